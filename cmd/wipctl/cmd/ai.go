@@ -0,0 +1,280 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/ai"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/aicache"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/aiusage"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/ui"
+)
+
+var (
+	noAICache   bool
+	showPrompts bool
+	usageSince  string
+)
+
+// aiCache is the response cache in the report directory; nil when disabled
+var aiCache *aicache.Cache
+
+var aiCmd = &cobra.Command{
+	Use:   "ai",
+	Short: "Manage AI provider state",
+	Long: `Inspect and manage what wipctl keeps for its AI provider.
+
+AI responses are cached in <report-dir>/` + aicache.DirName + `. The key is the
+provider, the model, the token limit and temperature, the redaction rules,
+the prompt template and a hash of the request input.
+Re-running 'push --ai-commit' or 'review' on an unchanged workspace is then
+answered from disk, instantly and for free.
+
+Every AI call is priced from the ai_prices table of the config file (USD per
+million tokens) over built-in list prices. Every call, including those of
+'review' and 'status --ai', is appended to <report-dir>/` + aiusage.LogFileName + `
+and 'ai usage' totals them. Push and checkpoint reports also show each
+entry's tokens, latency and cost.
+
+Environment:
+  WIPCTL_AI_CACHE_TTL      how long responses stay valid (default 7d, 0 disables the cache)
+  WIPCTL_AI_CACHE_MAX_MB   size limit; least recently used entries go first (default 50)
+
+Examples:
+  wipctl ai cache stats
+  wipctl ai cache clear
+  wipctl ai usage --since 7d   # Tokens and estimated cost of the last week's calls
+  wipctl review --no-cache     # Ask the provider again, refreshing the cache
+  wipctl ai prompts list       # Prompt templates and their overrides`,
+}
+
+var aiCacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Inspect or clear the AI response cache",
+}
+
+var aiCacheStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show AI response cache size, age and hits",
+	Args:  cobra.NoArgs,
+	RunE:  runAICacheStats,
+}
+
+var aiCacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Remove all cached AI responses",
+	Args:  cobra.NoArgs,
+	RunE:  runAICacheClear,
+}
+
+var aiUsageCmd = &cobra.Command{
+	Use:   "usage",
+	Short: "Total AI tokens, latency and estimated cost from the usage log",
+	Long: `Total the AI calls recorded in <report-dir>/` + aiusage.LogFileName + `.
+
+Every provider call is appended to that log as it completes, one JSON object
+per line with the time, the command, the provider and model, the tokens, the
+latency and the unrounded cost. The log is written whether or not the command
+saves a report, so the calls of 'review' and 'status --ai' are counted too.
+Cached responses cost nothing and are not logged.
+
+Totals are grouped by provider and model over the calls newer than --since.
+
+Examples:
+  wipctl ai usage              # Last 30 days
+  wipctl ai usage --since 12h  # Last 12 hours`,
+	Args: cobra.NoArgs,
+	RunE: runAIUsage,
+}
+
+func init() {
+	rootCmd.AddCommand(aiCmd)
+	aiCmd.AddCommand(aiCacheCmd)
+	aiCacheCmd.AddCommand(aiCacheStatsCmd)
+	aiCacheCmd.AddCommand(aiCacheClearCmd)
+	aiCmd.AddCommand(aiUsageCmd)
+	aiUsageCmd.Flags().StringVar(&usageSince, "since", "30d", "only count calls newer than this (e.g. 12h, 7d)")
+	rootCmd.PersistentFlags().BoolVar(&noAICache, "no-cache", false, "ignore cached AI responses (fresh responses are still cached)")
+	rootCmd.PersistentFlags().BoolVar(&showPrompts, "show-prompt", false, "print AI requests exactly as they would be sent, without sending them (implies --dry-run)")
+}
+
+// initAIRedaction hands the redaction rules and --show-prompt to the ai
+// package. Broken rules stop every AI request rather than letting unredacted
+// text through.
+func initAIRedaction() {
+	// A preview must not change any repository: commits made with the
+	// template message in place of the unsent request would be real
+	if showPrompts {
+		dryRun = true
+	}
+	if err := ai.UseRedaction(wipConfig.Redaction, showPrompts); err != nil {
+		ui.Warning(err.Error() + " - AI requests are disabled until it is fixed")
+	}
+}
+
+// initAIPrices hands the configured price overrides to the ai package
+func initAIPrices() {
+	ai.UsePrices(wipConfig.Prices())
+}
+
+// initAIUsageLog records every AI call the command makes, whether or not it
+// saves a report
+func initAIUsageLog(cmd *cobra.Command) {
+	ai.UseUsageLog(aiusage.OpenLog(reportDir), cmd.Name())
+}
+
+// showAIUsage prints the AI totals of a run, if it made any calls
+func showAIUsage(total aiusage.Usage) {
+	if total.Calls > 0 {
+		ui.Info("🤖 AI usage: " + total.Summary())
+	}
+}
+
+// initAICache opens the response cache with limits from the environment and
+// hands it to the ai package
+func initAICache() {
+	ttl := aicache.DefaultTTL
+	if val := os.Getenv("WIPCTL_AI_CACHE_TTL"); val != "" {
+		parsed, err := aicache.ParseDuration(val)
+		if err != nil {
+			ui.Warning("Invalid WIPCTL_AI_CACHE_TTL, using default: " + err.Error())
+		} else {
+			ttl = parsed
+		}
+	}
+
+	maxBytes := int64(aicache.DefaultMaxBytes)
+	if val := os.Getenv("WIPCTL_AI_CACHE_MAX_MB"); val != "" {
+		parsed, err := strconv.ParseFloat(val, 64)
+		if err != nil || parsed < 0 {
+			ui.Warning("Invalid WIPCTL_AI_CACHE_MAX_MB, using default: " + val)
+		} else {
+			maxBytes = int64(parsed * (1 << 20))
+		}
+	}
+
+	aiCache = nil
+	if ttl > 0 && maxBytes > 0 {
+		aiCache = aicache.Open(reportDir, ttl, maxBytes)
+	}
+	// --show-prompt must reach the providers, so cached answers are skipped
+	ai.UseCache(aiCache, noAICache || showPrompts)
+}
+
+func runAICacheStats(cmd *cobra.Command, args []string) error {
+	if aiCache == nil {
+		ui.Info("AI response cache is disabled (WIPCTL_AI_CACHE_TTL or WIPCTL_AI_CACHE_MAX_MB is 0)")
+		return nil
+	}
+
+	stats, err := aiCache.Stats()
+	if err != nil {
+		ui.Error("Failed to read AI cache: " + err.Error())
+		return err
+	}
+	if stats.Entries == 0 {
+		ui.Info("AI response cache is empty: " + aiCache.Dir())
+		return nil
+	}
+
+	kinds := make([]string, 0, len(stats.ByKind))
+	for kind := range stats.ByKind {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	byKind := make([]string, 0, len(kinds))
+	for _, kind := range kinds {
+		byKind = append(byKind, fmt.Sprintf("%s %d", kind, stats.ByKind[kind]))
+	}
+
+	ui.InitTable("Setting", "Value")
+	ui.AddTableRow("Directory", aiCache.Dir())
+	ui.AddTableRow("Entries", fmt.Sprintf("%d (%s)", stats.Entries, strings.Join(byKind, ", ")))
+	ui.AddTableRow("Size", fmt.Sprintf("%s of %s", formatSize(stats.Bytes), formatSize(aiCache.MaxBytes())))
+	ui.AddTableRow("Hits", fmt.Sprintf("%d requests answered from cache", stats.Hits))
+	ui.AddTableRow("Expired", fmt.Sprintf("%d (TTL %s)", stats.Expired, formatTTL(aiCache.TTL())))
+	ui.AddTableRow("Oldest", formatAge(stats.Oldest))
+	ui.AddTableRow("Newest", formatAge(stats.Newest))
+	ui.RenderTable()
+	return nil
+}
+
+func runAICacheClear(cmd *cobra.Command, args []string) error {
+	if aiCache == nil {
+		ui.Info("AI response cache is disabled")
+		return nil
+	}
+
+	if dryRun {
+		stats, err := aiCache.Stats()
+		if err != nil {
+			return err
+		}
+		ui.Info(fmt.Sprintf("[DRY RUN] Would remove %d cached AI responses (%s)", stats.Entries, formatSize(stats.Bytes)))
+		return nil
+	}
+
+	removed, err := aiCache.Clear()
+	if err != nil {
+		ui.Error("Failed to clear AI cache: " + err.Error())
+		return err
+	}
+	ui.Success(fmt.Sprintf("Removed %d cached AI responses", removed))
+	return nil
+}
+
+func runAIUsage(cmd *cobra.Command, args []string) error {
+	since, err := aicache.ParseDuration(usageSince)
+	if err != nil || since <= 0 {
+		return fmt.Errorf("invalid --since %q: use a duration such as 12h or 30d", usageSince)
+	}
+	cutoff := time.Now().Add(-since)
+
+	usageLog := aiusage.OpenLog(reportDir)
+	records, err := usageLog.Since(cutoff)
+	if err != nil {
+		ui.Error("Failed to read AI usage: " + err.Error())
+		return err
+	}
+
+	if len(records) == 0 {
+		ui.Info(fmt.Sprintf("No AI usage recorded in the last %s (%s)", formatTTL(since), usageLog.Path()))
+		return nil
+	}
+
+	usages := make([]aiusage.Usage, 0, len(records))
+	for _, record := range records {
+		usages = append(usages, record.Usage)
+	}
+
+	ui.Info(fmt.Sprintf("AI usage from the last %s", formatTTL(since)))
+	ui.InitTable("Provider", "Calls", "Input Tokens", "Output Tokens", "Avg Latency", "Est. Cost")
+	addUsageRow := func(name string, usage aiusage.Usage) {
+		ui.AddTableRow(name,
+			strconv.Itoa(usage.Calls),
+			strconv.Itoa(usage.InputTokens),
+			strconv.Itoa(usage.OutputTokens),
+			aiusage.FormatLatency(usage.Latency/time.Duration(usage.Calls)),
+			usage.CostText())
+	}
+	for _, usage := range aiusage.Merge(usages) {
+		addUsageRow(usage.Name(), usage)
+	}
+	addUsageRow("Total", aiusage.Total(usages))
+	ui.RenderTable()
+	return nil
+}
+
+// formatTTL renders whole days as "7d" and anything else as a Go duration
+func formatTTL(ttl time.Duration) string {
+	day := 24 * time.Hour
+	if ttl >= day && ttl%day == 0 {
+		return fmt.Sprintf("%dd", ttl/day)
+	}
+	return ttl.String()
+}
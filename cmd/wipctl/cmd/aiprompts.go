@@ -0,0 +1,178 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/ai"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/config"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/prompts"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/ui"
+)
+
+var (
+	promptsUser    bool
+	promptsDefault bool
+)
+
+var aiPromptsCmd = &cobra.Command{
+	Use:   "prompts",
+	Short: "List, show and override the AI prompt templates",
+	Long: `Manage the text/template prompts sent to AI providers.
+
+Every kind of request (` + strings.Join(prompts.Kinds, ", ") + `) has a built-in
+template. A file <kind>.tmpl in one of these directories replaces it, the
+workspace winning over the user directory:
+  workspace: <report-dir>/` + prompts.DirName + `
+  user:      <user config dir>/wipctl/` + prompts.DirName + `
+
+Examples:
+  wipctl ai prompts list
+  wipctl ai prompts show commit
+  wipctl ai prompts show commit --default
+  wipctl ai prompts edit commit           # Override for this workspace
+  wipctl ai prompts edit commit --user    # Override for every workspace`,
+}
+
+var aiPromptsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List prompts with their version and source",
+	Args:  cobra.NoArgs,
+	RunE:  runAIPromptsList,
+}
+
+var aiPromptsShowCmd = &cobra.Command{
+	Use:       "show <kind>",
+	Short:     "Print the template used for a kind of request",
+	Args:      cobra.ExactArgs(1),
+	ValidArgs: prompts.Kinds,
+	RunE:      runAIPromptsShow,
+}
+
+var aiPromptsEditCmd = &cobra.Command{
+	Use:       "edit <kind>",
+	Short:     "Edit the workspace (or --user) override of a prompt in $EDITOR",
+	Args:      cobra.ExactArgs(1),
+	ValidArgs: prompts.Kinds,
+	RunE:      runAIPromptsEdit,
+}
+
+func init() {
+	aiCmd.AddCommand(aiPromptsCmd)
+	aiPromptsCmd.AddCommand(aiPromptsListCmd)
+	aiPromptsCmd.AddCommand(aiPromptsShowCmd)
+	aiPromptsCmd.AddCommand(aiPromptsEditCmd)
+
+	aiPromptsShowCmd.Flags().BoolVar(&promptsDefault, "default", false, "print the built-in template even if it is overridden")
+	aiPromptsEditCmd.Flags().BoolVar(&promptsUser, "user", false, "edit the user override instead of the workspace override")
+}
+
+// promptDirs returns the override directories, workspace first
+func promptDirs() []string {
+	dirs := []string{filepath.Join(reportDir, prompts.DirName)}
+	if userPath := config.UserPath(); userPath != "" {
+		dirs = append(dirs, filepath.Join(filepath.Dir(userPath), prompts.DirName))
+	}
+	return dirs
+}
+
+// initAIPrompts hands the prompt overrides to the ai package. A broken
+// override is skipped so requests keep working with the built-in prompt.
+func initAIPrompts() {
+	for _, err := range ai.UsePrompts(promptDirs()...) {
+		ui.Warning(err.Error() + " - using the built-in prompt")
+	}
+}
+
+func runAIPromptsList(cmd *cobra.Command, args []string) error {
+	ui.InitTable("Kind", "Version", "Source", "Key")
+	for _, kind := range prompts.Kinds {
+		prompt, ok := ai.Prompt(kind)
+		if !ok {
+			continue
+		}
+		ui.AddTableRow(kind, strconv.Itoa(prompt.Version), prompt.Source, prompt.Key())
+	}
+	ui.RenderTable()
+	return nil
+}
+
+func runAIPromptsShow(cmd *cobra.Command, args []string) error {
+	kind := args[0]
+	if promptsDefault {
+		text, err := prompts.Default(kind)
+		if err != nil {
+			return err
+		}
+		fmt.Print(text)
+		return nil
+	}
+
+	prompt, ok := ai.Prompt(kind)
+	if !ok {
+		return fmt.Errorf("unknown prompt %q (one of %s)", kind, strings.Join(prompts.Kinds, ", "))
+	}
+	fmt.Print(prompt.Text)
+	return nil
+}
+
+func runAIPromptsEdit(cmd *cobra.Command, args []string) error {
+	kind := args[0]
+	prompt, ok := ai.Prompt(kind)
+	if !ok {
+		return fmt.Errorf("unknown prompt %q (one of %s)", kind, strings.Join(prompts.Kinds, ", "))
+	}
+
+	dirs := promptDirs()
+	dir := dirs[0]
+	if promptsUser {
+		if len(dirs) < 2 {
+			return fmt.Errorf("cannot determine user config directory")
+		}
+		dir = dirs[1]
+	}
+	path := prompts.Path(dir, kind)
+
+	// Start from the prompt in effect so edits build on what is used today
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("create prompt directory: %w", err)
+		}
+		if err := os.WriteFile(path, []byte(prompt.Text), 0644); err != nil {
+			return fmt.Errorf("write prompt: %w", err)
+		}
+	}
+
+	if err := ui.OpenEditor(path); err != nil {
+		ui.Error("Editor failed: " + err.Error())
+		return err
+	}
+
+	edited, err := prompts.Read(kind, path)
+	if err == nil {
+		err = ai.CheckPrompt(edited)
+	}
+	if err != nil {
+		ui.Warning("Prompt saved but is invalid, the built-in prompt is used until it is fixed: " + err.Error())
+		return err
+	}
+
+	ui.Success("Prompt saved: " + path)
+	if !promptsUser {
+		return nil
+	}
+	if workspace := prompts.Path(dirs[0], kind); isFile(workspace) {
+		ui.Warning("This workspace overrides it with " + workspace)
+	}
+	return nil
+}
+
+func isFile(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
@@ -0,0 +1,308 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/bundle"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/gitexec"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/report"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/ui"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/workspace"
+)
+
+var bundleThin bool
+
+var bundleCmd = &cobra.Command{
+	Use:   "bundle",
+	Short: "Move WIP branches between machines as git bundles",
+	Long: `Transfer WIP branches without network access, e.g. on a USB stick.
+
+'export' writes one git bundle per repository that has local WIP branches,
+plus an index (` + bundle.IndexFileName + `). 'import' verifies each bundle and
+fetches its branches into refs/remotes/` + bundle.RemoteName + `/, from where
+'wipctl pull --from-bundle' switches to them like a normal pull.
+
+Examples:
+  wipctl bundle export /media/usb/wip          # Full bundles
+  wipctl bundle export /media/usb/wip --thin   # Only commits origin doesn't have
+  wipctl bundle import /media/usb/wip
+  wipctl pull --from-bundle`,
+}
+
+var bundleExportCmd = &cobra.Command{
+	Use:   "export <dir>",
+	Short: "Write a git bundle per repository with WIP branches",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runBundleExport,
+}
+
+var bundleImportCmd = &cobra.Command{
+	Use:   "import <dir>",
+	Short: "Verify bundles and fetch them into refs/remotes/" + bundle.RemoteName,
+	Args:  cobra.ExactArgs(1),
+	RunE:  runBundleImport,
+}
+
+func init() {
+	rootCmd.AddCommand(bundleCmd)
+	bundleCmd.AddCommand(bundleExportCmd)
+	bundleCmd.AddCommand(bundleImportCmd)
+	bundleExportCmd.Flags().BoolVar(&bundleThin, "thin", false, "leave out commits already on origin (the importing repo must have them)")
+}
+
+func runBundleExport(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	if dryRun {
+		ctx = context.WithValue(ctx, gitexec.DryRunKey, true)
+		ui.Info("🧪 DRY RUN MODE - No actual git operations will be performed")
+	}
+
+	dir, err := filepath.Abs(args[0])
+	if err != nil {
+		return err
+	}
+	if !dryRun {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			ui.Error("Failed to create bundle directory: " + err.Error())
+			return err
+		}
+	}
+
+	repos, err := discoverRepos(ctx)
+	if err != nil {
+		return err
+	}
+
+	index := bundle.Index{
+		Created:   time.Now(),
+		Host:      hostName,
+		Workspace: workspacePath,
+	}
+	rep := report.NewReport("WIP Bundle Export Report", workspacePath, reportDir, "bundle-export")
+
+	for _, repo := range repos {
+		entry := report.ReportEntry{Repo: repo.Name}
+
+		wips, err := listWipRefs(ctx, repo.Path, "")
+		if err != nil {
+			entry.Outcome = "error"
+			entry.AddError(fmt.Sprintf("list WIP branches failed: %v", err))
+			rep.AddEntry(entry)
+			continue
+		}
+		if len(wips) == 0 {
+			continue
+		}
+
+		branches := make(map[string]string)
+		names := make([]string, 0, len(wips))
+		for _, wip := range wips {
+			branches[wip.Branch] = wip.Commit
+			names = append(names, wip.Branch)
+		}
+		sort.Strings(names)
+
+		rel := workspaceRelPath(repo.Path)
+		bundleEntry := bundle.Entry{
+			Repo:    repo.Name,
+			RelPath: rel,
+			File:    bundle.FileName(rel),
+			Thin:    bundleThin,
+		}
+		excludeRemote := ""
+		if bundleThin {
+			excludeRemote = "origin"
+		}
+
+		path := filepath.Join(dir, bundleEntry.File)
+		if err := gitexec.BundleCreate(ctx, repo.Path, path, names, excludeRemote); err != nil {
+			if errors.Is(err, gitexec.ErrEmptyBundle) {
+				entry.Outcome = "skipped"
+				entry.AddWarning("WIP branches are already on origin")
+				rep.AddEntry(entry)
+				continue
+			}
+			entry.Outcome = "error"
+			entry.AddError(fmt.Sprintf("bundle create failed: %v", err))
+			ui.Error(fmt.Sprintf("%s: bundle create failed", repo.Name))
+			rep.AddEntry(entry)
+			continue
+		}
+
+		// Thin bundles drop branches whose commits origin already has
+		included := names
+		if !dryRun && bundleThin {
+			if heads, err := gitexec.BundleHeads(ctx, repo.Path, path); err == nil {
+				included = heads
+			}
+		}
+		for _, name := range included {
+			bundleEntry.Branches = append(bundleEntry.Branches, bundle.Branch{Name: name, Commit: branches[name]})
+		}
+
+		if !dryRun {
+			sum, err := bundle.FileSHA256(path)
+			if err != nil {
+				entry.Outcome = "error"
+				entry.AddError(fmt.Sprintf("checksum failed: %v", err))
+				rep.AddEntry(entry)
+				continue
+			}
+			bundleEntry.SHA256 = sum
+		}
+
+		index.Repos = append(index.Repos, bundleEntry)
+		entry.Outcome = "success"
+		entry.Details = fmt.Sprintf("%d WIP branches → %s", len(bundleEntry.Branches), bundleEntry.File)
+		rep.AddEntry(entry)
+		ui.Success(fmt.Sprintf("%s: bundled %d WIP branches", repo.Name, len(bundleEntry.Branches)))
+	}
+
+	if err := rep.Save(); err != nil {
+		ui.Warning("Failed to save report: " + err.Error())
+	}
+
+	if len(index.Repos) == 0 {
+		ui.Warning("No repositories with WIP branches to bundle")
+		return nil
+	}
+
+	if dryRun {
+		fmt.Printf("[DRY RUN] Would write bundle index: %s\n", filepath.Join(dir, bundle.IndexFileName))
+	} else if err := bundle.WriteIndex(dir, index); err != nil {
+		ui.Error("Failed to write bundle index: " + err.Error())
+		return err
+	}
+
+	ui.Success(fmt.Sprintf("Exported %d bundles to %s", len(index.Repos), dir))
+	return nil
+}
+
+func runBundleImport(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	if dryRun {
+		ctx = context.WithValue(ctx, gitexec.DryRunKey, true)
+		ui.Info("🧪 DRY RUN MODE - No actual git operations will be performed")
+	}
+
+	dir, err := filepath.Abs(args[0])
+	if err != nil {
+		return err
+	}
+
+	index, err := bundle.ReadIndex(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			ui.Error(fmt.Sprintf("No %s found in %s", bundle.IndexFileName, dir))
+		} else {
+			ui.Error("Failed to read bundle index: " + err.Error())
+		}
+		return err
+	}
+
+	ui.Info(fmt.Sprintf("Importing %d bundles exported from %s at %s",
+		len(index.Repos), index.Host, index.Created.Format("2006-01-02 15:04:05")))
+
+	repos, err := discoverRepos(ctx)
+	if err != nil {
+		return err
+	}
+
+	byRelPath := make(map[string]workspace.Repo)
+	byName := make(map[string][]workspace.Repo)
+	for _, repo := range repos {
+		byRelPath[workspaceRelPath(repo.Path)] = repo
+		byName[repo.Name] = append(byName[repo.Name], repo)
+	}
+
+	rep := report.NewReport("WIP Bundle Import Report", workspacePath, reportDir, "bundle-import")
+
+	imported := 0
+	for _, bundleEntry := range index.Repos {
+		entry := report.ReportEntry{Repo: bundleEntry.Repo}
+
+		repo, ok := byRelPath[bundleEntry.RelPath]
+		if !ok && len(byName[bundleEntry.Repo]) == 1 {
+			repo, ok = byName[bundleEntry.Repo][0], true
+		}
+		if !ok {
+			entry.Outcome = "skipped"
+			entry.AddWarning(fmt.Sprintf("repository %s not found in workspace", bundleEntry.RelPath))
+			ui.Warning(fmt.Sprintf("%s: not found in workspace", bundleEntry.Repo))
+			rep.AddEntry(entry)
+			continue
+		}
+
+		entry = importBundle(ctx, repo, filepath.Join(dir, bundleEntry.File), bundleEntry)
+		if entry.Outcome == "success" {
+			imported++
+		}
+		rep.AddEntry(entry)
+	}
+
+	if err := rep.Save(); err != nil {
+		ui.Warning("Failed to save report: " + err.Error())
+	}
+
+	ui.Success(fmt.Sprintf("Imported %d of %d bundles", imported, len(index.Repos)))
+	if imported > 0 {
+		ui.Info("Run 'wipctl pull --from-bundle' to switch to the imported WIP branches")
+	}
+	return nil
+}
+
+// importBundle verifies a single bundle and fetches its WIP branches
+func importBundle(ctx context.Context, repo workspace.Repo, path string, bundleEntry bundle.Entry) report.ReportEntry {
+	entry := report.ReportEntry{Repo: repo.Name}
+	slog.Info("Importing bundle", "repo", repo.Path, "bundle", path)
+
+	sum, err := bundle.FileSHA256(path)
+	if err != nil {
+		entry.Outcome = "error"
+		entry.AddError(fmt.Sprintf("read bundle failed: %v", err))
+		ui.Error(fmt.Sprintf("%s: bundle missing", repo.Name))
+		return entry
+	}
+	if bundleEntry.SHA256 != "" && sum != bundleEntry.SHA256 {
+		entry.Outcome = "error"
+		entry.AddError("bundle checksum does not match the index")
+		ui.Error(fmt.Sprintf("%s: bundle checksum mismatch", repo.Name))
+		return entry
+	}
+
+	if err := gitexec.BundleVerify(ctx, repo.Path, path); err != nil {
+		entry.Outcome = "error"
+		entry.AddError(fmt.Sprintf("bundle verify failed: %v", err))
+		ui.Error(fmt.Sprintf("%s: bundle verify failed", repo.Name))
+		return entry
+	}
+
+	if err := gitexec.FetchBundle(ctx, repo.Path, path, bundle.RemoteName, wipNames.Prefixes()); err != nil {
+		entry.Outcome = "error"
+		entry.AddError(fmt.Sprintf("fetch from bundle failed: %v", err))
+		ui.Error(fmt.Sprintf("%s: fetch from bundle failed", repo.Name))
+		return entry
+	}
+
+	entry.Outcome = "success"
+	entry.Details = fmt.Sprintf("%d WIP branches → refs/remotes/%s/", len(bundleEntry.Branches), bundle.RemoteName)
+	ui.Success(fmt.Sprintf("%s: imported %d WIP branches", repo.Name, len(bundleEntry.Branches)))
+	return entry
+}
+
+// workspaceRelPath returns a repo path relative to the workspace, in slash form
+func workspaceRelPath(repoPath string) string {
+	rel, err := filepath.Rel(workspacePath, repoPath)
+	if err != nil {
+		return filepath.Base(repoPath)
+	}
+	return filepath.ToSlash(rel)
+}
@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/bundle"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/workspace"
+)
+
+const bundleWipBranch = "wip/laptop/20260101-120000"
+
+// setupBundleLaptop clones origin into root/laptop/api and commits notes.txt
+// on a local WIP branch, leaving main checked out
+func setupBundleLaptop(t *testing.T) (root, origin, laptop string) {
+	t.Helper()
+	root, origin = setupOrigin(t)
+	oldThin, oldFromBundle := bundleThin, pullFromBundle
+	t.Cleanup(func() { bundleThin, pullFromBundle = oldThin, oldFromBundle })
+
+	laptop = clone(t, root, origin, filepath.Join("laptop", "api"))
+	git(t, laptop, "switch", "-q", "-c", bundleWipBranch)
+	writeFile(t, filepath.Join(laptop, "notes.txt"), "todo\n")
+	git(t, laptop, "add", "-A")
+	git(t, laptop, "commit", "-q", "-m", "wip")
+	git(t, laptop, "switch", "-q", "main")
+	return root, origin, laptop
+}
+
+func TestBundleExportImportAndPull(t *testing.T) {
+	root, origin, _ := setupBundleLaptop(t)
+	usb := filepath.Join(root, "usb")
+
+	workspacePath = filepath.Join(root, "laptop")
+	if err := runBundleExport(bundleExportCmd, []string{usb}); err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	index, err := bundle.ReadIndex(usb)
+	if err != nil {
+		t.Fatalf("read index: %v", err)
+	}
+	if len(index.Repos) != 1 || index.Repos[0].RelPath != "api" || len(index.Repos[0].Branches) != 1 {
+		t.Fatalf("index repos = %+v, want api with one WIP branch", index.Repos)
+	}
+
+	desktop := clone(t, root, origin, filepath.Join("desktop", "api"))
+	workspacePath = filepath.Join(root, "desktop")
+	if err := runBundleImport(bundleImportCmd, []string{usb}); err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	imported := "refs/remotes/" + bundle.RemoteName + "/" + bundleWipBranch
+	if got := git(t, desktop, "for-each-ref", "--format=%(refname)", imported); got != imported {
+		t.Fatalf("imported refs = %q, want %s", got, imported)
+	}
+
+	pullFromBundle = true
+	entry := processRepoPull(context.Background(), workspace.Repo{Name: "api", Path: desktop})
+	if entry.Outcome != "success" {
+		t.Fatalf("pull outcome = %q, want success (errors: %v, warnings: %v)", entry.Outcome, entry.Errors, entry.Warnings)
+	}
+	if got := git(t, desktop, "branch", "--show-current"); got != bundleWipBranch {
+		t.Errorf("current branch = %q, want %s", got, bundleWipBranch)
+	}
+	if content, err := os.ReadFile(filepath.Join(desktop, "notes.txt")); err != nil || string(content) != "todo\n" {
+		t.Errorf("notes.txt = %q (%v), want the bundled commit", content, err)
+	}
+}
+
+func TestBundleExportThinSkipsBranchesOnOrigin(t *testing.T) {
+	root, _, laptop := setupBundleLaptop(t)
+	git(t, laptop, "push", "-q", "origin", bundleWipBranch)
+	usb := filepath.Join(root, "usb")
+
+	workspacePath = filepath.Join(root, "laptop")
+	bundleThin = true
+	if err := runBundleExport(bundleExportCmd, []string{usb}); err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	if _, err := bundle.ReadIndex(usb); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("read index error = %v, want no index when origin has every WIP commit", err)
+	}
+}
+
+func TestImportBundleRejectsChecksumMismatch(t *testing.T) {
+	root, origin, _ := setupBundleLaptop(t)
+	usb := filepath.Join(root, "usb")
+
+	workspacePath = filepath.Join(root, "laptop")
+	if err := runBundleExport(bundleExportCmd, []string{usb}); err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	index, err := bundle.ReadIndex(usb)
+	if err != nil || len(index.Repos) != 1 {
+		t.Fatalf("read index = %+v, %v", index, err)
+	}
+	bundleEntry := index.Repos[0]
+	bundleEntry.SHA256 = "0000"
+
+	desktop := clone(t, root, origin, filepath.Join("desktop", "api"))
+	entry := importBundle(context.Background(), workspace.Repo{Name: "api", Path: desktop}, filepath.Join(usb, bundleEntry.File), bundleEntry)
+	if entry.Outcome != "error" {
+		t.Errorf("outcome = %q, want error", entry.Outcome)
+	}
+	if got := git(t, desktop, "for-each-ref", "refs/remotes/"+bundle.RemoteName); got != "" {
+		t.Errorf("refs fetched despite checksum mismatch: %q", got)
+	}
+}
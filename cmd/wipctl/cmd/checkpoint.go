@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"path/filepath"
 	"strings"
@@ -10,6 +11,9 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/ai"
 	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/gitexec"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/hooks"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/msgedit"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/queue"
 	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/report"
 	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/status"
 	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/ui"
@@ -38,7 +42,10 @@ Configure AI provider with environment variables:
 Examples:
   wipctl checkpoint                    # Checkpoint all repos with AI commits
   wipctl checkpoint --dry-run          # Preview what would be checkpointed
-  wipctl checkpoint --message="EOD"    # Use custom message prefix`,
+  wipctl checkpoint --message="EOD"    # Use custom message prefix
+  wipctl checkpoint --rolling          # Add to the rolling wip/<host>/<branch> history
+  wipctl checkpoint --edit             # Edit every repo's message in one $EDITOR file
+  wipctl checkpoint --gates            # Run go build/vet, npm test, ... first`,
 	RunE: runCheckpoint,
 }
 
@@ -47,15 +54,22 @@ var (
 	checkpointConcurrency int
 	checkpointFeature     string
 	checkpointCrossRepo   bool
+	checkpointRolling     bool
+	checkpointEdit        bool
 )
 
 func init() {
 	rootCmd.AddCommand(checkpointCmd)
 
-	checkpointCmd.Flags().StringVar(&checkpointMessage, "message", "", "Custom message prefix for commits")
+	checkpointCmd.Flags().StringVar(&checkpointMessage, "message", "", "Custom message for commits (first body paragraph of AI messages)")
 	checkpointCmd.Flags().IntVar(&checkpointConcurrency, "concurrency", 8, "Number of parallel operations")
 	checkpointCmd.Flags().StringVar(&checkpointFeature, "feature", "", "Cross-repo feature name for coordinated commits")
 	checkpointCmd.Flags().BoolVar(&checkpointCrossRepo, "cross-repo", false, "Enable cross-repository feature coordination")
+	checkpointCmd.Flags().BoolVar(&gatesOn, "gates", false, "Run quality gates (build/vet/tests) before committing")
+	checkpointCmd.Flags().BoolVar(&gatesOff, "no-gates", false, "Skip quality gates even when enabled in config")
+	checkpointCmd.MarkFlagsMutuallyExclusive("gates", "no-gates")
+	checkpointCmd.Flags().BoolVar(&checkpointEdit, "edit", false, "Review and edit all commit messages in $EDITOR before committing")
+	checkpointCmd.Flags().BoolVar(&checkpointRolling, "rolling", false, "Commit onto one rolling wip/<host>/<branch> ref instead of a new branch")
 }
 
 func runCheckpoint(cmd *cobra.Command, args []string) error {
@@ -66,6 +80,8 @@ func runCheckpoint(cmd *cobra.Command, args []string) error {
 		ctx = context.WithValue(ctx, gitexec.DryRunKey, true)
 	}
 
+	ctx = withSigning(ctx)
+
 	ui.CyberpunkBanner("HACKERSPEED CHECKPOINT")
 	ui.Info("🚀 Initiating rapid workspace checkpoint...")
 
@@ -111,24 +127,95 @@ func runCheckpoint(cmd *cobra.Command, args []string) error {
 	aiConfig := ai.LoadConfigFromEnv()
 	generator := ai.NewGenerator(aiConfig)
 
-	// Create enhanced checkpoint report
-	checkpointReport := report.NewCheckpointReport("Hackerspeed Checkpoint", workspacePath, reportDir, checkpointFeature, checkpointCrossRepo)
-	checkpointReport.TotalRepos = len(repos)
-
 	if checkpointCrossRepo {
 		ui.Info(fmt.Sprintf("🔗 Cross-repo feature mode: %s", checkpointFeature))
 	}
 
+	if _, err := checkpointRepositories(ctx, "Hackerspeed Checkpoint", checkpointRepos, results, len(repos), generator); err != nil {
+		return err
+	}
+
+	ui.Success("🚀 Hackerspeed checkpoint complete!")
+	ui.Info(fmt.Sprintf("📋 Checkpointed %d repositories", len(checkpointRepos)))
+
+	return nil
+}
+
+// checkpointRepositories runs the checkpoint workflow for each repository,
+// then summarizes and saves the checkpoint report. It fails only when the
+// pre-checkpoint hook aborts the run.
+func checkpointRepositories(ctx context.Context, title string, repoPaths []string, results map[string]*gitexec.RepoStatus, totalRepos int, generator ai.Generator) (*report.CheckpointReport, error) {
+	if err := runOperationHook(ctx, hooks.Pre("checkpoint"), "checkpoint", ""); err != nil {
+		return nil, err
+	}
+
+	// Create enhanced checkpoint report
+	checkpointReport := report.NewCheckpointReport(title, workspacePath, reportDir, checkpointFeature, checkpointCrossRepo)
+	checkpointReport.TotalRepos = totalRepos
+
+	// Run quality gates for every repository up front, in parallel
+	var gateRuns map[string][]report.GateRun
+	if gatesEnabled() {
+		var eligible []string
+		for _, repoPath := range repoPaths {
+			if checkpointEligible(results[repoPath]) {
+				eligible = append(eligible, repoPath)
+			}
+		}
+		ui.Info(fmt.Sprintf("🚦 Running quality gates for %d repositories...", len(eligible)))
+		gateRuns = runGatesParallel(ctx, eligible, checkpointConcurrency)
+	}
+
+	// With --edit, stage everything and let the user review all messages first
+	var review checkpointReview
+	if checkpointEdit {
+		var err error
+		if review, err = editCheckpointMessages(ctx, repoPaths, results, generator, gateRuns); err != nil {
+			for _, repoPath := range repoPaths {
+				if pre, ok := review.Pre[repoPath]; ok {
+					checkpointReport.AddCheckpointEntry(pre)
+				}
+			}
+			if saveErr := checkpointReport.Save(); saveErr != nil {
+				ui.Warning("Failed to save checkpoint report: " + saveErr.Error())
+			}
+			ui.Error("Checkpoint aborted: " + err.Error())
+			return nil, err
+		}
+	}
+
 	// Process each repository that needs checkpointing
-	for _, repoPath := range checkpointRepos {
+	for _, repoPath := range repoPaths {
 		repoStatus := results[repoPath]
 		if repoStatus.Error != "" {
 			continue // Skip errored repos
 		}
 
+		plan := checkpointPlan{Gates: gateRuns[repoPath], AI: review.Attributions[repoPath]}
+		if pre, ok := review.Pre[repoPath]; ok {
+			if pre.Outcome != "processing" {
+				// Its pre-repo hook or staging failed during the review
+				checkpointReport.AddCheckpointEntry(pre)
+				continue
+			}
+			plan.Pre = &pre
+
+			var kept bool
+			if plan.Message, kept = review.Messages[repoPath]; !kept {
+				if err := gitexec.ResetIndex(ctx, repoPath); err != nil {
+					ui.Warning(fmt.Sprintf("%s: failed to unstage: %v", filepath.Base(repoPath), err))
+				}
+				pre.Outcome, pre.Details = "skipped", "message removed in editor"
+				finishRepoHook(ctx, &pre.ReportEntry, checkpointHookEvent(repoPath, repoStatus))
+				checkpointReport.AddCheckpointEntry(pre)
+				ui.Info(fmt.Sprintf("⏭️  %s skipped", filepath.Base(repoPath)))
+				continue
+			}
+		}
+
 		ui.Info(fmt.Sprintf("🔄 Checkpointing %s...", filepath.Base(repoPath)))
 
-		entry := processEnhancedCheckpointRepo(ctx, repoPath, repoStatus, generator)
+		entry := checkpointRepoWithHooks(ctx, repoPath, repoStatus, generator, plan)
 		checkpointReport.AddCheckpointEntry(entry)
 
 		if entry.Outcome == "success" {
@@ -145,11 +232,145 @@ func runCheckpoint(cmd *cobra.Command, args []string) error {
 	if err := checkpointReport.Save(); err != nil {
 		ui.Warning("Failed to save checkpoint report: " + err.Error())
 	}
+	showAIUsage(checkpointReport.AIUsage())
 
-	ui.Success("🚀 Hackerspeed checkpoint complete!")
-	ui.Info(fmt.Sprintf("📋 Checkpointed %d repositories", len(checkpointRepos)))
+	outcome := "success"
+	if checkpointReport.FailedRepos > 0 {
+		outcome = "partial"
+	}
+	runOperationHook(ctx, hooks.Post("checkpoint"), "checkpoint", outcome) //nolint:errcheck // post hook failures are reported, not fatal
 
-	return nil
+	entries := make([]report.ReportEntry, 0, len(checkpointReport.Entries))
+	for _, entry := range checkpointReport.Entries {
+		entries = append(entries, entry.ReportEntry)
+	}
+	autoFlushPushQueue(ctx, entries)
+
+	return checkpointReport, nil
+}
+
+// checkpointReview is what the --edit review decided, by repository path
+type checkpointReview struct {
+	Messages     map[string]string                 // messages kept in $EDITOR
+	Attributions map[string]*ai.Attribution        // provider that proposed each message
+	Pre          map[string]report.CheckpointEntry // repositories whose pre-repo hook has run
+}
+
+// editCheckpointMessages runs the pre-repo hook of every eligible repository,
+// then stages it and proposes a message, in the order the checkpoint itself
+// would, and opens all messages in $EDITOR. Repositories whose hook or
+// staging fails get a final Pre entry. When the edit is aborted everything is
+// unstaged and every Pre entry is final, its post-repo hook run with the
+// outcome "aborted".
+func editCheckpointMessages(ctx context.Context, repoPaths []string, results map[string]*gitexec.RepoStatus, generator ai.Generator, gateRuns map[string][]report.GateRun) (checkpointReview, error) {
+	review := checkpointReview{
+		Attributions: make(map[string]*ai.Attribution),
+		Pre:          make(map[string]report.CheckpointEntry),
+	}
+	var items []msgedit.Item
+	var staged []string
+
+	for _, repoPath := range repoPaths {
+		status := results[repoPath]
+		repoName := filepath.Base(repoPath)
+		if !checkpointEligible(status) || gateBlocked(repoName, gateRuns[repoPath]) {
+			continue
+		}
+
+		event := checkpointHookEvent(repoPath, status)
+		pre := report.CreateCheckpointEntry(repoName, status.Branch, "processing", "")
+		pre.Path = repoPath
+		if skipOnPreRepoHook(ctx, &pre.ReportEntry, event) {
+			pre.Details = "pre-repo hook failed"
+			finishRepoHook(ctx, &pre.ReportEntry, event)
+			review.Pre[repoPath] = pre
+			continue
+		}
+
+		if status.Dirty > 0 || status.Untracked > 0 {
+			if err := gitexec.AddAll(ctx, repoPath); err != nil {
+				ui.Warning(fmt.Sprintf("%s: git add failed: %v", repoName, err))
+				pre.Outcome, pre.Details = "failed", fmt.Sprintf("git add failed: %v", err)
+				finishRepoHook(ctx, &pre.ReportEntry, event)
+				review.Pre[repoPath] = pre
+				continue
+			}
+			staged = append(staged, repoPath)
+		}
+		review.Pre[repoPath] = pre
+
+		diffStat, _ := gitexec.DiffStatCached(ctx, repoPath)
+		message, attribution := checkpointCommitMessage(ctx, repoPath, status, generator)
+		review.Attributions[repoPath] = attribution
+		items = append(items, msgedit.Item{
+			Key:      repoPath,
+			Branch:   status.Branch,
+			DiffStat: diffStat,
+			Message:  annotateGates(repoName, message, gateRuns[repoPath]),
+		})
+	}
+
+	if len(items) == 0 {
+		review.Messages = map[string]string{}
+		return review, nil
+	}
+
+	messages, err := editCommitMessages(ctx, "wipctl checkpoint", items)
+	if err != nil {
+		for _, repoPath := range staged {
+			gitexec.ResetIndex(ctx, repoPath) //nolint:errcheck // best effort on abort
+		}
+		for repoPath, pre := range review.Pre {
+			if pre.Outcome != "processing" {
+				continue
+			}
+			pre.Outcome, pre.Details = "aborted", "message edit aborted: "+err.Error()
+			attribution := review.Attributions[repoPath]
+			pre.AI, pre.Usage = attribution.String(), attribution.Calls()
+			finishRepoHook(ctx, &pre.ReportEntry, checkpointHookEvent(repoPath, results[repoPath]))
+			review.Pre[repoPath] = pre
+		}
+		return review, err
+	}
+	review.Messages = messages
+	return review, nil
+}
+
+// checkpointEligible reports whether a repository passes the checkpoint preconditions
+func checkpointEligible(status *gitexec.RepoStatus) bool {
+	return status.Error == "" && status.HasOrigin && !status.InProgress
+}
+
+// checkpointHookEvent builds the repo hook event of a checkpoint
+func checkpointHookEvent(repoPath string, status *gitexec.RepoStatus) hooks.Event {
+	return hooks.Event{Operation: "checkpoint", RepoPath: repoPath, Branch: status.Branch}
+}
+
+// checkpointRepoWithHooks wraps a single repo checkpoint in the pre-repo/post-repo
+// hooks. The pre-repo hook is skipped when plan.Pre shows the --edit review
+// already ran it.
+func checkpointRepoWithHooks(ctx context.Context, repoPath string, status *gitexec.RepoStatus, generator ai.Generator, plan checkpointPlan) report.CheckpointEntry {
+	event := checkpointHookEvent(repoPath, status)
+
+	var pre report.CheckpointEntry
+	if plan.Pre != nil {
+		pre = *plan.Pre
+	} else {
+		pre = report.CreateCheckpointEntry(filepath.Base(repoPath), status.Branch, "processing", "")
+		pre.Path = repoPath
+		if skipOnPreRepoHook(ctx, &pre.ReportEntry, event) {
+			pre.Details = "pre-repo hook failed"
+			finishRepoHook(ctx, &pre.ReportEntry, event)
+			return pre
+		}
+	}
+
+	entry := processEnhancedCheckpointRepo(ctx, repoPath, status, generator, plan)
+	entry.Hooks = append(pre.Hooks, entry.Hooks...)
+
+	event.WipBranch = entry.WipBranch
+	finishRepoHook(ctx, &entry.ReportEntry, event)
+	return entry
 }
 
 func filterCheckpointCandidates(results map[string]*gitexec.RepoStatus) []string {
@@ -169,11 +390,21 @@ func filterCheckpointCandidates(results map[string]*gitexec.RepoStatus) []string
 	return candidates
 }
 
-func processEnhancedCheckpointRepo(ctx context.Context, repoPath string, status *gitexec.RepoStatus, generator ai.Generator) report.CheckpointEntry {
+// checkpointPlan carries per-repository decisions made before the commit loop
+type checkpointPlan struct {
+	Message string                  // approved in $EDITOR; empty means generate one
+	Gates   []report.GateRun        // quality gate results, already run
+	AI      *ai.Attribution         // provider that proposed Message
+	Pre     *report.CheckpointEntry // pre-repo hook run by the --edit review, if any
+}
+
+// processEnhancedCheckpointRepo checkpoints one repository according to plan
+func processEnhancedCheckpointRepo(ctx context.Context, repoPath string, status *gitexec.RepoStatus, generator ai.Generator, plan checkpointPlan) report.CheckpointEntry {
 	repoName := filepath.Base(repoPath)
 
 	// Create enhanced checkpoint entry
 	entry := report.CreateCheckpointEntry(repoName, status.Branch, "processing", fmt.Sprintf("branch: %s", status.Branch))
+	entry.Path = repoPath
 
 	// Add feature coordination if enabled
 	if checkpointFeature != "" {
@@ -198,6 +429,12 @@ func processEnhancedCheckpointRepo(ctx context.Context, repoPath string, status
 		return entry
 	}
 
+	// Apply the quality gate policy (gates ran up front, in parallel)
+	if !applyGatePolicy(repoName, plan.Gates, &entry.ReportEntry) {
+		entry.Details = "blocked by quality gates"
+		return entry
+	}
+
 	// Collect detailed repo information before staging
 	entry.FilesModified = status.Dirty
 	entry.FilesAdded = status.Untracked
@@ -234,72 +471,147 @@ func processEnhancedCheckpointRepo(ctx context.Context, repoPath string, status
 		}
 	}
 
-	// Generate AI commit message with cross-repo context
-	commitMsg, err := generateEnhancedCheckpointCommitMessage(ctx, repoPath, status, generator)
-	if err != nil {
-		ui.Warning("AI commit generation failed, using fallback: " + err.Error())
-		commitMsg = generateFallbackCheckpointMessage(repoName, status)
+	// Use the message approved in the editor, or generate one
+	commitMsg, attribution := plan.Message, plan.AI
+	if commitMsg == "" {
+		commitMsg, attribution = checkpointCommitMessage(ctx, repoPath, status, generator)
+		commitMsg = annotateGates(repoName, commitMsg, plan.Gates)
 	}
 	entry.CommitMessage = commitMsg
+	entry.AI, entry.Usage = attribution.String(), attribution.Calls()
 
-	// Create checkpoint commit
-	if err := gitexec.CommitAllowEmpty(ctx, repoPath, commitMsg); err != nil {
-		entry.Outcome = "failed"
-		entry.Details = "failed to create commit"
-		entry.AddError("git commit failed: " + err.Error())
+	if checkpointRolling {
+		result, err := rollingCheckpoint(ctx, repoPath, repoName, status.Branch, commitMsg)
+		entry.WipBranch = result.Branch
+		if err != nil {
+			entry.Outcome = "failed"
+			entry.Details = "rolling checkpoint failed"
+			entry.AddError(err.Error())
+			return entry
+		}
+		if len(result.Commit) >= 8 {
+			entry.CommitHash = result.Commit[:8]
+		}
+		if result.Rebased {
+			entry.AddWarning("base branch was rebased - rolling WIP ref restarted on it")
+		}
+		if result.Queued {
+			entry.PushQueued = true
+			entry.AddWarning("remote unreachable - push queued (run 'wipctl flush')")
+		}
+		entry.Outcome = "success"
+		entry.Details = describeRolling(result)
 		return entry
 	}
 
-	// Try to get the commit hash
-	if hash, err := gitexec.GetLastCommitHash(ctx, repoPath); err == nil {
-		entry.CommitHash = hash[:8] // Short hash
-	}
-
 	// Generate WIP branch name (with feature coordination if enabled)
-	timestamp := time.Now().Format("20060102-150405")
-	var wipBranch string
-	if checkpointFeature != "" {
-		wipBranch = fmt.Sprintf("wip/%s/%s/%s", hostName, checkpointFeature, timestamp)
+	wipBranch := renderWipBranch(status.Branch, checkpointFeature, time.Now())
+	entry.WipBranch = wipBranch
+
+	protected := isProtectedBranch(status.Branch)
+	if protected {
+		// Never commit onto a protected branch: write the snapshot to the WIP ref only
+		commit, err := commitSnapshot(ctx, repoPath, wipBranch, commitMsg)
+		if err != nil {
+			entry.Outcome = "failed"
+			entry.Details = "failed to create WIP commit"
+			entry.AddError(err.Error())
+			return entry
+		}
+		if len(commit) >= 8 {
+			entry.CommitHash = commit[:8]
+		}
+		entry.AddWarning(protectedSkipReason("committing to", status.Branch))
 	} else {
-		wipBranch = fmt.Sprintf("wip/%s/%s", hostName, timestamp)
+		// Create checkpoint commit
+		if err := gitexec.CommitAllowEmpty(ctx, repoPath, commitMsg); err != nil {
+			entry.Outcome = "failed"
+			entry.Details = "failed to create commit"
+			entry.AddError("git commit failed: " + err.Error())
+			return entry
+		}
+
+		// Try to get the commit hash
+		if hash, err := gitexec.GetLastCommitHash(ctx, repoPath); err == nil {
+			entry.CommitHash = hash[:8] // Short hash
+		}
+
+		// Create WIP branch
+		if err := gitexec.SwitchCreate(ctx, repoPath, wipBranch); err != nil {
+			entry.Outcome = "failed"
+			entry.Details = "failed to create WIP branch"
+			entry.AddError("git switch failed: " + err.Error())
+			return entry
+		}
 	}
-	entry.WipBranch = wipBranch
 
-	// Create and push WIP branch
-	if err := gitexec.SwitchCreate(ctx, repoPath, wipBranch); err != nil {
+	// Push WIP branch to the WIP remote (origin unless a mirror is configured)
+	wipRemote, err := ensureWipRemote(ctx, repoPath, repoName)
+	if err != nil {
 		entry.Outcome = "failed"
-		entry.Details = "failed to create WIP branch"
-		entry.AddError("git switch failed: " + err.Error())
+		entry.Details = "failed to set up WIP remote"
+		entry.AddError(err.Error())
 		return entry
 	}
 
-	// Push WIP branch to origin
-	if err := gitexec.PushUpstream(ctx, repoPath, wipBranch); err != nil {
-		entry.Outcome = "failed"
-		entry.Details = "failed to push WIP branch"
-		entry.AddError("git push failed: " + err.Error())
-		return entry
+	if err := gitexec.PushUpstreamTo(ctx, repoPath, wipRemote, wipBranch); err != nil {
+		if !queuePush(ctx, repoName, repoPath, wipRemote, wipBranch, queue.ModeUpstream, "", err) {
+			entry.Outcome = "failed"
+			entry.Details = "failed to push WIP branch"
+			entry.AddError("git push failed: " + err.Error())
+			return entry
+		}
+		// Offline: keep the local WIP branch and queue the push
+		entry.PushQueued = true
+		entry.AddWarning("remote unreachable - push queued (run 'wipctl flush')")
 	}
 
 	// Switch back to original branch
-	if err := gitexec.Switch(ctx, repoPath, status.Branch); err != nil {
-		entry.AddWarning("Failed to switch back to original branch: " + err.Error())
+	if !protected {
+		if err := gitexec.Switch(ctx, repoPath, status.Branch); err != nil {
+			entry.AddWarning("Failed to switch back to original branch: " + err.Error())
+		}
 	}
 
 	// Push original branch if it exists on origin
 	hasRemoteBranch, err := gitexec.RemoteHasBranch(ctx, repoPath, status.Branch)
-	if err == nil && hasRemoteBranch {
+	if err == nil && hasRemoteBranch && protected {
+		entry.AddWarning(protectedSkipReason("pushing", status.Branch))
+	} else if err == nil && hasRemoteBranch {
 		if err := gitexec.Push(ctx, repoPath, status.Branch); err != nil {
-			entry.AddWarning("Failed to push original branch: " + err.Error())
+			if queuePush(ctx, repoName, repoPath, "origin", status.Branch, queue.ModePlain, "", err) {
+				entry.AddWarning("origin unreachable - push of original branch queued")
+			} else {
+				entry.AddWarning("Failed to push original branch: " + err.Error())
+			}
 		}
 	}
 
 	entry.Outcome = "success"
 	entry.Details = fmt.Sprintf("checkpointed to %s", wipBranch)
+	if entry.PushQueued {
+		entry.Details += " (push queued)"
+	}
 
 	return entry
 }
 
+// checkpointCommitMessage generates a message for the staged changes, falling
+// back to a template when AI generation fails. It also returns which AI
+// provider wrote the message, naming none for the template, with the usage
+// of the AI calls.
+func checkpointCommitMessage(ctx context.Context, repoPath string, status *gitexec.RepoStatus, generator ai.Generator) (string, *ai.Attribution) {
+	ctx, attribution := ai.WithAttribution(ctx)
+	message, err := generateEnhancedCheckpointCommitMessage(ctx, repoPath, status, generator)
+	if err != nil {
+		if !errors.Is(err, ai.ErrPromptShown) {
+			ui.Warning("AI commit generation failed, using fallback: " + err.Error())
+		}
+		return generateFallbackCheckpointMessage(filepath.Base(repoPath), status), attribution.Unused()
+	}
+	return message, attribution
+}
+
 func generateEnhancedCheckpointCommitMessage(ctx context.Context, repoPath string, status *gitexec.RepoStatus, generator ai.Generator) (string, error) {
 	// Get diff information for AI
 	diffStat, err := gitexec.DiffStatCached(ctx, repoPath)
@@ -328,6 +640,7 @@ func generateEnhancedCheckpointCommitMessage(ctx context.Context, repoPath strin
 		Untracked:     []string{}, // We auto-stage everything
 		PriorSubjects: recentCommits,
 	}
+	input.Diff, input.DiffOmitted = ai.StagedDiff(ctx, repoPath, ai.LoadConfigFromEnv().DiffBudget())
 
 	// Add custom message prefix if provided
 	message, err := generator.CommitMessage(ctx, input)
@@ -335,16 +648,24 @@ func generateEnhancedCheckpointCommitMessage(ctx context.Context, repoPath strin
 		return "", err
 	}
 
-	// Add cross-repo feature context
-	if checkpointFeature != "" {
-		message = fmt.Sprintf("feat(%s): %s", checkpointFeature, message)
+	if checkpointFeature == "" && checkpointMessage == "" {
+		return message, nil
 	}
 
+	// Fold the feature and custom message into the commit rather than
+	// around its header, which must stay a valid Conventional Commit
+	commit, err := ai.ParseCommit(message)
+	if err != nil {
+		subject, body, _ := strings.Cut(strings.TrimSpace(message), "\n")
+		commit = ai.StructuredCommit{Type: "chore", Subject: subject, Body: body}
+	}
+	if checkpointFeature != "" {
+		commit.Scope = checkpointFeature
+	}
 	if checkpointMessage != "" {
-		message = fmt.Sprintf("[%s] %s", checkpointMessage, message)
+		commit.Body = strings.TrimSpace(checkpointMessage + "\n\n" + commit.Body)
 	}
-
-	return message, nil
+	return commit.Force().Render(), nil
 }
 
 func generateFallbackCheckpointMessage(repoName string, status *gitexec.RepoStatus) string {
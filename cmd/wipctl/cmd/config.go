@@ -0,0 +1,228 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/config"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/ui"
+)
+
+var configUser bool
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Show and edit wipctl configuration",
+	Long: `Manage wipctl configuration files.
+
+Configuration is JSON and is read from two places, the workspace file
+winning over the user file for any key it sets:
+  user:      $WIPCTL_CONFIG_DIR/config.json or <user config dir>/wipctl/config.json
+  workspace: <report-dir>/config.json
+
+Examples:
+  wipctl config path           # Show config file locations
+  wipctl config show           # Print the effective configuration
+  wipctl config edit           # Edit the workspace config in $EDITOR
+  wipctl config edit --user    # Edit the user config in $EDITOR
+  wipctl config trust add laptop ~/.ssh/id_ed25519.pub`,
+}
+
+var configPathCmd = &cobra.Command{
+	Use:   "path",
+	Short: "Show configuration file locations",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		for _, path := range []string{config.UserPath(), config.WorkspacePath(reportDir)} {
+			state := "missing"
+			if _, err := os.Stat(path); err == nil {
+				state = "present"
+			}
+			fmt.Printf("%s (%s)\n", path, state)
+		}
+		return nil
+	},
+}
+
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Print the effective configuration",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		data, err := json.MarshalIndent(wipConfig, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	},
+}
+
+var configEditCmd = &cobra.Command{
+	Use:   "edit",
+	Short: "Edit the workspace (or --user) config file in $EDITOR",
+	RunE:  runConfigEdit,
+}
+
+var configTrustCmd = &cobra.Command{
+	Use:   "trust",
+	Short: "Manage the trusted-hosts keyring used to verify pulled WIP refs",
+	Long: `Manage signing.trusted, the keys whose signed WIP commits pull accepts.
+
+A key is an SSH public key (or a path to a .pub file) or a GPG fingerprint.
+Unsigned or untrusted WIP refs are warned about, or refused when
+signing.verify is "require".`,
+}
+
+var configTrustListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List trusted hosts and keys",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		trusted := wipConfig.Signing.TrustedKeys()
+		if len(trusted) == 0 {
+			ui.Info("No trusted keys configured")
+			return nil
+		}
+		ui.Info(fmt.Sprintf("Verify mode: %s", wipConfig.Signing.VerifyMode()))
+		ui.InitTable("Host", "Key")
+		for _, key := range trusted {
+			ui.AddTableRow(key.Host, key.Key)
+		}
+		ui.RenderTable()
+		return nil
+	},
+}
+
+var configTrustAddCmd = &cobra.Command{
+	Use:   "add <host> <ssh-public-key|key-file|gpg-fingerprint>",
+	Short: "Trust WIP commits signed by a host's key",
+	Args:  cobra.ExactArgs(2),
+	RunE:  runConfigTrustAdd,
+}
+
+var configTrustRemoveCmd = &cobra.Command{
+	Use:   "remove <host>",
+	Short: "Stop trusting a host's keys",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runConfigTrustRemove,
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configPathCmd)
+	configCmd.AddCommand(configShowCmd)
+	configCmd.AddCommand(configEditCmd)
+	configCmd.AddCommand(configTrustCmd)
+	configTrustCmd.AddCommand(configTrustListCmd)
+	configTrustCmd.AddCommand(configTrustAddCmd)
+	configTrustCmd.AddCommand(configTrustRemoveCmd)
+
+	configEditCmd.Flags().BoolVar(&configUser, "user", false, "edit the user config instead of the workspace config")
+	configTrustCmd.PersistentFlags().BoolVar(&configUser, "user", false, "change the user config instead of the workspace config")
+}
+
+// targetConfigPath returns the workspace config, or the user config with --user
+func targetConfigPath() (string, error) {
+	path := config.WorkspacePath(reportDir)
+	if configUser {
+		path = config.UserPath()
+	}
+	if path == "" {
+		return "", fmt.Errorf("cannot determine config path")
+	}
+	return path, nil
+}
+
+func runConfigEdit(cmd *cobra.Command, args []string) error {
+	path, err := targetConfigPath()
+	if err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return fmt.Errorf("create config directory: %w", err)
+		}
+		if err := os.WriteFile(path, []byte(config.Template()), 0644); err != nil {
+			return fmt.Errorf("write config template: %w", err)
+		}
+	}
+
+	if err := ui.OpenEditor(path); err != nil {
+		ui.Error("Editor failed: " + err.Error())
+		return err
+	}
+
+	if _, err := config.Load(reportDir); err != nil {
+		ui.Warning("Config saved but is invalid: " + err.Error())
+		return err
+	}
+
+	ui.Success("Config saved: " + path)
+	return nil
+}
+
+func runConfigTrustAdd(cmd *cobra.Command, args []string) error {
+	host, key := args[0], args[1]
+	if content, err := os.ReadFile(key); err == nil {
+		key = strings.TrimSpace(string(content))
+	}
+	if err := (config.TrustedKey{Host: host, Key: key}).Validate(); err != nil {
+		return err
+	}
+
+	return updateTrustedKeys(func(trusted []config.TrustedKey) []config.TrustedKey {
+		for _, existing := range trusted {
+			if existing.Host == host && existing.Key == key {
+				return trusted
+			}
+		}
+		ui.Success(fmt.Sprintf("Trusting %s", host))
+		return append(trusted, config.TrustedKey{Host: host, Key: key})
+	})
+}
+
+func runConfigTrustRemove(cmd *cobra.Command, args []string) error {
+	return updateTrustedKeys(func(trusted []config.TrustedKey) []config.TrustedKey {
+		var kept []config.TrustedKey
+		for _, key := range trusted {
+			if key.Host != args[0] {
+				kept = append(kept, key)
+			}
+		}
+		if len(kept) == len(trusted) {
+			ui.Warning(fmt.Sprintf("No trusted keys for %s", args[0]))
+		} else {
+			ui.Success(fmt.Sprintf("Removed %d keys for %s", len(trusted)-len(kept), args[0]))
+		}
+		return kept
+	})
+}
+
+// updateTrustedKeys rewrites signing.trusted in the target config file
+func updateTrustedKeys(update func([]config.TrustedKey) []config.TrustedKey) error {
+	path, err := targetConfigPath()
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.LoadFile(path)
+	if err != nil {
+		ui.Error(err.Error())
+		return err
+	}
+	if cfg.Signing == nil {
+		cfg.Signing = &config.Signing{}
+	}
+	cfg.Signing.Trusted = update(cfg.Signing.Trusted)
+
+	if err := config.Save(path, cfg); err != nil {
+		ui.Error("Failed to save config: " + err.Error())
+		return err
+	}
+	ui.Info("Updated " + path)
+	return nil
+}
@@ -0,0 +1,11 @@
+//go:build windows
+
+package cmd
+
+import "syscall"
+
+// detachedProcAttr starts the watch daemon in a process group of its own, so
+// Ctrl-C in the console never reaches it
+func detachedProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP}
+}
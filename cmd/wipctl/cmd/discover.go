@@ -0,0 +1,22 @@
+package cmd
+
+import (
+	"context"
+	"sort"
+
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/ui"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/workspace"
+)
+
+// discoverRepos finds the workspace repositories sorted by path, so commands
+// that walk them one at a time do so in a stable order
+func discoverRepos(ctx context.Context) ([]workspace.Repo, error) {
+	ui.Info("Discovering Git repositories...")
+	repos, err := workspace.Discover(ctx, workspacePath)
+	if err != nil {
+		ui.Error("Failed to discover repositories: " + err.Error())
+		return nil, err
+	}
+	sort.Slice(repos, func(i, j int) bool { return repos[i].Path < repos[j].Path })
+	return repos, nil
+}
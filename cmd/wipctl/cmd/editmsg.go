@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/gitexec"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/msgedit"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/ui"
+)
+
+// editCommitMessages writes the proposed messages to <report-dir>/WIP_EDITMSG,
+// opens it in $EDITOR and returns the messages that were kept, by key.
+// Repositories missing from the result should not be committed. Emptying the
+// file returns msgedit.ErrAborted without asking to edit it again.
+func editCommitMessages(ctx context.Context, title string, items []msgedit.Item) (map[string]string, error) {
+	keys := make([]string, 0, len(items))
+	for _, item := range items {
+		keys = append(keys, item.Key)
+	}
+
+	content := msgedit.Format(title, items)
+	path := filepath.Join(reportDir, msgedit.FileName)
+
+	if gitexec.IsDryRun(ctx) {
+		fmt.Printf("[DRY RUN] Would open %d proposed messages in $EDITOR: %s\n", len(items), path)
+		return msgedit.Parse(content, keys)
+	}
+
+	if err := os.MkdirAll(reportDir, 0755); err != nil {
+		return nil, fmt.Errorf("create report directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return nil, fmt.Errorf("write %s: %w", path, err)
+	}
+	defer os.Remove(path)
+
+	for {
+		if err := ui.OpenEditor(path); err != nil {
+			return nil, fmt.Errorf("editor failed: %w", err)
+		}
+
+		edited, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		messages, err := msgedit.Parse(string(edited), keys)
+		if err == nil || errors.Is(err, msgedit.ErrAborted) {
+			return messages, err
+		}
+
+		ui.Error("Invalid message file: " + err.Error())
+		if !ui.Confirm("Edit the file again?") {
+			return nil, err
+		}
+	}
+}
@@ -0,0 +1,340 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/gitexec"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/queue"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/report"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/ui"
+)
+
+var flushList bool
+
+var flushCmd = &cobra.Command{
+	Use:   "flush",
+	Short: "Retry WIP pushes that were queued while offline",
+	Long: `Retry pushes recorded in the offline push queue.
+
+When push, checkpoint or watch cannot reach a remote, the local WIP commit and
+branch are kept and the push is queued in <report-dir>/` + queue.FileName + `
+instead of failing the repository. Queued pushes are retried oldest first;
+a repository's later pushes wait until its earlier ones succeed. A push the
+remote keeps rejecting (not a network failure) is dropped after a few tries.
+
+The queue is also flushed automatically after the next push, checkpoint or
+pull that reaches its remotes. 'wipctl status' shows pending pushes per
+repository.
+
+Examples:
+  wipctl flush           # Retry all queued pushes
+  wipctl flush --list    # Show the queue without pushing`,
+	RunE: runFlush,
+}
+
+var pushQueue *queue.Queue
+
+func init() {
+	rootCmd.AddCommand(flushCmd)
+	flushCmd.Flags().BoolVar(&flushList, "list", false, "list queued pushes without retrying them")
+}
+
+func runFlush(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	if dryRun {
+		ctx = context.WithValue(ctx, gitexec.DryRunKey, true)
+		ui.Info("🧪 DRY RUN MODE - No actual git operations will be performed")
+	}
+
+	entries, err := pushQueue.Entries()
+	if err != nil {
+		ui.Error("Failed to read push queue: " + err.Error())
+		return err
+	}
+	if len(entries) == 0 {
+		ui.Success("Push queue is empty")
+		return nil
+	}
+
+	if flushList {
+		displayPushQueue(entries)
+		return nil
+	}
+
+	ui.Info(fmt.Sprintf("Retrying %d queued pushes...", len(entries)))
+
+	rep := report.NewReport("WIP Flush Report", workspacePath, reportDir, "flush")
+	results, err := flushPushQueue(ctx)
+	if err != nil {
+		ui.Error("Failed to update push queue: " + err.Error())
+		return err
+	}
+	for _, entry := range results {
+		rep.AddEntry(entry)
+	}
+	if err := rep.Save(); err != nil {
+		ui.Warning("Failed to save report: " + err.Error())
+	}
+
+	summarizeFlush(results)
+	return nil
+}
+
+// displayPushQueue renders the queued pushes, oldest first
+func displayPushQueue(entries []queue.Entry) {
+	ui.InitTable("Repository", "Remote", "Branch", "Queued", "Attempts", "Last Error")
+	for _, entry := range entries {
+		lastError := entry.LastError
+		if lastError == "" {
+			lastError = "-"
+		}
+		ui.AddTableRow(
+			ui.CyberText(entry.Repo, "repo"),
+			entry.Remote,
+			ui.CyberText(entry.Branch, "branch"),
+			entry.Queued.Format("2006-01-02 15:04:05"),
+			fmt.Sprintf("%d", entry.Attempts),
+			lastError,
+		)
+	}
+	ui.RenderTable()
+}
+
+// queuePush records a push that failed because the remote was unreachable.
+// It returns false when err is not a network error, so the caller reports
+// the failure as usual.
+func queuePush(ctx context.Context, repoName, repoPath, remote, branch, mode, lease string, err error) bool {
+	if !gitexec.IsNetworkError(err) {
+		return false
+	}
+
+	path, absErr := filepath.Abs(repoPath)
+	if absErr != nil {
+		path = repoPath
+	}
+
+	entry := queue.Entry{
+		Repo:      repoName,
+		Path:      path,
+		Remote:    remote,
+		Branch:    branch,
+		Commit:    gitexec.ResolveRef(ctx, repoPath, "refs/heads/"+branch),
+		Mode:      mode,
+		Lease:     lease,
+		Queued:    time.Now(),
+		LastError: err.Error(),
+	}
+	if qErr := pushQueue.Add(entry); qErr != nil {
+		slog.Error("Failed to queue push", "repo", repoPath, "branch", branch, "error", qErr)
+		return false
+	}
+
+	slog.Warn("Remote unreachable, push queued", "repo", repoPath, "remote", remote, "branch", branch)
+	return true
+}
+
+// dequeuePush drops a queued push superseded by one that just went through
+func dequeuePush(repoPath, remote, branch string) {
+	path, err := filepath.Abs(repoPath)
+	if err != nil {
+		path = repoPath
+	}
+	if err := pushQueue.Remove(path, remote, branch); err != nil {
+		slog.Warn("Failed to update push queue", "repo", repoPath, "error", err)
+	}
+}
+
+// flushPushQueue retries queued pushes in order. A repository whose push
+// fails keeps its later entries queued behind it. The pushes run without
+// holding the queue lock; the results are merged into a fresh read of the
+// queue so entries added or replaced meanwhile are kept.
+func flushPushQueue(ctx context.Context) ([]report.ReportEntry, error) {
+	entries, err := pushQueue.Entries()
+	if err != nil {
+		return nil, err
+	}
+
+	var results []report.ReportEntry
+	retried := make(map[string]flushOutcome)
+	blocked := make(map[string]bool)
+
+	for _, queued := range entries {
+		entry := report.ReportEntry{
+			Repo:    queued.Repo,
+			Details: fmt.Sprintf("%s → %s", queued.Branch, queued.Remote),
+		}
+
+		if blocked[queued.Path] {
+			entry.Outcome = "queued"
+			entry.AddWarning("waiting on an earlier queued push")
+			results = append(results, entry)
+			continue
+		}
+
+		if gitexec.IsDryRun(ctx) {
+			fmt.Printf("[DRY RUN] Would retry queued push: %s %s (in %s)\n", queued.Remote, queued.Branch, queued.Path)
+			entry.Outcome = "queued"
+			results = append(results, entry)
+			continue
+		}
+
+		if queued.Mode == queue.ModePlain && isProtectedBranch(queued.Branch) {
+			entry.Outcome = "skipped"
+			entry.AddWarning(protectedSkipReason("pushing", queued.Branch) + " - dropped from queue")
+			results = append(results, entry)
+			retried[queued.Key()] = flushOutcome{entry: queued, drop: true}
+			continue
+		}
+
+		if alreadyPushed(ctx, queued) {
+			entry.Outcome = "success"
+			entry.AddWarning("already on remote - dropped from queue")
+			results = append(results, entry)
+			retried[queued.Key()] = flushOutcome{entry: queued, drop: true}
+			continue
+		}
+
+		if err := retryQueuedPush(ctx, queued); err != nil {
+			queued.Attempts++
+			queued.LastError = err.Error()
+
+			switch {
+			case gitexec.IsNetworkError(err):
+				entry.Outcome = "queued"
+				entry.AddWarning("remote still unreachable")
+				blocked[queued.Path] = true
+				retried[queued.Key()] = flushOutcome{entry: queued}
+			case queued.Failures+1 >= queue.MaxFailures:
+				entry.Outcome = "error"
+				entry.AddError(fmt.Sprintf("push failed %d times - dropped from queue: %v", queued.Failures+1, err))
+				retried[queued.Key()] = flushOutcome{entry: queued, drop: true}
+			default:
+				queued.Failures++
+				entry.Outcome = "error"
+				entry.AddError(fmt.Sprintf("push failed: %v", err))
+				blocked[queued.Path] = true
+				retried[queued.Key()] = flushOutcome{entry: queued}
+			}
+			results = append(results, entry)
+			continue
+		}
+
+		entry.Outcome = "success"
+		ui.Success(fmt.Sprintf("%s: pushed queued %s", queued.Repo, queued.Branch))
+		results = append(results, entry)
+		retried[queued.Key()] = flushOutcome{entry: queued, drop: true}
+	}
+
+	if len(retried) == 0 {
+		return results, nil
+	}
+
+	err = pushQueue.Update(func(current []queue.Entry) []queue.Entry {
+		var remaining []queue.Entry
+		for _, queued := range current {
+			outcome, ok := retried[queued.Key()]
+			if !ok || outcome.entry.Commit != queued.Commit {
+				// Queued or replaced by a newer tip while we were pushing
+				remaining = append(remaining, queued)
+				continue
+			}
+			if !outcome.drop {
+				remaining = append(remaining, outcome.entry)
+			}
+		}
+		return remaining
+	})
+
+	return results, err
+}
+
+// flushOutcome is what a retry decided for one queued push
+type flushOutcome struct {
+	entry queue.Entry
+	drop  bool
+}
+
+// retryQueuedPush repeats the git push a queue entry was recorded for
+func retryQueuedPush(ctx context.Context, entry queue.Entry) error {
+	switch entry.Mode {
+	case queue.ModeLease:
+		return gitexec.PushWithLease(ctx, entry.Path, entry.Remote, entry.Branch, entry.Lease)
+	case queue.ModePlain:
+		return gitexec.Push(ctx, entry.Path, entry.Branch)
+	default:
+		return gitexec.PushUpstreamTo(ctx, entry.Path, entry.Remote, entry.Branch)
+	}
+}
+
+// alreadyPushed reports whether the queued commit has since reached the remote
+func alreadyPushed(ctx context.Context, entry queue.Entry) bool {
+	if entry.Commit == "" {
+		return false
+	}
+	tracking := gitexec.ResolveRef(ctx, entry.Path, "refs/remotes/"+entry.Remote+"/"+entry.Branch)
+	local := gitexec.ResolveRef(ctx, entry.Path, "refs/heads/"+entry.Branch)
+	return tracking != "" && tracking == local && gitexec.IsAncestor(ctx, entry.Path, entry.Commit, tracking)
+}
+
+// autoFlushPushQueue retries queued pushes after a command that reached its
+// remotes, unless that command queued pushes of its own
+func autoFlushPushQueue(ctx context.Context, entries []report.ReportEntry) {
+	if gitexec.IsDryRun(ctx) || pushQueue.Added() > 0 {
+		return
+	}
+
+	online := false
+	for _, entry := range entries {
+		if entry.Outcome == "success" || entry.Outcome == "no-wip" {
+			online = true
+			break
+		}
+	}
+	if !online {
+		return
+	}
+
+	queued, err := pushQueue.Entries()
+	if err != nil || len(queued) == 0 {
+		return
+	}
+
+	ui.Info(fmt.Sprintf("📤 Network is back - retrying %d queued pushes...", len(queued)))
+	results, err := flushPushQueue(ctx)
+	if err != nil {
+		ui.Warning("Failed to update push queue: " + err.Error())
+		return
+	}
+	summarizeFlush(results)
+}
+
+// summarizeFlush prints how many queued pushes went through
+func summarizeFlush(results []report.ReportEntry) {
+	pushed, pending, failed := 0, 0, 0
+	for _, entry := range results {
+		switch entry.Outcome {
+		case "success":
+			pushed++
+		case "queued":
+			pending++
+		case "skipped":
+			ui.Warning(fmt.Sprintf("%s: %s", entry.Repo, strings.Join(entry.Warnings, "; ")))
+		default:
+			failed++
+			ui.Error(fmt.Sprintf("%s: %s", entry.Repo, strings.Join(entry.Errors, "; ")))
+		}
+	}
+
+	switch {
+	case pending == 0 && failed == 0:
+		ui.Success(fmt.Sprintf("Flushed %d queued pushes", pushed))
+	default:
+		ui.Warning(fmt.Sprintf("Flushed %d queued pushes, %d still queued, %d failed - run 'wipctl flush' to retry", pushed, pending, failed))
+	}
+}
@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/config"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/gates"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/report"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/ui"
+)
+
+// gatesTrailer notes failed quality gates in annotated commit messages
+const gatesTrailer = "Wip-Gates-Failed"
+
+var (
+	gatesOn  bool
+	gatesOff bool
+)
+
+// gatesEnabled reports whether quality gates run for this invocation
+func gatesEnabled() bool {
+	if gatesOff {
+		return false
+	}
+	return gatesOn || wipConfig.Gates.IsEnabled()
+}
+
+// runRepoGates runs a repository's quality gates and converts the results
+// into report records. Repositories without gates return nil.
+func runRepoGates(ctx context.Context, repoPath, repoName string) []report.GateRun {
+	commands := gates.Commands(repoPath, repoName, wipConfig.Gates)
+	if len(commands) == 0 {
+		return nil
+	}
+
+	timeout := wipConfig.Gates.CommandTimeout(gates.DefaultTimeout)
+	var runs []report.GateRun
+	for _, result := range gates.Run(ctx, repoPath, commands, timeout) {
+		run := report.GateRun{
+			Command:  result.Command,
+			Duration: result.Duration,
+			Output:   result.Output,
+		}
+		if result.Err != nil {
+			run.Error = result.Err.Error()
+		}
+		runs = append(runs, run)
+	}
+	return runs
+}
+
+// runGatesParallel runs the gates of many repositories under a concurrency
+// limit, returning the results by repository path
+func runGatesParallel(ctx context.Context, repoPaths []string, concurrency int) map[string][]report.GateRun {
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, concurrency)
+	results := make(map[string][]report.GateRun)
+
+	for _, repoPath := range repoPaths {
+		wg.Add(1)
+		go func(repoPath string) {
+			defer wg.Done()
+
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			runs := runRepoGates(ctx, repoPath, filepath.Base(repoPath))
+
+			mu.Lock()
+			results[repoPath] = runs
+			mu.Unlock()
+		}(repoPath)
+	}
+
+	wg.Wait()
+	return results
+}
+
+// failedGates returns the commands of the gate runs that failed
+func failedGates(runs []report.GateRun) []string {
+	var failed []string
+	for _, run := range runs {
+		if run.Error != "" {
+			failed = append(failed, run.Command)
+		}
+	}
+	return failed
+}
+
+// applyGatePolicy records gate runs on the entry and applies the repository's
+// policy to failures. It returns false when the commit must be blocked.
+func applyGatePolicy(repoName string, runs []report.GateRun, entry *report.ReportEntry) bool {
+	entry.Gates = append(entry.Gates, runs...)
+
+	failed := failedGates(runs)
+	if len(failed) == 0 {
+		return true
+	}
+
+	reason := "quality gate failed: " + strings.Join(failed, ", ")
+	switch wipConfig.Gates.PolicyFor(repoName) {
+	case config.GateBlock:
+		entry.Outcome = "skipped"
+		entry.AddWarning(reason)
+		ui.Warning(fmt.Sprintf("%s: %s - not committing", repoName, reason))
+		return false
+	case config.GateWarn:
+		entry.AddWarning(reason)
+		ui.Warning(fmt.Sprintf("%s: %s", repoName, reason))
+	}
+	return true
+}
+
+// gateBlocked reports whether failing gates block a repository's commit
+func gateBlocked(repoName string, runs []report.GateRun) bool {
+	return len(failedGates(runs)) > 0 && wipConfig.Gates.PolicyFor(repoName) == config.GateBlock
+}
+
+// annotateGates adds a trailer naming failed gates to the commit message
+// when the repository's policy is annotate
+func annotateGates(repoName, message string, runs []report.GateRun) string {
+	failed := failedGates(runs)
+	if len(failed) == 0 || wipConfig.Gates.PolicyFor(repoName) != config.GateAnnotate {
+		return message
+	}
+	return fmt.Sprintf("%s\n\n%s: %s", strings.TrimRight(message, "\n"), gatesTrailer, strings.Join(failed, ", "))
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/ai"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/config"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/workspace"
+)
+
+func TestPushGatePolicies(t *testing.T) {
+	tests := []struct {
+		policy  string
+		outcome string
+		trailer bool
+	}{
+		{policy: config.GateBlock, outcome: "skipped"},
+		{policy: config.GateWarn, outcome: "success"},
+		{policy: config.GateAnnotate, outcome: "success", trailer: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			root, origin := setupOrigin(t)
+			repo := clone(t, root, origin, "api")
+			writeFile(t, filepath.Join(repo, "notes.txt"), "todo\n")
+			wipConfig.Gates = &config.Gates{
+				Enabled: true,
+				Repos:   map[string]config.RepoGates{"api": {Commands: []string{"echo broken; exit 1"}, Policy: tt.policy}},
+			}
+
+			const wipBranch = "wip/laptop/20260101-120000"
+			entry := processRepoPush(context.Background(), workspace.Repo{Name: "api", Path: repo}, &ai.NoneGenerator{}, wipBranch)
+			if entry.Outcome != tt.outcome {
+				t.Fatalf("outcome = %q, want %q (errors: %v)", entry.Outcome, tt.outcome, entry.Errors)
+			}
+			if len(entry.Gates) != 1 || entry.Gates[0].Output != "broken" || entry.Gates[0].Error == "" {
+				t.Errorf("gate runs = %+v, want the failed gate with its output", entry.Gates)
+			}
+
+			pushed := git(t, origin, "branch", "--list", wipBranch)
+			if tt.outcome == "skipped" {
+				if pushed != "" {
+					t.Errorf("blocked repo was pushed: %q", pushed)
+				}
+				if got := git(t, repo, "status", "--porcelain"); got != "?? notes.txt" {
+					t.Errorf("worktree = %q, want the change left untouched", got)
+				}
+				return
+			}
+			if pushed == "" {
+				t.Fatalf("origin has no %s", wipBranch)
+			}
+			message := git(t, origin, "log", "-1", "--format=%B", wipBranch)
+			if has := strings.Contains(message, gatesTrailer+": echo broken; exit 1"); has != tt.trailer {
+				t.Errorf("commit message %q: trailer present = %v, want %v", message, has, tt.trailer)
+			}
+		})
+	}
+}
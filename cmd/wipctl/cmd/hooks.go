@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/gitexec"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/hooks"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/report"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/ui"
+)
+
+// runOperationHook runs the workspace-level pre-<op>/post-<op> hooks
+func runOperationHook(ctx context.Context, name, op, outcome string) error {
+	results, err := hookRunner.Run(ctx, name, hooks.Event{
+		Operation: op,
+		Workspace: workspacePath,
+		Outcome:   outcome,
+	})
+
+	for _, result := range results {
+		if result.Output != "" {
+			ui.Info(fmt.Sprintf("🪝 %s: %s\n%s", name, result.Command, result.Output))
+		}
+	}
+
+	if err != nil {
+		ui.Error(err.Error())
+	}
+	return err
+}
+
+// runRepoHook runs per-repository hooks and records them on the report entry.
+// It returns an error only when a hook failed.
+func runRepoHook(ctx context.Context, name string, entry *report.ReportEntry, event hooks.Event) error {
+	event.Workspace = workspacePath
+	event.Repo = entry.Repo
+
+	results, err := hookRunner.Run(ctx, name, event)
+	for _, result := range results {
+		run := report.HookRun{
+			Hook:    result.Hook,
+			Command: result.Command,
+			Output:  result.Output,
+		}
+		if result.Err != nil {
+			run.Error = result.Err.Error()
+		}
+		entry.AddHookRun(run)
+	}
+
+	return err
+}
+
+// skipOnPreRepoHook runs the pre-repo hook and marks the entry skipped when it fails
+func skipOnPreRepoHook(ctx context.Context, entry *report.ReportEntry, event hooks.Event) bool {
+	if err := runRepoHook(ctx, hooks.PreRepo, entry, event); err != nil {
+		entry.Outcome = "skipped"
+		entry.AddWarning(err.Error())
+		ui.Warning(fmt.Sprintf("%s: %s", entry.Repo, err.Error()))
+		return true
+	}
+	return false
+}
+
+// finishRepoHook runs the post-repo hook with the final outcome
+func finishRepoHook(ctx context.Context, entry *report.ReportEntry, event hooks.Event) {
+	event.Outcome = entry.Outcome
+	if err := runRepoHook(ctx, hooks.PostRepo, entry, event); err != nil {
+		entry.AddWarning(err.Error())
+	}
+}
+
+// repoHookEvent builds the base hook event for a repository
+func repoHookEvent(ctx context.Context, op, repoPath, wipBranch string) hooks.Event {
+	branch, _ := gitexec.CurrentBranch(ctx, repoPath)
+	return hooks.Event{
+		Operation: op,
+		RepoPath:  repoPath,
+		Branch:    branch,
+		WipBranch: wipBranch,
+	}
+}
+
+// operationOutcome summarizes a run for post-<op> hooks
+func operationOutcome(entries []report.ReportEntry) string {
+	for _, entry := range entries {
+		if entry.Outcome == "error" || entry.Outcome == "failed" || entry.Outcome == "conflicts" {
+			return "partial"
+		}
+	}
+	return "success"
+}
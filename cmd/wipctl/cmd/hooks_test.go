@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/ai"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/config"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/gitexec"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/hooks"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/workspace"
+)
+
+func TestFailingPreRepoHookSkipsRepo(t *testing.T) {
+	root, origin := setupOrigin(t)
+	repo := clone(t, root, origin, "api")
+	writeFile(t, filepath.Join(repo, "notes.txt"), "todo\n")
+	outcome := filepath.Join(root, "outcome.txt")
+	hookRunner = hooks.NewRunner(map[string][]config.Hook{
+		hooks.PreRepo:  {{Run: "echo not today; exit 1"}},
+		hooks.PostRepo: {{Run: `echo "$WIPCTL_OUTCOME" > ` + outcome}},
+	})
+
+	entry := processRepoPush(context.Background(), workspace.Repo{Name: "api", Path: repo}, &ai.NoneGenerator{}, "wip/laptop/20260101-120000")
+	if entry.Outcome != "skipped" {
+		t.Errorf("outcome = %q, want skipped", entry.Outcome)
+	}
+	if len(entry.Hooks) != 2 || entry.Hooks[0].Output != "not today" || entry.Hooks[0].Error == "" {
+		t.Errorf("hook runs = %+v, want the failed pre-repo hook and the post-repo hook", entry.Hooks)
+	}
+	if got, _ := os.ReadFile(outcome); strings.TrimSpace(string(got)) != "skipped" {
+		t.Errorf("post-repo hook saw outcome %q, want skipped", got)
+	}
+	if got := git(t, origin, "branch", "--list", "wip/*"); got != "" {
+		t.Errorf("origin got WIP branches %q, want none", got)
+	}
+	if got := git(t, repo, "status", "--porcelain"); got != "?? notes.txt" {
+		t.Errorf("worktree = %q, want the change left untouched", got)
+	}
+}
+
+func TestFailingPreOperationHookAbortsCheckpoint(t *testing.T) {
+	root, origin := setupOrigin(t)
+	repo := clone(t, root, origin, "api")
+	writeFile(t, filepath.Join(repo, "notes.txt"), "todo\n")
+	workspacePath = root
+	hookRunner = hooks.NewRunner(map[string][]config.Hook{
+		hooks.Pre("checkpoint"): {{Run: "exit 1"}},
+		hooks.PreRepo:           {{Run: "touch " + filepath.Join(root, "pre-repo-ran")}},
+	})
+
+	ctx := context.Background()
+	status, err := gitexec.Status(ctx, repo)
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	results := map[string]*gitexec.RepoStatus{repo: status}
+
+	if _, err := checkpointRepositories(ctx, "Checkpoint", []string{repo}, results, 1, &ai.NoneGenerator{}); err == nil {
+		t.Fatal("checkpoint ran despite the failing pre-checkpoint hook")
+	}
+	if _, err := os.Stat(filepath.Join(root, "pre-repo-ran")); err == nil {
+		t.Error("pre-repo hook ran after pre-checkpoint failed")
+	}
+	if got := git(t, repo, "rev-list", "--count", "--all"); got != "1" {
+		t.Errorf("repository has %s commits, want only the initial one", got)
+	}
+}
@@ -0,0 +1,733 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/ai"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/gitexec"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/queue"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/report"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/ui"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/workspace"
+)
+
+var (
+	promoteOnto    string
+	promoteFeature string
+	promoteFrom    string
+	promoteRegroup bool
+	promotePush    bool
+	promoteCleanup bool
+)
+
+// promotedConfigKey records in branch.<name>.<key> the base..tip range a
+// branch was promoted from, so its WIP refs can be removed later
+const promotedConfigKey = "wipctlPromoted"
+
+// promoteSubjectLimit caps the WIP subjects sent with each AI request
+const promoteSubjectLimit = 30
+
+var promoteCmd = &cobra.Command{
+	Use:   "promote <branch> [repository-path]",
+	Short: "Turn a WIP chain into a clean feature branch",
+	Long: `Rebuild the history of a WIP chain as a new branch, ready for a pull request.
+
+The chain ends at the newest WIP branch (of --feature, or the --from ref) and
+starts where it forks from the --onto branch (default: the repository's
+default branch). A new branch is created at that merge base with:
+- one squashed commit holding the whole chain (default), or
+- with --regroup, one commit per directory touched
+
+Commit messages are written by the configured AI provider from the diff and
+the WIP commit subjects, falling back to a template. A PR description is
+drafted from the same data and saved to <report-dir>/pr-<repo>-<branch>.md.
+
+The new branch is built from commit objects alone: the worktree, index and
+working branch are not touched. WIP refs are left intact until you confirm
+their deletion at the end of the run, or later with --cleanup.
+
+Examples:
+  wipctl promote feature/parser ./api              # Squash api's latest WIP chain
+  wipctl promote feature/parser ./api --regroup    # One commit per directory
+  wipctl promote feature/login --feature=login     # Every repo with WIP refs of feature login
+  wipctl promote feature/parser ./api --push       # Push the new branch to origin
+  wipctl promote feature/parser ./api --cleanup    # Delete the promoted WIP refs`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: runPromote,
+}
+
+func init() {
+	rootCmd.AddCommand(promoteCmd)
+	promoteCmd.Flags().StringVar(&promoteOnto, "onto", "", "branch the feature branch starts from (default: the repository's default branch)")
+	promoteCmd.Flags().StringVar(&promoteFeature, "feature", "", "promote the WIP refs of a cross-repo feature, in every repository when no path is given")
+	promoteCmd.Flags().StringVar(&promoteFrom, "from", "", "WIP branch or commit the chain ends at (default: the newest WIP branch)")
+	promoteCmd.Flags().BoolVar(&promoteRegroup, "regroup", false, "split the chain into one commit per directory instead of squashing it")
+	promoteCmd.Flags().BoolVar(&promotePush, "push", false, "push the new branch to origin")
+	promoteCmd.Flags().BoolVar(&promoteCleanup, "cleanup", false, "delete the WIP refs of a branch promoted earlier")
+	promoteCmd.MarkFlagsMutuallyExclusive("feature", "from")
+}
+
+// promotion is a WIP chain rebuilt as a feature branch
+type promotion struct {
+	repo   workspace.Repo
+	branch string
+	onto   string
+	base   string   // merge base with the onto branch, where the branch starts
+	tip    string   // last commit of the WIP chain
+	from   string   // name of the WIP ref or revision the chain ends at
+	wips   []string // subjects of the WIP commits, oldest first
+	groups []promoteGroup
+	refs   []wipRef // WIP refs inside the chain, deleted on cleanup
+}
+
+// promoteGroup is one commit of a promoted branch
+type promoteGroup struct {
+	scope   string   // directory the paths are in; "" for a squashed chain
+	paths   []string // nil for a squashed chain
+	message string
+	commit  string
+}
+
+func runPromote(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	if dryRun {
+		ctx = context.WithValue(ctx, gitexec.DryRunKey, true)
+		ui.Info("🧪 DRY RUN MODE - No actual git operations will be performed")
+	}
+
+	ctx = withSigning(ctx)
+
+	branch := args[0]
+	if isProtectedBranch(branch) {
+		ui.Error(fmt.Sprintf("Refusing to promote onto protected branch %s", branch))
+		return fmt.Errorf("%s is a protected branch", branch)
+	}
+	if _, ok := wipNames.Parse(branch); ok {
+		ui.Error(fmt.Sprintf("%s is a WIP branch name - pick a feature branch name", branch))
+		return fmt.Errorf("%s is a WIP branch name", branch)
+	}
+
+	var repos []workspace.Repo
+	switch {
+	case len(args) > 1:
+		if !isGitRepository(args[1]) {
+			ui.Error("Not a git repository: " + args[1])
+			return fmt.Errorf("not a git repository")
+		}
+		absPath, err := filepath.Abs(args[1])
+		if err != nil {
+			return err
+		}
+		repos = []workspace.Repo{{Path: args[1], Name: filepath.Base(absPath)}}
+	case promoteFeature != "" || promoteCleanup:
+		ui.Info("Discovering Git repositories...")
+		found, err := workspace.Discover(ctx, workspacePath)
+		if err != nil {
+			ui.Error("Failed to discover repositories: " + err.Error())
+			return err
+		}
+		repos = found
+	default:
+		ui.Error("Give a repository path, or --feature to promote a feature across the workspace")
+		return fmt.Errorf("no repository or feature to promote")
+	}
+
+	if promoteCleanup {
+		return runPromoteCleanup(ctx, repos, branch)
+	}
+
+	aiConfig := ai.LoadConfigFromEnv()
+	generator := ai.NewGenerator(aiConfig)
+	useAI := aiConfig.Provider != "" && aiConfig.Provider != "none"
+
+	rep := report.NewReport("WIP Promote Report", workspacePath, reportDir, "promote")
+
+	var promoted []*promotion
+	for _, repo := range repos {
+		entry, p := promoteRepo(ctx, repo, branch, generator, useAI)
+		if p == nil && promoteFeature != "" && len(args) == 1 && entry.Outcome == "skipped" {
+			// Repositories without the feature's WIP refs are not part of it
+			continue
+		}
+		rep.AddEntry(entry)
+		if p != nil {
+			promoted = append(promoted, p)
+		}
+	}
+
+	if err := rep.Save(); err != nil {
+		ui.Warning("Failed to save report: " + err.Error())
+	}
+	showAIUsage(rep.AIUsage())
+
+	if len(promoted) == 0 {
+		if len(rep.Entries) == 0 {
+			ui.Warning(fmt.Sprintf("No WIP refs found for feature %s", promoteFeature))
+		}
+		return nil
+	}
+
+	confirmPromoteCleanup(ctx, promoted, branch)
+	ui.Success("Promote operation completed. Report saved.")
+	return nil
+}
+
+// promoteRepo builds the feature branch of one repository. The promotion is
+// nil when nothing was created.
+func promoteRepo(ctx context.Context, repo workspace.Repo, branch string, generator ai.Generator, useAI bool) (report.ReportEntry, *promotion) {
+	entry := report.ReportEntry{Repo: repo.Name}
+
+	p, err := planPromotion(ctx, repo, branch)
+	if err != nil {
+		entry.Outcome = "error"
+		entry.AddError(err.Error())
+		ui.Error(fmt.Sprintf("%s: %v", repo.Name, err))
+		return entry, nil
+	}
+	if p == nil {
+		entry.Outcome = "skipped"
+		return entry, nil
+	}
+	if len(p.groups) == 0 {
+		entry.Outcome = "skipped"
+		entry.Details = fmt.Sprintf("%s has no changes against %s", p.from, p.onto)
+		ui.Warning(fmt.Sprintf("%s: %s", repo.Name, entry.Details))
+		return entry, nil
+	}
+
+	ui.Info(fmt.Sprintf("%s: promoting %d WIP commits from %s onto %s", repo.Name, len(p.wips), p.from, p.onto))
+
+	parent := p.base
+	for i := range p.groups {
+		group := &p.groups[i]
+
+		message, attribution := promoteCommitMessage(ctx, p, *group, generator, useAI)
+		group.message = message
+		entry.Usage = append(entry.Usage, attribution.Calls()...)
+		if entry.AI == "" {
+			entry.AI = attribution.String()
+		}
+
+		tree, err := promoteTree(ctx, p, parent, *group)
+		if err != nil {
+			entry.Outcome = "error"
+			entry.AddError(fmt.Sprintf("build tree: %v", err))
+			return entry, nil
+		}
+		commit, err := gitexec.CommitTree(ctx, repo.Path, tree, message, parent)
+		if err != nil {
+			entry.Outcome = "error"
+			entry.AddError(fmt.Sprintf("commit-tree: %v", err))
+			return entry, nil
+		}
+		group.commit = commit
+		parent = commit
+	}
+
+	if !gitexec.IsDryRun(ctx) {
+		built, _ := gitexec.TreeOf(ctx, repo.Path, parent)
+		want, _ := gitexec.TreeOf(ctx, repo.Path, p.tip)
+		if built != want {
+			entry.Outcome = "error"
+			entry.AddError(fmt.Sprintf("rebuilt tree %s does not match %s", built, p.from))
+			return entry, nil
+		}
+	}
+
+	if err := gitexec.CreateBranch(ctx, repo.Path, branch, parent); err != nil {
+		entry.Outcome = "error"
+		entry.AddError(fmt.Sprintf("create %s failed: %v", branch, err))
+		return entry, nil
+	}
+	if err := gitexec.SetConfig(ctx, repo.Path, promotedKey(branch), p.base+".."+p.tip); err != nil {
+		entry.AddWarning(fmt.Sprintf("failed to record the promotion, --cleanup will not find it: %v", err))
+	}
+
+	description, attribution := promoteDescription(ctx, p, generator, useAI)
+	entry.Usage = append(entry.Usage, attribution.Calls()...)
+	if entry.AI == "" {
+		entry.AI = attribution.String()
+	}
+	descriptionFile := ""
+	if !gitexec.IsDryRun(ctx) {
+		if descriptionFile, err = savePromoteDescription(repo.Name, branch, description); err != nil {
+			entry.AddWarning("failed to save PR description: " + err.Error())
+		}
+	}
+
+	if promotePush {
+		if err := gitexec.PushUpstreamTo(ctx, repo.Path, "origin", branch); err != nil {
+			if queuePush(ctx, repo.Name, repo.Path, "origin", branch, queue.ModeUpstream, "", err) {
+				entry.AddWarning(fmt.Sprintf("origin unreachable - push of %s queued (run 'wipctl flush')", branch))
+			} else {
+				entry.AddWarning(fmt.Sprintf("failed to push %s: %v", branch, err))
+			}
+		}
+	}
+
+	entry.Outcome = "success"
+	entry.Details = fmt.Sprintf("%s: %d WIP commits from %s → %d commits on %s", branch, len(p.wips), p.from, len(p.groups), p.onto)
+	if descriptionFile != "" {
+		entry.Details += fmt.Sprintf(" (PR description: %s)", filepath.Base(descriptionFile))
+	}
+
+	displayPromotion(p, description)
+	return entry, p
+}
+
+// planPromotion finds the WIP chain of a repository and splits it into the
+// commits of the new branch. It returns nil when the repository has no WIP
+// refs of the requested feature.
+func planPromotion(ctx context.Context, repo workspace.Repo, branch string) (*promotion, error) {
+	if gitexec.ResolveRef(ctx, repo.Path, "refs/heads/"+branch) != "" {
+		return nil, fmt.Errorf("branch %s already exists", branch)
+	}
+
+	remote := wipConfig.WipRemote.RemoteName()
+	if err := gitexec.FetchRemote(ctx, repo.Path, remote); err != nil {
+		slog.Warn("Fetch before promote failed, using known WIP refs", "repo", repo.Path, "remote", remote, "error", err)
+	}
+
+	refs, err := promoteWipRefs(ctx, repo.Path, remote)
+	if err != nil {
+		return nil, fmt.Errorf("list WIP refs: %w", err)
+	}
+
+	p := &promotion{repo: repo, branch: branch}
+	switch {
+	case promoteFrom != "":
+		p.from = promoteFrom
+		p.tip = gitexec.ResolveRef(ctx, repo.Path, promoteFrom)
+		if p.tip == "" {
+			return nil, fmt.Errorf("%s is not a commit", promoteFrom)
+		}
+	default:
+		newest := -1
+		for i, ref := range refs {
+			if promoteFeature != "" && ref.Fields.Feature != promoteFeature {
+				continue
+			}
+			if newest < 0 || ref.Time.After(refs[newest].Time) {
+				newest = i
+			}
+		}
+		if newest < 0 {
+			if promoteFeature != "" {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("no WIP branches found")
+		}
+		p.from, p.tip = refs[newest].Branch, refs[newest].Commit
+	}
+
+	p.onto = promoteOnto
+	if p.onto == "" {
+		p.onto = gitexec.DefaultBranch(ctx, repo.Path, "origin")
+	}
+	ontoRef := "refs/remotes/origin/" + p.onto
+	if gitexec.ResolveRef(ctx, repo.Path, ontoRef) == "" {
+		ontoRef = "refs/heads/" + p.onto
+		if gitexec.ResolveRef(ctx, repo.Path, ontoRef) == "" {
+			return nil, fmt.Errorf("branch %s to promote onto not found (use --onto)", p.onto)
+		}
+	}
+
+	p.base, err = gitexec.MergeBase(ctx, repo.Path, ontoRef, p.tip)
+	if err != nil || p.base == "" {
+		return nil, fmt.Errorf("%s shares no history with %s", p.from, p.onto)
+	}
+
+	p.wips, err = gitexec.LogSubjectsRange(ctx, repo.Path, p.base, p.tip)
+	if err != nil {
+		return nil, fmt.Errorf("read WIP history: %w", err)
+	}
+	p.refs = chainWipRefs(ctx, repo.Path, refs, p.base, p.tip)
+
+	paths, err := gitexec.DiffNames(ctx, repo.Path, p.base, p.tip)
+	if err != nil {
+		return nil, fmt.Errorf("diff %s: %w", p.from, err)
+	}
+	switch {
+	case len(paths) == 0:
+	case promoteRegroup:
+		p.groups = groupByDirectory(paths)
+	default:
+		p.groups = []promoteGroup{{}}
+	}
+	return p, nil
+}
+
+// promoteWipRefs lists the local WIP branches and those of the WIP remote
+func promoteWipRefs(ctx context.Context, repoPath, remote string) ([]wipRef, error) {
+	local, err := listWipRefs(ctx, repoPath, "")
+	if err != nil {
+		return nil, err
+	}
+	remoteRefs, err := listWipRefs(ctx, repoPath, remote)
+	if err != nil {
+		return nil, err
+	}
+	return append(local, remoteRefs...), nil
+}
+
+// chainWipRefs keeps the refs whose commits are in base..tip
+func chainWipRefs(ctx context.Context, repoPath string, refs []wipRef, base, tip string) []wipRef {
+	var chain []wipRef
+	for _, ref := range refs {
+		if gitexec.IsAncestor(ctx, repoPath, ref.Commit, tip) && !gitexec.IsAncestor(ctx, repoPath, ref.Commit, base) {
+			chain = append(chain, ref)
+		}
+	}
+	return chain
+}
+
+// groupByDirectory splits changed paths into one group per directory, in
+// path order. A path under another changed path (a file that became a
+// directory, or back) joins that path's group, so the swap is one commit.
+func groupByDirectory(paths []string) []promoteGroup {
+	changed := make(map[string]bool, len(paths))
+	for _, file := range paths {
+		changed[file] = true
+	}
+
+	byDir := make(map[string][]string)
+	for _, file := range paths {
+		dir := path.Dir(file)
+		for parent := dir; parent != "."; parent = path.Dir(parent) {
+			if changed[parent] {
+				dir = path.Dir(parent)
+			}
+		}
+		byDir[dir] = append(byDir[dir], file)
+	}
+
+	dirs := make([]string, 0, len(byDir))
+	for dir := range byDir {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+
+	groups := make([]promoteGroup, 0, len(dirs))
+	for _, dir := range dirs {
+		groups = append(groups, promoteGroup{scope: dir, paths: byDir[dir]})
+	}
+	return groups
+}
+
+// promoteTree returns the tree of a group's commit: the chain's own tree when
+// it is squashed, otherwise the parent's tree with the group's paths updated
+func promoteTree(ctx context.Context, p *promotion, parent string, group promoteGroup) (string, error) {
+	if group.paths == nil {
+		return gitexec.TreeOf(ctx, p.repo.Path, p.tip)
+	}
+	return gitexec.OverlayTree(ctx, p.repo.Path, parent, p.tip, group.paths)
+}
+
+// promoteCommitMessage writes the message of one commit of the new branch
+// from its diff and the WIP subjects, falling back to a template
+func promoteCommitMessage(ctx context.Context, p *promotion, group promoteGroup, generator ai.Generator, useAI bool) (string, *ai.Attribution) {
+	fallback := promoteFallbackMessage(p, group)
+	if !useAI {
+		return fallback, nil
+	}
+
+	input := ai.CommitMsgInput{
+		Repo:          p.repo.Name,
+		Branch:        p.branch,
+		Host:          hostName,
+		PriorSubjects: lastSubjects(p.wips, promoteSubjectLimit),
+	}
+	if nameStatus, err := gitexec.DiffNameStatus(ctx, p.repo.Path, p.base, p.tip, group.paths...); err == nil {
+		input.NameStatus = nameStatus
+	}
+	if diffStat, err := gitexec.DiffStat(ctx, p.repo.Path, p.base, p.tip, group.paths...); err == nil {
+		input.DiffStat = diffStat
+	}
+	if budget := ai.LoadConfigFromEnv().DiffBudget(); budget > 0 {
+		if diff, err := gitexec.Diff(ctx, p.repo.Path, p.base, p.tip, group.paths...); err == nil && diff != "" {
+			input.Diff, input.DiffOmitted = ai.SelectDiff(diff, budget)
+		}
+	}
+
+	ctx, attribution := ai.WithAttribution(ctx)
+	message, err := generator.CommitMessage(ctx, input)
+	if errors.Is(err, ai.ErrPromptShown) {
+		return fallback, nil
+	}
+	if err != nil || message == "" {
+		if err != nil {
+			ui.Warning(fmt.Sprintf("%s: AI commit message failed, using fallback: %v", p.repo.Name, err))
+		}
+		return fallback, attribution.Unused()
+	}
+	return message, attribution
+}
+
+// promoteFallbackMessage describes a commit without AI: the scope of a
+// regrouped commit, or the WIP subjects a squashed chain is made of
+func promoteFallbackMessage(p *promotion, group promoteGroup) string {
+	if group.paths != nil {
+		scope := group.scope
+		if scope == "." {
+			scope = "root"
+		}
+		files := "files"
+		if len(group.paths) == 1 {
+			files = "file"
+		}
+		return fmt.Sprintf("chore(%s): update %d %s", scope, len(group.paths), files)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("chore: promote %d WIP commits from %s", len(p.wips), p.from))
+	if len(p.wips) > 0 {
+		sb.WriteString("\n\n")
+		for _, subject := range lastSubjects(p.wips, promoteSubjectLimit) {
+			sb.WriteString("- " + subject + "\n")
+		}
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+// promoteDescription drafts the pull request description of the new branch
+func promoteDescription(ctx context.Context, p *promotion, generator ai.Generator, useAI bool) (string, *ai.Attribution) {
+	input := ai.PRReviewInput{
+		Repo:   p.repo.Name,
+		Branch: p.branch,
+	}
+	for _, group := range p.groups {
+		input.CommitMsgs = append(input.CommitMsgs, group.message)
+	}
+	if nameStatus, err := gitexec.DiffNameStatus(ctx, p.repo.Path, p.base, p.tip); err == nil {
+		input.NameStatus = nameStatus
+	}
+	if diffStat, err := gitexec.DiffStat(ctx, p.repo.Path, p.base, p.tip); err == nil {
+		input.DiffStat = diffStat
+	}
+	if added, removed, files, err := gitexec.DiffLineCounts(ctx, p.repo.Path, p.base, p.tip); err == nil {
+		input.LinesAdded, input.LinesRemoved, input.FilesCount = added, removed, files
+	}
+
+	fallback := fallbackPromoteDescription(p, input)
+	if !useAI {
+		return fallback, nil
+	}
+
+	ctx, attribution := ai.WithAttribution(ctx)
+	description, err := generator.PRDescription(ctx, input)
+	if errors.Is(err, ai.ErrPromptShown) {
+		return fallback, nil
+	}
+	if err != nil || strings.TrimSpace(description) == "" {
+		if err != nil {
+			ui.Warning(fmt.Sprintf("%s: AI PR description failed, using fallback: %v", p.repo.Name, err))
+		}
+		return fallback, attribution.Unused()
+	}
+	return strings.TrimSpace(description), attribution
+}
+
+// fallbackPromoteDescription lists the commits and files of the branch
+func fallbackPromoteDescription(p *promotion, input ai.PRReviewInput) string {
+	var sb strings.Builder
+	sb.WriteString("## Summary\n\n")
+	sb.WriteString(fmt.Sprintf("Promotes %d WIP commits from `%s` onto `%s`: %d files, +%d/-%d lines.\n\n",
+		len(p.wips), p.from, p.onto, input.FilesCount, input.LinesAdded, input.LinesRemoved))
+	sb.WriteString("## Changes\n\n")
+	for _, message := range input.CommitMsgs {
+		subject, _, _ := strings.Cut(message, "\n")
+		sb.WriteString("- " + subject + "\n")
+	}
+	if input.DiffStat != "" {
+		sb.WriteString("\n## Files\n\n```\n" + input.DiffStat + "\n```\n")
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+// savePromoteDescription writes the PR description next to the reports
+func savePromoteDescription(repoName, branch, description string) (string, error) {
+	if err := os.MkdirAll(reportDir, 0755); err != nil {
+		return "", err
+	}
+	file := filepath.Join(reportDir, fmt.Sprintf("pr-%s-%s.md", repoName, strings.ReplaceAll(branch, "/", "-")))
+	return file, os.WriteFile(file, []byte(description+"\n"), 0644)
+}
+
+// displayPromotion shows the commits of the new branch and its PR description
+func displayPromotion(p *promotion, description string) {
+	ui.InitTable("Commit", "Files", "Message")
+	for _, group := range p.groups {
+		commit := group.commit
+		if len(commit) > 8 {
+			commit = commit[:8]
+		}
+		files := "all"
+		if group.paths != nil {
+			files = fmt.Sprintf("%d", len(group.paths))
+		}
+		subject, _, _ := strings.Cut(group.message, "\n")
+		ui.AddTableRow(commit, files, subject)
+	}
+	ui.RenderTable()
+
+	ui.Info(fmt.Sprintf("📝 PR description for %s:", p.branch))
+	fmt.Println(description)
+	fmt.Println()
+}
+
+// confirmPromoteCleanup lists the WIP refs of the promoted chains and
+// deletes them only when the user agrees
+func confirmPromoteCleanup(ctx context.Context, promoted []*promotion, branch string) {
+	total := 0
+	ui.InitTable("Repository", "WIP Ref", "Commit")
+	for _, p := range promoted {
+		for _, ref := range p.refs {
+			ui.AddTableRow(ui.CyberText(p.repo.Name, "repo"), ui.CyberText(displayRefName(ref), "branch"), ref.Commit[:8])
+			total++
+		}
+	}
+	if total == 0 {
+		return
+	}
+	ui.RenderTable()
+
+	later := fmt.Sprintf("WIP refs kept - delete them later with 'wipctl promote %s --cleanup'", branch)
+	if gitexec.IsDryRun(ctx) {
+		ui.Info(later)
+		return
+	}
+	if !ui.Confirm(fmt.Sprintf("Delete these %d WIP refs now that they are promoted?", total)) {
+		ui.Info(later)
+		return
+	}
+
+	for _, p := range promoted {
+		entry := deletePromotedRefs(ctx, p.repo, branch, p.refs)
+		reportPromoteCleanup(entry)
+	}
+}
+
+// runPromoteCleanup deletes the WIP refs of branches promoted earlier
+func runPromoteCleanup(ctx context.Context, repos []workspace.Repo, branch string) error {
+	rep := report.NewReport("WIP Promote Cleanup Report", workspacePath, reportDir, "promote")
+
+	for _, repo := range repos {
+		recorded := gitexec.ConfigValue(ctx, repo.Path, promotedKey(branch))
+		base, tip, ok := strings.Cut(recorded, "..")
+		if !ok {
+			continue
+		}
+
+		remote := wipConfig.WipRemote.RemoteName()
+		if err := gitexec.FetchRemote(ctx, repo.Path, remote); err != nil {
+			slog.Warn("Fetch before cleanup failed, using known WIP refs", "repo", repo.Path, "remote", remote, "error", err)
+		}
+		refs, err := promoteWipRefs(ctx, repo.Path, remote)
+		if err != nil {
+			entry := report.ReportEntry{Repo: repo.Name, Outcome: "error"}
+			entry.AddError(fmt.Sprintf("list WIP refs: %v", err))
+			rep.AddEntry(entry)
+			reportPromoteCleanup(entry)
+			continue
+		}
+
+		entry := deletePromotedRefs(ctx, repo, branch, chainWipRefs(ctx, repo.Path, refs, base, tip))
+		rep.AddEntry(entry)
+		reportPromoteCleanup(entry)
+	}
+
+	if len(rep.Entries) == 0 {
+		ui.Warning(fmt.Sprintf("No promotion of %s recorded", branch))
+		return nil
+	}
+	if err := rep.Save(); err != nil {
+		ui.Warning("Failed to save report: " + err.Error())
+	}
+	return nil
+}
+
+// deletePromotedRefs deletes WIP refs, locally and on the WIP remote. The
+// record of the promotion is kept until every ref is gone, so an offline
+// cleanup can be repeated.
+func deletePromotedRefs(ctx context.Context, repo workspace.Repo, branch string, refs []wipRef) report.ReportEntry {
+	entry := report.ReportEntry{Repo: repo.Name}
+	remote := wipConfig.WipRemote.RemoteName()
+	current, _ := gitexec.CurrentBranch(ctx, repo.Path)
+
+	deleted, kept := 0, 0
+	for _, ref := range refs {
+		var err error
+		switch {
+		case !strings.HasPrefix(ref.Name, "refs/heads/"):
+			err = gitexec.PushDelete(ctx, repo.Path, remote, ref.Branch, ref.Commit)
+		case ref.Branch == current:
+			entry.AddWarning(fmt.Sprintf("%s is checked out - not deleted", ref.Branch))
+			kept++
+			continue
+		default:
+			err = gitexec.DeleteRef(ctx, repo.Path, ref.Name, ref.Commit)
+		}
+		if err != nil {
+			entry.AddWarning(fmt.Sprintf("failed to delete %s: %v", displayRefName(ref), err))
+			kept++
+			continue
+		}
+		deleted++
+	}
+
+	if kept == 0 {
+		if err := gitexec.SetConfig(ctx, repo.Path, promotedKey(branch), ""); err != nil {
+			entry.AddWarning(fmt.Sprintf("failed to clear the promotion record: %v", err))
+		}
+	}
+
+	entry.Outcome = "success"
+	entry.Details = fmt.Sprintf("deleted %d WIP refs promoted to %s", deleted, branch)
+	if kept > 0 {
+		entry.Details += fmt.Sprintf(", %d kept", kept)
+	}
+	return entry
+}
+
+// reportPromoteCleanup prints the outcome of a cleanup
+func reportPromoteCleanup(entry report.ReportEntry) {
+	for _, warning := range entry.Warnings {
+		ui.Warning(fmt.Sprintf("%s: %s", entry.Repo, warning))
+	}
+	for _, err := range entry.Errors {
+		ui.Error(fmt.Sprintf("%s: %s", entry.Repo, err))
+	}
+	if entry.Outcome == "success" {
+		ui.Success(fmt.Sprintf("%s: %s", entry.Repo, entry.Details))
+	}
+}
+
+// displayRefName shows a WIP ref as its branch, with the remote for remote refs
+func displayRefName(ref wipRef) string {
+	if strings.HasPrefix(ref.Name, "refs/heads/") {
+		return ref.Branch
+	}
+	return strings.TrimPrefix(ref.Name, "refs/remotes/")
+}
+
+// promotedKey is the config key recording a branch's promotion
+func promotedKey(branch string) string {
+	return "branch." + branch + "." + promotedConfigKey
+}
+
+// lastSubjects returns at most n subjects, the newest ones
+func lastSubjects(subjects []string, n int) []string {
+	if len(subjects) <= n {
+		return subjects
+	}
+	return subjects[len(subjects)-n:]
+}
@@ -0,0 +1,214 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/ai"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/gitexec"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/workspace"
+)
+
+const promoteWip = "wip/laptop/20260101-120000"
+
+// setupPromote clones origin with a base layout on main and a two-commit WIP
+// chain that renames a file across directories and swaps a file and a
+// directory. It returns the clone and the first WIP commit.
+func setupPromote(t *testing.T) (root, origin, repo, first string) {
+	t.Helper()
+	root, origin = setupOrigin(t)
+	oldOnto, oldFeature, oldFrom := promoteOnto, promoteFeature, promoteFrom
+	oldRegroup, oldPush, oldCleanup := promoteRegroup, promotePush, promoteCleanup
+	t.Cleanup(func() {
+		promoteOnto, promoteFeature, promoteFrom = oldOnto, oldFeature, oldFrom
+		promoteRegroup, promotePush, promoteCleanup = oldRegroup, oldPush, oldCleanup
+	})
+
+	repo = clone(t, root, origin, "api")
+	for _, dir := range []string{"src", "docs"} {
+		if err := os.MkdirAll(filepath.Join(repo, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile(t, filepath.Join(repo, "src", "old.go"), "package src\n")
+	writeFile(t, filepath.Join(repo, "lib"), "lib is a file\n")
+	writeFile(t, filepath.Join(repo, "docs", "a.md"), "docs is a directory\n")
+	git(t, repo, "add", "-A")
+	git(t, repo, "commit", "-q", "-m", "layout")
+	git(t, repo, "push", "-q", "origin", "main")
+
+	git(t, repo, "switch", "-q", "-c", promoteWip)
+	git(t, repo, "mv", "src", "pkg")
+	writeFile(t, filepath.Join(repo, "README.md"), "hello\nwip\n")
+	git(t, repo, "add", "-A")
+	git(t, repo, "commit", "-q", "-m", "wip: move src")
+	first = git(t, repo, "rev-parse", "HEAD")
+
+	git(t, repo, "rm", "-q", "lib", "docs/a.md")
+	if err := os.MkdirAll(filepath.Join(repo, "lib"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(repo, "lib", "util.go"), "package lib\n")
+	writeFile(t, filepath.Join(repo, "docs"), "docs is a file\n")
+	git(t, repo, "add", "-A")
+	git(t, repo, "commit", "-q", "-m", "wip: swap lib and docs")
+	git(t, repo, "switch", "-q", "main")
+	return root, origin, repo, first
+}
+
+func TestPromoteRebuildsWipTree(t *testing.T) {
+	for _, regroup := range []bool{false, true} {
+		name := "squash"
+		if regroup {
+			name = "regroup"
+		}
+		t.Run(name, func(t *testing.T) {
+			_, _, repo, _ := setupPromote(t)
+			promoteRegroup = regroup
+			base := git(t, repo, "rev-parse", "main")
+			tip := git(t, repo, "rev-parse", promoteWip)
+
+			ctx := context.Background()
+			entry, p := promoteRepo(ctx, workspace.Repo{Name: "api", Path: repo}, "feature/parser", &ai.NoneGenerator{}, false)
+			if entry.Outcome != "success" || p == nil {
+				t.Fatalf("outcome = %q, want success (errors: %v)", entry.Outcome, entry.Errors)
+			}
+
+			if got, want := git(t, repo, "rev-parse", "feature/parser^{tree}"), git(t, repo, "rev-parse", promoteWip+"^{tree}"); got != want {
+				t.Errorf("feature tree = %s, want the WIP tip's %s", got, want)
+			}
+			commits := strings.Fields(git(t, repo, "rev-list", "--reverse", "main..feature/parser"))
+			if len(commits) != len(p.groups) {
+				t.Fatalf("commits = %d, want one per group (%d)", len(commits), len(p.groups))
+			}
+			if regroup {
+				var scopes []string
+				for _, group := range p.groups {
+					scopes = append(scopes, group.scope)
+				}
+				// docs and lib swap between file and directory, so their
+				// paths stay in the root group
+				if got := strings.Join(scopes, ","); got != ".,pkg,src" {
+					t.Errorf("groups = %s, want one per directory", got)
+				}
+				// Each commit changes only its group's paths
+				for i, commit := range commits {
+					changed := strings.Fields(git(t, repo, "diff", "--name-only", "--no-renames", commit+"^", commit))
+					if strings.Join(changed, ",") != strings.Join(p.groups[i].paths, ",") {
+						t.Errorf("commit %d changed %v, want %v", i, changed, p.groups[i].paths)
+					}
+				}
+			} else if len(commits) != 1 {
+				t.Errorf("commits = %d, want one squashed commit", len(commits))
+			}
+
+			if got := git(t, repo, "config", "--get", promotedKey("feature/parser")); got != base+".."+tip {
+				t.Errorf("promotion record = %q, want %s..%s", got, base, tip)
+			}
+			if got := git(t, repo, "branch", "--show-current"); got != "main" {
+				t.Errorf("current branch = %q, want main kept", got)
+			}
+			if got := git(t, repo, "status", "--porcelain"); got != "" {
+				t.Errorf("worktree = %q, want it untouched", got)
+			}
+			if _, err := os.Stat(filepath.Join(reportDir, "pr-api-feature-parser.md")); err != nil {
+				t.Errorf("PR description not saved: %v", err)
+			}
+		})
+	}
+}
+
+func TestPromoteRefusesExistingBranch(t *testing.T) {
+	_, _, repo, _ := setupPromote(t)
+	git(t, repo, "branch", "feature/parser")
+	before := git(t, repo, "rev-parse", "feature/parser")
+
+	entry, p := promoteRepo(context.Background(), workspace.Repo{Name: "api", Path: repo}, "feature/parser", &ai.NoneGenerator{}, false)
+	if entry.Outcome != "error" || p != nil {
+		t.Fatalf("outcome = %q, want error", entry.Outcome)
+	}
+	if len(entry.Errors) != 1 || !strings.Contains(entry.Errors[0], "already exists") {
+		t.Errorf("errors = %v, want already exists", entry.Errors)
+	}
+	if got := git(t, repo, "rev-parse", "feature/parser"); got != before {
+		t.Errorf("feature/parser moved to %s", got)
+	}
+	if got := gitexec.ConfigValue(context.Background(), repo, promotedKey("feature/parser")); got != "" {
+		t.Errorf("promotion recorded: %q", got)
+	}
+}
+
+// refExists reports whether ref resolves to a commit in repo
+func refExists(repo, ref string) bool {
+	return gitexec.ResolveRef(context.Background(), repo, ref) != ""
+}
+
+func TestPromoteCleanupDeletesChainRefs(t *testing.T) {
+	_, origin, repo, first := setupPromote(t)
+	const (
+		inChain  = "wip/laptop/20260101-110000"
+		onBase   = "wip/laptop/20251231-120000"
+		offChain = "wip/desktop/20260102-090000"
+	)
+	ctx := context.Background()
+	apiRepo := workspace.Repo{Name: "api", Path: repo}
+	if entry, _ := promoteRepo(ctx, apiRepo, "feature/parser", &ai.NoneGenerator{}, false); entry.Outcome != "success" {
+		t.Fatalf("promote outcome = %q (errors: %v)", entry.Outcome, entry.Errors)
+	}
+
+	// Refs made after the promotion: only those in the recorded range go
+	git(t, repo, "branch", inChain, first)
+	git(t, repo, "branch", onBase, "main")
+	git(t, repo, "switch", "-q", "-c", offChain)
+	writeFile(t, filepath.Join(repo, "other.txt"), "other\n")
+	git(t, repo, "add", "-A")
+	git(t, repo, "commit", "-q", "-m", "wip: elsewhere")
+	git(t, repo, "switch", "-q", "main")
+	git(t, repo, "push", "-q", "origin", promoteWip, offChain)
+
+	promoteCleanup = true
+	if err := runPromoteCleanup(ctx, []workspace.Repo{apiRepo}, "feature/parser"); err != nil {
+		t.Fatalf("cleanup: %v", err)
+	}
+
+	for _, ref := range []string{"refs/heads/" + promoteWip, "refs/heads/" + inChain, "refs/remotes/origin/" + promoteWip} {
+		if refExists(repo, ref) {
+			t.Errorf("%s kept, want it deleted", ref)
+		}
+	}
+	for _, ref := range []string{"refs/heads/" + onBase, "refs/heads/" + offChain, "refs/remotes/origin/" + offChain, "refs/heads/feature/parser"} {
+		if !refExists(repo, ref) {
+			t.Errorf("%s deleted, want it kept", ref)
+		}
+	}
+	if got := git(t, origin, "for-each-ref", "--format=%(refname)", "refs/heads/wip/"); got != "refs/heads/"+offChain {
+		t.Errorf("origin WIP refs = %q, want only %s", got, offChain)
+	}
+	if got := gitexec.ConfigValue(ctx, repo, promotedKey("feature/parser")); got != "" {
+		t.Errorf("promotion record = %q, want it unset", got)
+	}
+}
+
+func TestGroupByDirectory(t *testing.T) {
+	tests := []struct {
+		paths []string
+		want  string
+	}{
+		{paths: []string{"README.md", "cmd/a.go", "cmd/b.go", "internal/x/y.go"}, want: ".:README.md cmd:cmd/a.go,cmd/b.go internal/x:internal/x/y.go"},
+		{paths: []string{"lib", "lib/util.go"}, want: ".:lib,lib/util.go"},
+		{paths: []string{"pkg/docs", "pkg/docs/a/b.md", "pkg/other.go"}, want: "pkg:pkg/docs,pkg/docs/a/b.md,pkg/other.go"},
+	}
+
+	for _, tt := range tests {
+		var got []string
+		for _, group := range groupByDirectory(tt.paths) {
+			got = append(got, group.scope+":"+strings.Join(group.paths, ","))
+		}
+		if strings.Join(got, " ") != tt.want {
+			t.Errorf("groupByDirectory(%v) = %s, want %s", tt.paths, strings.Join(got, " "), tt.want)
+		}
+	}
+}
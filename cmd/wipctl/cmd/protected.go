@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/gitexec"
+)
+
+// isProtectedBranch reports whether wipctl must leave branch alone
+func isProtectedBranch(branch string) bool {
+	return wipConfig.IsProtected(branch)
+}
+
+// protectedSkipReason explains a skipped commit or push for reports
+func protectedSkipReason(action, branch string) string {
+	return fmt.Sprintf("skipped %s protected branch %s (protected_branches) - only WIP refs are updated", action, branch)
+}
+
+// commitSnapshot commits the staged tree on top of HEAD straight into
+// refs/heads/<wipBranch>, without moving HEAD or the checked-out branch.
+// The index is reset afterwards so the working tree is left as it was.
+func commitSnapshot(ctx context.Context, repoPath, wipBranch, message string) (string, error) {
+	head := gitexec.ResolveRef(ctx, repoPath, "HEAD")
+	if head == "" {
+		return "", fmt.Errorf("repository has no commits")
+	}
+
+	tree, err := gitexec.WriteTree(ctx, repoPath)
+	if err != nil {
+		return "", fmt.Errorf("write tree: %w", err)
+	}
+
+	commit, err := gitexec.CommitTree(ctx, repoPath, tree, message, head)
+	if err != nil {
+		return "", fmt.Errorf("commit-tree: %w", err)
+	}
+
+	if err := gitexec.UpdateRef(ctx, repoPath, "refs/heads/"+wipBranch, commit, ""); err != nil {
+		return "", fmt.Errorf("create %s: %w", wipBranch, err)
+	}
+
+	if err := gitexec.ResetIndex(ctx, repoPath); err != nil {
+		slog.Warn("Failed to unstage after snapshot commit", "repo", repoPath, "error", err)
+	}
+
+	return commit, nil
+}
@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/ai"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/config"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/gitexec"
+)
+
+func TestIsProtectedBranch(t *testing.T) {
+	oldConfig := wipConfig
+	t.Cleanup(func() { wipConfig = oldConfig })
+
+	tests := []struct {
+		name      string
+		protected []string
+		branch    string
+		want      bool
+	}{
+		{name: "default main", branch: "main", want: true},
+		{name: "default master", branch: "master", want: true},
+		{name: "default release glob", branch: "release/1.2", want: true},
+		{name: "glob stops at slash", branch: "release/1/x", want: false},
+		{name: "feature branch", branch: "feature/login", want: false},
+		{name: "WIP branch", branch: "wip/laptop/20260101-120000", want: false},
+		{name: "configured glob", protected: []string{"prod-*"}, branch: "prod-eu", want: true},
+		{name: "configured list replaces defaults", protected: []string{"prod-*"}, branch: "main", want: false},
+		{name: "empty list protects nothing", protected: []string{}, branch: "main", want: false},
+		{name: "invalid glob still matches exactly", protected: []string{"[main"}, branch: "[main", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wipConfig = &config.Config{ProtectedBranches: tt.protected}
+			if got := isProtectedBranch(tt.branch); got != tt.want {
+				t.Errorf("isProtectedBranch(%q) with %v = %v, want %v", tt.branch, tt.protected, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPushRejectsProtectedPrefix(t *testing.T) {
+	root, origin := setupOrigin(t)
+	repo := clone(t, root, origin, "api")
+	writeFile(t, filepath.Join(repo, "notes.txt"), "todo\n")
+	workspacePath = root
+	oldPrefix := wipPrefix
+	t.Cleanup(func() { wipPrefix = oldPrefix })
+	before := git(t, origin, "rev-parse", "main")
+
+	wipPrefix = "main"
+	if err := runPush(pushCmd, nil); err == nil {
+		t.Fatal("push --prefix main was not rejected")
+	}
+	if got := git(t, origin, "rev-parse", "main"); got != before {
+		t.Errorf("origin main moved to %s", got)
+	}
+	if got := git(t, repo, "rev-list", "--count", "HEAD"); got != "1" {
+		t.Errorf("main has %s commits, want only the initial one", got)
+	}
+}
+
+func TestCheckpointOnProtectedBranchWritesOnlyWipRef(t *testing.T) {
+	root, origin := setupOrigin(t)
+	repo := clone(t, root, origin, "api")
+	writeFile(t, filepath.Join(repo, "notes.txt"), "todo\n")
+	workspacePath = root
+	head := git(t, repo, "rev-parse", "HEAD")
+
+	ctx := context.Background()
+	status, err := gitexec.Status(ctx, repo)
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	results := map[string]*gitexec.RepoStatus{repo: status}
+	rep, err := checkpointRepositories(ctx, "Checkpoint", []string{repo}, results, 1, &ai.NoneGenerator{})
+	if err != nil {
+		t.Fatalf("checkpoint: %v", err)
+	}
+	if len(rep.Entries) != 1 || rep.Entries[0].Outcome != "success" {
+		t.Fatalf("entries = %+v, want one success", rep.Entries)
+	}
+	wipBranch := rep.Entries[0].WipBranch
+
+	if got := git(t, repo, "branch", "--show-current"); got != "main" {
+		t.Errorf("current branch = %q, want main left checked out", got)
+	}
+	if got := git(t, repo, "rev-parse", "main"); got != head {
+		t.Errorf("local main moved to %s", got)
+	}
+	if got := git(t, origin, "rev-parse", "main"); got != head {
+		t.Errorf("origin main moved to %s", got)
+	}
+	if got := git(t, origin, "rev-parse", wipBranch+"^"); got != head {
+		t.Errorf("WIP commit parent = %s, want main %s", got, head)
+	}
+	if got := git(t, origin, "show", wipBranch+":notes.txt"); got != "todo" {
+		t.Errorf("notes.txt on %s = %q, want the snapshot", wipBranch, got)
+	}
+	if got := git(t, repo, "status", "--porcelain"); got != "?? notes.txt" {
+		t.Errorf("worktree = %q, want the change left unstaged", got)
+	}
+}
@@ -4,37 +4,61 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"strings"
 	"sync"
 
 	"github.com/spf13/cobra"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/bundle"
 	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/gitexec"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/hooks"
 	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/report"
 	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/ui"
 	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/workspace"
 )
 
-var pullConcurrency int
+var (
+	pullConcurrency int
+	pullFromBundle  bool
+	pullCheck       bool
+	pullForce       bool
+)
+
+// pullStashConfigKey records the auto-stash a pull could not pop cleanly,
+// so 'wipctl resolve' drops exactly that stash once its conflicts are fixed
+const pullStashConfigKey = "wipctl.pullStash"
 
 var pullCmd = &cobra.Command{
 	Use:   "pull",
-	Short: "Pull latest WIP branches from origin across all repositories",
-	Long: `Pull the latest WIP branches from origin across all repositories safely.
+	Short: "Pull latest WIP branches from the WIP remote across all repositories",
+	Long: `Pull the latest WIP branches across all repositories safely.
 
 For each repository:
 1. Check preconditions (has origin, not in rebase/merge)
-2. Fetch from origin
-3. Find the newest WIP branch by commit date
+2. Fetch from the WIP remote (origin unless wip_remote is configured),
+   or use refs imported by 'wipctl bundle import' with --from-bundle
+3. Find the newest WIP branch by commit date and, when a trusted keyring
+   is configured, verify it was signed by a trusted host
 4. Stash any local changes
 5. Switch to (or create) local WIP branch tracking the remote
 6. Pop stashed changes and detect conflicts
 
-If conflicts occur, they are reported but not automatically resolved.`,
+Before stashing, pull predicts the result in memory (git stash create plus
+git merge-tree --write-tree, the worktree is never touched) and skips
+repositories whose local changes or current branch would conflict with the
+WIP ref, unless --force is given. --check only prints the prediction:
+clean, conflicts (with files) or diverged.
+
+If conflicts occur, they are reported but not automatically resolved.
+Run 'wipctl resolve' to work through them interactively.`,
 	RunE: runPull,
 }
 
 func init() {
 	rootCmd.AddCommand(pullCmd)
 	pullCmd.Flags().IntVar(&pullConcurrency, "concurrency", 6, "number of concurrent repository operations")
+	pullCmd.Flags().BoolVar(&pullFromBundle, "from-bundle", false, "use WIP branches imported by 'wipctl bundle import' instead of fetching")
+	pullCmd.Flags().BoolVar(&pullCheck, "check", false, "only predict per repo whether pulling would be clean, conflict or diverge")
+	pullCmd.Flags().BoolVar(&pullForce, "force", false, "pull repositories even when conflicts are predicted")
 }
 
 func runPull(cmd *cobra.Command, args []string) error {
@@ -56,10 +80,23 @@ func runPull(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
+	if err := prepareVerification(); err != nil {
+		ui.Error("Failed to prepare signature verification: " + err.Error())
+		return err
+	}
+
+	if pullCheck {
+		return runPullCheck(ctx, repos)
+	}
+
 	ui.Info(fmt.Sprintf("Pulling WIP branches for %d repositories", len(repos)))
 
 	rep := report.NewReport("WIP Pull Report", workspacePath, reportDir, "pull")
 
+	if err := runOperationHook(ctx, hooks.Pre("pull"), "pull", ""); err != nil {
+		return err
+	}
+
 	var mu sync.Mutex
 	var wg sync.WaitGroup
 	semaphore := make(chan struct{}, pullConcurrency)
@@ -86,13 +123,70 @@ func runPull(cmd *cobra.Command, args []string) error {
 		ui.Warning("Failed to save report: " + err.Error())
 	}
 
+	runOperationHook(ctx, hooks.Post("pull"), "pull", operationOutcome(rep.Entries)) //nolint:errcheck // post hook failures are reported, not fatal
+
+	if !pullFromBundle {
+		autoFlushPushQueue(ctx, rep.Entries)
+	}
+
 	ui.Success("Pull operation completed. Report saved.")
 	return nil
 }
 
+// runPullCheck predicts every repository's pull without changing anything
+// but remote-tracking refs, and prints the prediction table
+func runPullCheck(ctx context.Context, repos []workspace.Repo) error {
+	ui.Info(fmt.Sprintf("Predicting WIP pulls for %d repositories", len(repos)))
+
+	rep := report.NewReport("WIP Pull Check Report", workspacePath, reportDir, "pull-check")
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, pullConcurrency)
+
+	for _, repo := range repos {
+		wg.Add(1)
+		go func(repo workspace.Repo) {
+			defer wg.Done()
+
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			entry := pullRepo(ctx, repo, report.CreatePullEntry(repo.Name, "", "", ""))
+
+			mu.Lock()
+			rep.AddEntry(entry)
+			mu.Unlock()
+		}(repo)
+	}
+
+	wg.Wait()
+
+	displayPullCheckTable(rep.Entries)
+
+	if err := rep.Save(); err != nil {
+		ui.Warning("Failed to save report: " + err.Error())
+	}
+	return nil
+}
+
 func processRepoPull(ctx context.Context, repo workspace.Repo) report.ReportEntry {
 	entry := report.CreatePullEntry(repo.Name, "", "", "")
+	event := repoHookEvent(ctx, "pull", repo.Path, "")
+
+	if !skipOnPreRepoHook(ctx, &entry, event) {
+		entry = pullRepo(ctx, repo, entry)
+	}
 
+	// After a pull the repo sits on the WIP branch it switched to
+	if entry.Outcome == "success" || entry.Outcome == "conflicts" {
+		event.WipBranch, _ = gitexec.CurrentBranch(ctx, repo.Path)
+	}
+	finishRepoHook(ctx, &entry, event)
+	return entry
+}
+
+func pullRepo(ctx context.Context, repo workspace.Repo, entry report.ReportEntry) report.ReportEntry {
 	slog.Info("Processing repository", "repo", repo.Path)
 
 	ok, reason := gitexec.Preconditions(ctx, repo.Path)
@@ -112,46 +206,94 @@ func processRepoPull(ctx context.Context, repo workspace.Repo) report.ReportEntr
 
 	originalBranch := status.Branch
 
-	if err := gitexec.Fetch(ctx, repo.Path); err != nil {
-		entry.Outcome = "error"
-		entry.AddError(fmt.Sprintf("fetch failed: %v", err))
-		ui.Error(fmt.Sprintf("%s: fetch failed", repo.Name))
-		return entry
+	wipRemote := bundle.RemoteName
+	if !pullFromBundle {
+		wipRemote, err = ensureWipRemote(ctx, repo.Path, repo.Name)
+		if err != nil {
+			entry.Outcome = "error"
+			entry.AddError(fmt.Sprintf("WIP remote setup failed: %v", err))
+			ui.Error(fmt.Sprintf("%s: WIP remote setup failed", repo.Name))
+			return entry
+		}
+
+		if err := gitexec.FetchRemote(ctx, repo.Path, wipRemote); err != nil {
+			entry.Outcome = "error"
+			entry.AddError(fmt.Sprintf("fetch failed: %v", err))
+			ui.Error(fmt.Sprintf("%s: fetch failed", repo.Name))
+			return entry
+		}
 	}
 
-	latestWipRemote, err := gitexec.LatestRemoteWIP(ctx, repo.Path)
+	latest, err := latestWipRef(ctx, repo.Path, wipRemote)
 	if err != nil {
 		entry.Outcome = "no-wip"
-		entry.AddWarning("no WIP branches found on origin")
+		entry.AddWarning(fmt.Sprintf("no WIP branches found on %s", wipRemote))
 		ui.Info(fmt.Sprintf("%s: no WIP branches found", repo.Name))
 		return entry
 	}
 
-	wipBranchName := gitexec.TrimOrigin(latestWipRemote)
+	latestWipRemote := latest.Name
+	wipBranchName := latest.Branch
 	entry.Details = fmt.Sprintf("%s → %s", originalBranch, wipBranchName)
 
-	stashMessage := fmt.Sprintf("wipctl auto-stash before pull - %s", wipBranchName)
-	if err := gitexec.Stash(ctx, repo.Path, stashMessage); err != nil {
-		slog.Debug("Stash failed (may be nothing to stash)", "repo", repo.Path, "error", err)
+	if !verifyWipRef(ctx, repo, latestWipRemote, wipBranchName, &entry) {
+		return entry
 	}
 
-	if err := gitexec.SwitchCreate(ctx, repo.Path, wipBranchName); err != nil {
+	prediction, err := predictPull(ctx, repo.Path, latestWipRemote)
+	switch {
+	case err != nil && pullCheck:
 		entry.Outcome = "error"
-		entry.AddError(fmt.Sprintf("switch to WIP branch failed: %v", err))
+		entry.AddError(fmt.Sprintf("conflict prediction failed: %v", err))
 		return entry
+	case err != nil:
+		entry.AddWarning(fmt.Sprintf("conflict prediction failed: %v", err))
+	case pullCheck:
+		entry.Outcome = prediction.State
+		if note := describePrediction(prediction); note != "" {
+			entry.AddWarning(note)
+		}
+		return entry
+	case prediction.State == pullConflicts && !pullForce:
+		entry.Outcome = "skipped"
+		entry.AddWarning(fmt.Sprintf("predicted conflicts in %s - not pulling (use --force to pull anyway)", strings.Join(prediction.Files, ", ")))
+		ui.Warning(fmt.Sprintf("%s: pull would conflict, skipped (see 'wipctl pull --check')", repo.Name))
+		return entry
+	case prediction.State == pullConflicts:
+		entry.AddWarning(fmt.Sprintf("predicted conflicts in %s - pulling anyway (--force)", strings.Join(prediction.Files, ", ")))
+	case prediction.State == pullDiverged:
+		entry.AddWarning(fmt.Sprintf("%s and %s have diverged - local commits stay on %s", originalBranch, wipBranchName, originalBranch))
 	}
 
-	err = gitexec.Switch(ctx, repo.Path, wipBranchName)
+	stashMessage := fmt.Sprintf("wipctl auto-stash before pull - %s", wipBranchName)
+	stash, err := gitexec.StashPush(ctx, repo.Path, stashMessage)
 	if err != nil {
-		if createErr := createTrackingBranch(ctx, repo.Path, wipBranchName, latestWipRemote); createErr != nil {
-			entry.Outcome = "error"
-			entry.AddError(fmt.Sprintf("create tracking branch failed: %v", createErr))
-			return entry
-		}
+		entry.Outcome = "error"
+		entry.AddError(fmt.Sprintf("stash local changes failed: %v", err))
+		return entry
 	}
 
-	if err := gitexec.StashPop(ctx, repo.Path); err != nil {
-		slog.Debug("Stash pop failed (may be no stash)", "repo", repo.Path, "error", err)
+	// Bundle refs have no configured remote to track, so branch off them directly
+	if pullFromBundle {
+		err = gitexec.SwitchCreateAt(ctx, repo.Path, wipBranchName, latestWipRemote)
+	} else {
+		err = gitexec.SwitchTrack(ctx, repo.Path, wipBranchName, wipRemote+"/"+wipBranchName)
+	}
+	if err != nil {
+		entry.Outcome = "error"
+		entry.AddError(fmt.Sprintf("switch to WIP branch failed: %v", err))
+		return entry
+	}
+
+	// Only pop what this pull stashed; an older entry on top of the stack is
+	// never touched
+	if stash != "" {
+		if err := gitexec.StashPop(ctx, repo.Path, stash); err != nil {
+			slog.Debug("Stash pop failed", "repo", repo.Path, "stash", stash, "error", err)
+			if err := gitexec.SetConfig(ctx, repo.Path, pullStashConfigKey, stash); err != nil {
+				entry.AddWarning(fmt.Sprintf("failed to record auto-stash %s: %v", stash, err))
+			}
+		}
 	}
 
 	hasConflicts, conflictFiles, err := gitexec.HasConflicts(ctx, repo.Path)
@@ -162,7 +304,7 @@ func processRepoPull(ctx context.Context, repo workspace.Repo) report.ReportEntr
 	if hasConflicts {
 		entry.Outcome = "conflicts"
 		entry.AddWarning(fmt.Sprintf("conflicts in files: %v", conflictFiles))
-		ui.Warning(fmt.Sprintf("%s: conflicts detected, resolve and commit", repo.Name))
+		ui.Warning(fmt.Sprintf("%s: conflicts detected, run 'wipctl resolve'", repo.Name))
 		return entry
 	}
 
@@ -170,16 +312,3 @@ func processRepoPull(ctx context.Context, repo workspace.Repo) report.ReportEntr
 	ui.Success(fmt.Sprintf("%s: switched to WIP branch %s", repo.Name, wipBranchName))
 	return entry
 }
-
-func createTrackingBranch(ctx context.Context, repoPath, localBranch, remoteBranch string) error {
-	if err := gitexec.SwitchCreate(ctx, repoPath, localBranch); err != nil {
-		return fmt.Errorf("create local branch: %w", err)
-	}
-
-	remoteShort := gitexec.TrimOrigin(remoteBranch)
-
-	cmd := fmt.Sprintf("git branch --set-upstream-to=origin/%s %s", remoteShort, localBranch)
-	slog.Debug("Setting upstream", "repo", repoPath, "command", cmd)
-
-	return nil
-}
\ No newline at end of file
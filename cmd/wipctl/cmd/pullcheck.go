@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/gitexec"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/report"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/ui"
+)
+
+// Pull prediction states
+const (
+	pullClean     = "clean"
+	pullConflicts = "conflicts"
+	pullDiverged  = "diverged"
+)
+
+// pullPrediction is what pulling a WIP ref would do to a repository
+type pullPrediction struct {
+	State string
+	Files []string // conflicted paths
+	Notes []string
+}
+
+// predictPull works out, without touching the worktree or index, whether
+// switching to wipRef and re-applying local changes would conflict:
+//   - uncommitted tracked changes are captured with "git stash create" and
+//     merged onto wipRef over HEAD, as "git stash pop" would after the switch
+//   - untracked files conflict when wipRef has a file at the same path
+//   - the current branch is diverged when neither it nor wipRef contains the
+//     other, and conflicting when their trees would not merge cleanly
+func predictPull(ctx context.Context, repoPath, wipRef string) (pullPrediction, error) {
+	prediction := pullPrediction{State: pullClean}
+	conflicts := make(map[string]bool)
+
+	head := gitexec.ResolveRef(ctx, repoPath, "HEAD")
+	wip := gitexec.ResolveRef(ctx, repoPath, wipRef)
+	if head == "" || wip == "" {
+		return prediction, fmt.Errorf("cannot resolve HEAD or %s", wipRef)
+	}
+
+	stash, err := gitexec.StashCreate(ctx, repoPath)
+	if err != nil {
+		return prediction, fmt.Errorf("stash create: %w", err)
+	}
+	if stash != "" {
+		files, err := gitexec.MergeTree(ctx, repoPath, head, wip, stash)
+		if err != nil {
+			return prediction, err
+		}
+		for _, file := range files {
+			conflicts[file] = true
+		}
+		if len(files) > 0 {
+			prediction.Notes = append(prediction.Notes, "local changes conflict with the WIP ref")
+		}
+	}
+
+	untracked, _ := gitexec.ListUntracked(ctx, repoPath)
+	for _, file := range untracked {
+		if gitexec.PathExists(ctx, repoPath, wip, file) {
+			conflicts[file] = true
+			prediction.Notes = append(prediction.Notes, fmt.Sprintf("untracked %s exists in the WIP ref", file))
+		}
+	}
+
+	switch {
+	case head == wip || gitexec.IsAncestor(ctx, repoPath, head, wip):
+		// Fast-forward: the WIP ref already contains the current branch
+	case gitexec.IsAncestor(ctx, repoPath, wip, head):
+		prediction.Notes = append(prediction.Notes, "current branch already contains the WIP ref")
+	default:
+		prediction.State = pullDiverged
+		files, err := gitexec.MergeTree(ctx, repoPath, "", head, wip)
+		if err != nil {
+			return prediction, err
+		}
+		for _, file := range files {
+			conflicts[file] = true
+		}
+		if len(files) > 0 {
+			prediction.Notes = append(prediction.Notes, "current branch and WIP ref would not merge cleanly")
+		}
+	}
+
+	if len(conflicts) > 0 {
+		prediction.State = pullConflicts
+		for file := range conflicts {
+			prediction.Files = append(prediction.Files, file)
+		}
+		sort.Strings(prediction.Files)
+	}
+
+	return prediction, nil
+}
+
+// describePrediction summarizes a prediction for reports and tables
+func describePrediction(prediction pullPrediction) string {
+	if len(prediction.Files) > 0 {
+		return strings.Join(prediction.Files, ", ")
+	}
+	return strings.Join(prediction.Notes, "; ")
+}
+
+// displayPullCheckTable renders the outcome of 'wipctl pull --check'
+func displayPullCheckTable(entries []report.ReportEntry) {
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Repo < entries[j].Repo })
+
+	ui.InitTable("Repository", "WIP Ref", "Prediction", "Files / Notes")
+	for _, entry := range entries {
+		notes := strings.Join(append(append([]string{}, entry.Warnings...), entry.Errors...), "; ")
+		if notes == "" {
+			notes = "-"
+		}
+		ui.AddTableRow(
+			ui.CyberText(entry.Repo, "repo"),
+			ui.CyberText(entry.Details, "branch"),
+			predictionCell(entry.Outcome),
+			notes,
+		)
+	}
+	ui.RenderTable()
+}
+
+func predictionCell(state string) string {
+	switch state {
+	case pullClean:
+		return ui.StatusCell("clean")
+	case pullConflicts:
+		return ui.StatusCell("conflicts")
+	case pullDiverged:
+		return ui.StatusCell("diverged")
+	case "error":
+		return ui.StatusCell("error")
+	default:
+		return state
+	}
+}
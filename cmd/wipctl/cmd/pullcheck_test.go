@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/ai"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/workspace"
+)
+
+// commitOn commits content to file on branch, then checks out main again
+func commitOn(t *testing.T, repo, branch, file, content string) {
+	t.Helper()
+	git(t, repo, "switch", "-q", branch)
+	writeFile(t, filepath.Join(repo, file), content)
+	git(t, repo, "add", "-A")
+	git(t, repo, "commit", "-q", "-m", "change "+file)
+	git(t, repo, "switch", "-q", "main")
+}
+
+func TestPredictPull(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(t *testing.T, repo string)
+		state string
+		files []string
+	}{
+		{
+			name: "fast-forward",
+			setup: func(t *testing.T, repo string) {
+				commitOn(t, repo, "wip", "notes.txt", "todo\n")
+			},
+			state: pullClean,
+		},
+		{
+			name: "local change elsewhere",
+			setup: func(t *testing.T, repo string) {
+				commitOn(t, repo, "wip", "notes.txt", "todo\n")
+				writeFile(t, filepath.Join(repo, "README.md"), "hello\nlocal\n")
+			},
+			state: pullClean,
+		},
+		{
+			name: "local change on the same line",
+			setup: func(t *testing.T, repo string) {
+				commitOn(t, repo, "wip", "README.md", "hello from wip\n")
+				writeFile(t, filepath.Join(repo, "README.md"), "hello from here\n")
+			},
+			state: pullConflicts,
+			files: []string{"README.md"},
+		},
+		{
+			name: "untracked file in the way",
+			setup: func(t *testing.T, repo string) {
+				commitOn(t, repo, "wip", "notes.txt", "todo\n")
+				writeFile(t, filepath.Join(repo, "notes.txt"), "mine\n")
+			},
+			state: pullConflicts,
+			files: []string{"notes.txt"},
+		},
+		{
+			name: "diverged cleanly",
+			setup: func(t *testing.T, repo string) {
+				commitOn(t, repo, "wip", "notes.txt", "todo\n")
+				commitOn(t, repo, "main", "other.txt", "other\n")
+			},
+			state: pullDiverged,
+		},
+		{
+			name: "diverged with conflicts",
+			setup: func(t *testing.T, repo string) {
+				commitOn(t, repo, "wip", "README.md", "hello from wip\n")
+				commitOn(t, repo, "main", "README.md", "hello from main\n")
+			},
+			state: pullConflicts,
+			files: []string{"README.md"},
+		},
+		{
+			name: "current branch already ahead",
+			setup: func(t *testing.T, repo string) {
+				commitOn(t, repo, "main", "notes.txt", "todo\n")
+			},
+			state: pullClean,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, origin := setupOrigin(t)
+			repo := clone(t, root, origin, "api")
+			git(t, repo, "branch", "wip")
+			tt.setup(t, repo)
+			status := git(t, repo, "status", "--porcelain")
+
+			prediction, err := predictPull(context.Background(), repo, "refs/heads/wip")
+			if err != nil {
+				t.Fatalf("predictPull: %v", err)
+			}
+			if prediction.State != tt.state {
+				t.Errorf("state = %q, want %q (notes %v)", prediction.State, tt.state, prediction.Notes)
+			}
+			if strings.Join(prediction.Files, ",") != strings.Join(tt.files, ",") {
+				t.Errorf("files = %v, want %v", prediction.Files, tt.files)
+			}
+			if got := git(t, repo, "status", "--porcelain"); got != status {
+				t.Errorf("worktree changed from %q to %q", status, got)
+			}
+			if got := git(t, repo, "stash", "list"); got != "" {
+				t.Errorf("stash list = %q, want prediction to leave it alone", got)
+			}
+		})
+	}
+}
+
+func TestPullSkipsPredictedConflicts(t *testing.T) {
+	root, origin := setupOrigin(t)
+	oldCheck, oldForce := pullCheck, pullForce
+	t.Cleanup(func() { pullCheck, pullForce = oldCheck, oldForce })
+
+	laptop := clone(t, root, origin, "laptop")
+	writeFile(t, filepath.Join(laptop, "README.md"), "hello from laptop\n")
+	entry := processRepoPush(context.Background(), workspace.Repo{Name: "api", Path: laptop}, &ai.NoneGenerator{}, "wip/laptop/20260101-120000")
+	if entry.Outcome != "success" {
+		t.Fatalf("push outcome = %q, want success (errors: %v)", entry.Outcome, entry.Errors)
+	}
+
+	desktop := clone(t, root, origin, "desktop")
+	writeFile(t, filepath.Join(desktop, "README.md"), "hello from desktop\n")
+	repo := workspace.Repo{Name: "api", Path: desktop}
+
+	pullCheck = true
+	entry = processRepoPull(context.Background(), repo)
+	if entry.Outcome != pullConflicts {
+		t.Errorf("--check outcome = %q, want %s", entry.Outcome, pullConflicts)
+	}
+
+	pullCheck = false
+	entry = processRepoPull(context.Background(), repo)
+	if entry.Outcome != "skipped" {
+		t.Errorf("pull outcome = %q, want skipped", entry.Outcome)
+	}
+	if got := git(t, desktop, "branch", "--show-current"); got != "main" {
+		t.Errorf("current branch = %q, want main kept", got)
+	}
+	if got := git(t, desktop, "status", "--porcelain"); got != "M README.md" {
+		t.Errorf("worktree = %q, want the local change untouched", got)
+	}
+}
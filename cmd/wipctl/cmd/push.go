@@ -2,14 +2,19 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/ai"
 	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/gitexec"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/hooks"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/msgedit"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/queue"
 	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/report"
 	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/ui"
 	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/workspace"
@@ -19,6 +24,8 @@ var (
 	pushConcurrency int
 	wipPrefix       string
 	autoAdd         bool
+	pushRolling     bool
+	pushEdit        bool
 
 	aiCommit     bool
 	aiProvider   string
@@ -42,10 +49,27 @@ For each repository that has changes:
 3. Handle dirty/untracked files (prompt or --auto-add)
 4. Generate commit message (AI or fallback)
 5. Create WIP branch and commit
-6. Push WIP branch to origin
+6. Push WIP branch to the WIP remote (origin unless wip_remote is configured)
 7. Push current branch if it exists on origin
 
-WIP branches use the format: wip/<host>/<timestamp>`,
+WIP branches are named by the naming.template config (default
+wip/{host}/{feature}/{ts}, i.e. wip/<host>/<timestamp>). Tokens: {user},
+{host}, {feature}, {branch}, {ts}.
+
+With --rolling, each repository keeps one wip/<host>/<branch> ref instead:
+every run adds a commit on top of the previous WIP commit (the working
+branch is left as is), and the ref is restarted with --force-with-lease
+when the working branch was rebased.
+
+With --edit, every repository is staged and given a proposed message (AI or
+fallback) first; then one file opens in $EDITOR listing each repository with
+its diffstat and message, like a rebase todo list. Edit a message to change
+it, or delete a repository's block to leave it uncommitted.
+
+With --gates (or gates.enabled in config), each repository's quality gates
+run before committing: go build/vet for go.mod, npm test for package.json,
+or the commands configured under gates. The gates.policy decides whether a
+failure blocks the repository, warns, or only annotates the commit message.`,
 	RunE: runPush,
 }
 
@@ -53,11 +77,12 @@ func init() {
 	rootCmd.AddCommand(pushCmd)
 
 	pushCmd.Flags().IntVar(&pushConcurrency, "concurrency", 6, "number of concurrent repository operations")
-	pushCmd.Flags().StringVar(&wipPrefix, "prefix", "", "WIP branch prefix (default: wip/<host>/<timestamp>)")
+	pushCmd.Flags().StringVar(&wipPrefix, "prefix", "", "exact WIP branch name (default: rendered from the naming template)")
 	pushCmd.Flags().BoolVar(&autoAdd, "auto-add", false, "automatically add all changes without prompting")
+	pushCmd.Flags().BoolVar(&pushRolling, "rolling", false, "commit onto one rolling wip/<host>/<branch> ref instead of a new branch")
 
 	pushCmd.Flags().BoolVar(&aiCommit, "ai-commit", false, "use AI to generate commit messages")
-	pushCmd.Flags().StringVar(&aiProvider, "ai-provider", "none", "AI provider: none|exec|openai|ollama")
+	pushCmd.Flags().StringVar(&aiProvider, "ai-provider", "none", "AI provider or comma-separated fallback chain: none|exec|openai|ollama|claude (e.g. claude,openai,none)")
 	pushCmd.Flags().StringVar(&aiEndpoint, "ai-endpoint", "", "AI endpoint URL")
 	pushCmd.Flags().StringVar(&aiModel, "ai-model", "", "AI model name")
 	pushCmd.Flags().StringVar(&aiToken, "ai-token", "", "AI API token")
@@ -65,6 +90,11 @@ func init() {
 	pushCmd.Flags().IntVar(&aiMaxTokens, "ai-max-tokens", 256, "AI max tokens")
 	pushCmd.Flags().Float64Var(&aiTemp, "ai-temperature", 0.1, "AI temperature")
 	pushCmd.Flags().BoolVar(&aiReview, "ai-review", false, "review AI-generated messages (forces concurrency=1)")
+	pushCmd.Flags().BoolVar(&pushEdit, "edit", false, "generate all messages first, then review and edit them in one $EDITOR file")
+	pushCmd.MarkFlagsMutuallyExclusive("edit", "ai-review")
+	pushCmd.Flags().BoolVar(&gatesOn, "gates", false, "run quality gates (build/vet/tests) before committing")
+	pushCmd.Flags().BoolVar(&gatesOff, "no-gates", false, "skip quality gates even when enabled in config")
+	pushCmd.MarkFlagsMutuallyExclusive("gates", "no-gates")
 }
 
 func runPush(cmd *cobra.Command, args []string) error {
@@ -74,14 +104,19 @@ func runPush(cmd *cobra.Command, args []string) error {
 		ui.Info("🧪 DRY RUN MODE - No actual git operations will be performed")
 	}
 
+	ctx = withSigning(ctx)
+
+	if wipPrefix != "" && isProtectedBranch(wipPrefix) {
+		ui.Error(fmt.Sprintf("Refusing to push WIP commits to protected branch %s", wipPrefix))
+		return fmt.Errorf("%s is a protected branch", wipPrefix)
+	}
+
 	if aiReview {
 		pushConcurrency = 1
 		ui.Info("AI review enabled - using serial processing")
 	}
 
-	if wipPrefix == "" {
-		wipPrefix = fmt.Sprintf("wip/%s/%s", hostName, time.Now().Format("20060102-150405"))
-	}
+	started := time.Now()
 
 	ui.Info("Discovering Git repositories...")
 	repos, err := workspace.Discover(ctx, workspacePath)
@@ -95,13 +130,39 @@ func runPush(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
-	ui.Info(fmt.Sprintf("Processing %d repositories with WIP prefix: %s", len(repos), wipPrefix))
+	if pushRolling {
+		ui.Info(fmt.Sprintf("Processing %d repositories onto rolling WIP refs", len(repos)))
+	} else if wipPrefix != "" {
+		ui.Info(fmt.Sprintf("Processing %d repositories with WIP prefix: %s", len(repos), wipPrefix))
+	} else {
+		ui.Info(fmt.Sprintf("Processing %d repositories with WIP branch template: %s", len(repos), wipNames.Template()))
+	}
 
 	aiConfig := buildAIConfig()
 	generator := ai.NewGenerator(aiConfig)
 
 	rep := report.NewReport("WIP Push Report", workspacePath, reportDir, "push")
 
+	if err := runOperationHook(ctx, hooks.Pre("push"), "push", ""); err != nil {
+		return err
+	}
+
+	if pushEdit {
+		err := pushWithEditor(ctx, repos, generator, started, rep)
+		if saveErr := rep.Save(); saveErr != nil {
+			ui.Warning("Failed to save report: " + saveErr.Error())
+		}
+		if err != nil {
+			ui.Error("Push aborted: " + err.Error())
+			return err
+		}
+		runOperationHook(ctx, hooks.Post("push"), "push", operationOutcome(rep.Entries)) //nolint:errcheck // post hook failures are reported, not fatal
+		autoFlushPushQueue(ctx, rep.Entries)
+		showAIUsage(rep.AIUsage())
+		ui.Success("Push operation completed. Report saved.")
+		return nil
+	}
+
 	var mu sync.Mutex
 	var wg sync.WaitGroup
 	semaphore := make(chan struct{}, pushConcurrency)
@@ -114,7 +175,7 @@ func runPush(cmd *cobra.Command, args []string) error {
 			semaphore <- struct{}{}
 			defer func() { <-semaphore }()
 
-			entry := processRepoPush(ctx, repo, generator, wipPrefix)
+			entry := processRepoPush(ctx, repo, generator, pushWipBranch(ctx, repo.Path, started))
 
 			mu.Lock()
 			rep.AddEntry(entry)
@@ -128,13 +189,157 @@ func runPush(cmd *cobra.Command, args []string) error {
 		ui.Warning("Failed to save report: " + err.Error())
 	}
 
+	runOperationHook(ctx, hooks.Post("push"), "push", operationOutcome(rep.Entries)) //nolint:errcheck // post hook failures are reported, not fatal
+
+	autoFlushPushQueue(ctx, rep.Entries)
+
+	showAIUsage(rep.AIUsage())
 	ui.Success("Push operation completed. Report saved.")
 	return nil
 }
 
+// pendingPush is a staged repository whose message awaits review in $EDITOR
+type pendingPush struct {
+	repo      workspace.Repo
+	wipBranch string
+	status    *gitexec.RepoStatus
+	message   string
+	entry     report.ReportEntry
+	event     hooks.Event
+}
+
+// pushWithEditor stages every repository and proposes its message, opens
+// them all in one $EDITOR file, then commits and pushes the repositories
+// whose messages were kept. Aborting the edit unstages everything.
+func pushWithEditor(ctx context.Context, repos []workspace.Repo, generator ai.Generator, started time.Time, rep *report.Report) error {
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, pushConcurrency)
+	var pending []*pendingPush
+
+	for _, repo := range repos {
+		wg.Add(1)
+		go func(repo workspace.Repo) {
+			defer wg.Done()
+
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			wipBranch := pushWipBranch(ctx, repo.Path, started)
+			entry := report.CreatePushEntry(repo.Name, "", wipBranch, "")
+			event := repoHookEvent(ctx, "push", repo.Path, wipBranch)
+
+			if !skipOnPreRepoHook(ctx, &entry, event) {
+				var status *gitexec.RepoStatus
+				var ok bool
+				if entry, status, ok = preparePush(ctx, repo, wipBranch, entry); ok {
+					p := &pendingPush{repo: repo, wipBranch: wipBranch, status: status, entry: entry, event: event}
+					message, attribution := generateCommitMessage(ctx, repo, generator, status)
+					p.message = annotateGates(repo.Name, message, entry.Gates)
+					p.entry.AI, p.entry.Usage = attribution.String(), attribution.Calls()
+					mu.Lock()
+					pending = append(pending, p)
+					mu.Unlock()
+					return
+				}
+			}
+
+			finishRepoHook(ctx, &entry, event)
+			mu.Lock()
+			rep.AddEntry(entry)
+			mu.Unlock()
+		}(repo)
+	}
+	wg.Wait()
+
+	if len(pending) == 0 {
+		return nil
+	}
+
+	sort.Slice(pending, func(i, j int) bool { return pending[i].repo.Path < pending[j].repo.Path })
+	items := make([]msgedit.Item, 0, len(pending))
+	for _, p := range pending {
+		diffStat, _ := gitexec.DiffStatCached(ctx, p.repo.Path)
+		items = append(items, msgedit.Item{Key: p.repo.Path, Branch: p.status.Branch, DiffStat: diffStat, Message: p.message})
+	}
+
+	messages, editErr := editCommitMessages(ctx, "wipctl push", items)
+
+	for _, p := range pending {
+		wg.Add(1)
+		go func(p *pendingPush) {
+			defer wg.Done()
+
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			entry := p.entry
+			if message, kept := messages[p.repo.Path]; kept {
+				entry = commitAndPushWip(ctx, p.repo, p.wipBranch, p.status, message, entry)
+			} else {
+				if err := gitexec.ResetIndex(ctx, p.repo.Path); err != nil {
+					entry.AddWarning(fmt.Sprintf("failed to unstage: %v", err))
+				}
+				if editErr != nil {
+					// Post-repo hooks see the abort rather than a normal skip
+					entry.Outcome = "aborted"
+					entry.AddWarning("message edit aborted: " + editErr.Error())
+				} else {
+					entry.Outcome = "skipped"
+					entry.AddWarning("message removed in editor")
+				}
+			}
+
+			finishRepoHook(ctx, &entry, p.event)
+			mu.Lock()
+			rep.AddEntry(entry)
+			mu.Unlock()
+		}(p)
+	}
+	wg.Wait()
+
+	return editErr
+}
+
+// pushWipBranch returns the --prefix name, or renders one for the repo's current branch
+func pushWipBranch(ctx context.Context, repoPath string, started time.Time) string {
+	if wipPrefix != "" {
+		return wipPrefix
+	}
+	branch, _ := gitexec.CurrentBranch(ctx, repoPath)
+	if pushRolling {
+		return rollingBranchName(branch)
+	}
+	return renderWipBranch(branch, "", started)
+}
+
 func processRepoPush(ctx context.Context, repo workspace.Repo, generator ai.Generator, wipPrefix string) report.ReportEntry {
 	entry := report.CreatePushEntry(repo.Name, "", wipPrefix, "")
+	event := repoHookEvent(ctx, "push", repo.Path, wipPrefix)
+
+	if !skipOnPreRepoHook(ctx, &entry, event) {
+		entry = pushRepo(ctx, repo, generator, wipPrefix, entry)
+	}
+
+	finishRepoHook(ctx, &entry, event)
+	return entry
+}
+
+func pushRepo(ctx context.Context, repo workspace.Repo, generator ai.Generator, wipPrefix string, entry report.ReportEntry) report.ReportEntry {
+	entry, status, ok := preparePush(ctx, repo, wipPrefix, entry)
+	if !ok {
+		return entry
+	}
 
+	message, attribution := generateCommitMessage(ctx, repo, generator, status)
+	message = annotateGates(repo.Name, message, entry.Gates)
+	entry.AI, entry.Usage = attribution.String(), attribution.Calls()
+	return commitAndPushWip(ctx, repo, wipPrefix, status, message, entry)
+}
+
+// preparePush checks preconditions, fetches and stages changes. It returns
+// false when the entry is already final (skipped or error).
+func preparePush(ctx context.Context, repo workspace.Repo, wipPrefix string, entry report.ReportEntry) (report.ReportEntry, *gitexec.RepoStatus, bool) {
 	slog.Info("Processing repository", "repo", repo.Path)
 
 	ok, reason := gitexec.Preconditions(ctx, repo.Path)
@@ -142,21 +347,23 @@ func processRepoPush(ctx context.Context, repo workspace.Repo, generator ai.Gene
 		entry.Outcome = "skipped"
 		entry.AddWarning(reason)
 		ui.Warning(fmt.Sprintf("%s: %s", repo.Name, reason))
-		return entry
+		return entry, nil, false
 	}
 
-	if err := gitexec.Fetch(ctx, repo.Path); err != nil {
+	if err := gitexec.Fetch(ctx, repo.Path); err != nil && gitexec.IsNetworkError(err) {
+		entry.AddWarning("origin unreachable - continuing offline, pushes will be queued")
+	} else if err != nil {
 		entry.Outcome = "error"
 		entry.AddError(fmt.Sprintf("fetch failed: %v", err))
 		ui.Error(fmt.Sprintf("%s: fetch failed", repo.Name))
-		return entry
+		return entry, nil, false
 	}
 
 	status, err := gitexec.Status(ctx, repo.Path)
 	if err != nil {
 		entry.Outcome = "error"
 		entry.AddError(fmt.Sprintf("status check failed: %v", err))
-		return entry
+		return entry, nil, false
 	}
 
 	entry.Details = fmt.Sprintf("%s (wip=%s)", status.Branch, wipPrefix)
@@ -166,38 +373,70 @@ func processRepoPush(ctx context.Context, repo workspace.Repo, generator ai.Gene
 		if err != nil {
 			entry.Outcome = "error"
 			entry.AddError(fmt.Sprintf("junk check failed: %v", err))
-			return entry
+			return entry, nil, false
 		}
 
 		if hasJunk {
 			entry.Outcome = "skipped"
 			entry.AddWarning(fmt.Sprintf("untracked junk files found: %v", junkFiles))
 			ui.Warning(fmt.Sprintf("%s: junk files found, fix .gitignore", repo.Name))
-			return entry
+			return entry, nil, false
 		}
 
-		if !autoAdd {
+		if !autoAdd && !pushEdit {
 			if pushConcurrency == 1 {
 				if !ui.Confirm(fmt.Sprintf("%s: Add all changes and continue?", repo.Name)) {
 					entry.Outcome = "skipped"
 					entry.AddWarning("user declined to add changes")
-					return entry
+					return entry, nil, false
 				}
 			} else {
 				entry.Outcome = "skipped"
 				entry.AddWarning("changes present but auto-add not enabled")
-				return entry
+				return entry, nil, false
 			}
 		}
 
 		if err := gitexec.AddAll(ctx, repo.Path); err != nil {
 			entry.Outcome = "error"
 			entry.AddError(fmt.Sprintf("add all failed: %v", err))
-			return entry
+			return entry, nil, false
+		}
+	}
+
+	if gatesEnabled() && !applyGatePolicy(repo.Name, runRepoGates(ctx, repo.Path, repo.Name), &entry) {
+		if err := gitexec.ResetIndex(ctx, repo.Path); err != nil {
+			entry.AddWarning(fmt.Sprintf("failed to unstage: %v", err))
 		}
+		return entry, nil, false
 	}
 
-	message := generateCommitMessage(ctx, repo, generator, status)
+	return entry, status, true
+}
+
+// commitAndPushWip commits the staged changes with message onto the WIP
+// branch (or rolling ref) and pushes it
+func commitAndPushWip(ctx context.Context, repo workspace.Repo, wipPrefix string, status *gitexec.RepoStatus, message string, entry report.ReportEntry) report.ReportEntry {
+	if pushRolling {
+		result, err := rollingCheckpoint(ctx, repo.Path, repo.Name, status.Branch, message)
+		if err != nil {
+			entry.Outcome = "error"
+			entry.AddError(fmt.Sprintf("rolling checkpoint failed: %v", err))
+			return entry
+		}
+		if result.Rebased {
+			entry.AddWarning("base branch was rebased - rolling WIP ref restarted on it")
+		}
+		entry.Details = fmt.Sprintf("%s: %s", status.Branch, describeRolling(result))
+		if result.Queued {
+			entry.Outcome = "queued"
+			ui.Warning(fmt.Sprintf("%s: %s", repo.Name, describeRolling(result)))
+			return entry
+		}
+		entry.Outcome = "success"
+		ui.Success(fmt.Sprintf("%s: %s", repo.Name, describeRolling(result)))
+		return entry
+	}
 
 	if err := gitexec.SwitchCreate(ctx, repo.Path, wipPrefix); err != nil {
 		entry.Outcome = "error"
@@ -211,7 +450,20 @@ func processRepoPush(ctx context.Context, repo workspace.Repo, generator ai.Gene
 		return entry
 	}
 
-	if err := gitexec.PushUpstream(ctx, repo.Path, wipPrefix); err != nil {
+	wipRemote, err := ensureWipRemote(ctx, repo.Path, repo.Name)
+	if err != nil {
+		entry.Outcome = "error"
+		entry.AddError(fmt.Sprintf("WIP remote setup failed: %v", err))
+		return entry
+	}
+
+	if err := gitexec.PushUpstreamTo(ctx, repo.Path, wipRemote, wipPrefix); err != nil {
+		if queuePush(ctx, repo.Name, repo.Path, wipRemote, wipPrefix, queue.ModeUpstream, "", err) {
+			entry.Outcome = "queued"
+			entry.AddWarning("remote unreachable - WIP commit kept locally and push queued (run 'wipctl flush')")
+			ui.Warning(fmt.Sprintf("%s: offline, WIP push queued", repo.Name))
+			return entry
+		}
 		entry.Outcome = "error"
 		entry.AddError(fmt.Sprintf("push WIP branch failed: %v", err))
 		return entry
@@ -221,8 +473,12 @@ func processRepoPush(ctx context.Context, repo workspace.Repo, generator ai.Gene
 	if err == nil && hasRemote {
 		if err := gitexec.Switch(ctx, repo.Path, status.Branch); err != nil {
 			entry.AddWarning(fmt.Sprintf("failed to switch back to %s", status.Branch))
-		} else {
-			if err := gitexec.Push(ctx, repo.Path, status.Branch); err != nil {
+		} else if isProtectedBranch(status.Branch) {
+			entry.AddWarning(protectedSkipReason("pushing", status.Branch))
+		} else if err := gitexec.Push(ctx, repo.Path, status.Branch); err != nil {
+			if queuePush(ctx, repo.Name, repo.Path, "origin", status.Branch, queue.ModePlain, "", err) {
+				entry.AddWarning(fmt.Sprintf("origin unreachable - push of %s queued", status.Branch))
+			} else {
 				entry.AddWarning(fmt.Sprintf("failed to push current branch %s", status.Branch))
 			}
 		}
@@ -233,12 +489,15 @@ func processRepoPush(ctx context.Context, repo workspace.Repo, generator ai.Gene
 	return entry
 }
 
-func generateCommitMessage(ctx context.Context, repo workspace.Repo, generator ai.Generator, status *gitexec.RepoStatus) string {
+// generateCommitMessage returns the commit message for the staged changes and
+// which AI provider wrote it, naming none for the template fallback. The
+// attribution carries the usage of any AI calls either way.
+func generateCommitMessage(ctx context.Context, repo workspace.Repo, generator ai.Generator, status *gitexec.RepoStatus) (string, *ai.Attribution) {
 	fallback := fmt.Sprintf("chore(wip): checkpoint %s (%s) — %d files @ %s",
 		hostName, status.Branch, status.Dirty+status.Untracked, time.Now().Format("2006-01-02 15:04:05"))
 
 	if !aiCommit {
-		return fallback
+		return fallback, nil
 	}
 
 	input := ai.CommitMsgInput{
@@ -267,27 +526,33 @@ func generateCommitMessage(ctx context.Context, repo workspace.Repo, generator a
 		input.PriorSubjects = subjects
 	}
 
+	input.Diff, input.DiffOmitted = ai.StagedDiff(ctx, repo.Path, buildAIConfig().DiffBudget())
+
+	ctx, attribution := ai.WithAttribution(ctx)
 	message, err := generator.CommitMessage(ctx, input)
+	if errors.Is(err, ai.ErrPromptShown) {
+		return fallback, nil
+	}
 	if err != nil {
 		slog.Warn("AI commit message generation failed, using fallback",
 			"repo", repo.Path,
 			"error", err)
-		return fallback
+		return fallback, attribution.Unused()
 	}
 
 	if message == "" {
-		return fallback
+		return fallback, attribution.Unused()
 	}
 
 	if aiReview {
 		ui.Info("AI-generated commit message:")
 		fmt.Printf("  %s\n\n", message)
 		if !ui.Confirm("Accept this message?") {
-			return fallback
+			return fallback, attribution.Unused()
 		}
 	}
 
-	return message
+	return message, attribution
 }
 
 func buildAIConfig() ai.Config {
@@ -299,6 +564,7 @@ func buildAIConfig() ai.Config {
 		Model:       aiModel,
 		Token:       aiToken,
 		ExecPath:    aiExec,
+		Providers:   envConfig.Providers,
 		MaxTokens:   aiMaxTokens,
 		Temperature: aiTemp,
 	}
@@ -318,6 +584,14 @@ func buildAIConfig() ai.Config {
 	if config.ExecPath == "" && envConfig.ExecPath != "" {
 		config.ExecPath = envConfig.ExecPath
 	}
+	config.ContextSize = envConfig.ContextSize
+	config.DiffTokens = envConfig.DiffTokens
+	config.ExecProtocol = envConfig.ExecProtocol
+	config.ExecTimeout = envConfig.ExecTimeout
+	config.Cache = envConfig.Cache
+	config.CacheRefresh = envConfig.CacheRefresh
+	config.Redaction = envConfig.Redaction
+	config.ShowPrompt = envConfig.ShowPrompt
 
 	return config
 }
\ No newline at end of file
@@ -0,0 +1,396 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/ai"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/gitexec"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/report"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/ui"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/workspace"
+)
+
+var resolveWithAI bool
+
+// resolveContextLines is how much surrounding text is shown around each conflict hunk
+const resolveContextLines = 3
+
+type resolveAction int
+
+const (
+	resolveDone resolveAction = iota
+	resolveSkip
+	resolveQuit
+)
+
+var resolveCmd = &cobra.Command{
+	Use:   "resolve [repository-path]",
+	Short: "Interactively resolve conflicts left behind by wipctl pull",
+	Long: `Walk every conflicted repository and file and resolve them interactively.
+
+For each conflicted file both sides are shown and you can choose:
+  o  keep ours (the WIP branch / HEAD side)
+  t  keep theirs (your stashed changes / incoming side)
+  e  open the file in $EDITOR and fix the markers by hand
+  a  ask the AI provider for a merged version (with --ai)
+  s  skip the file for now
+  q  stop resolving
+
+Once every file in a repository is resolved the interrupted operation is
+finished: the auto-stash from pull is dropped, or the merge/rebase is
+continued. Results are saved in a resolve report and appended to the
+latest pull report.
+
+Examples:
+  wipctl resolve                   # Resolve all conflicted repositories
+  wipctl resolve ./api --ai        # Resolve one repo with AI proposals`,
+	RunE: runResolve,
+}
+
+func init() {
+	rootCmd.AddCommand(resolveCmd)
+	resolveCmd.Flags().BoolVar(&resolveWithAI, "ai", false, "offer AI-proposed merges via the configured provider")
+}
+
+func runResolve(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	if dryRun {
+		ctx = context.WithValue(ctx, gitexec.DryRunKey, true)
+		ui.Info("🧪 DRY RUN MODE - No actual git operations will be performed")
+	}
+
+	var repos []workspace.Repo
+	if len(args) > 0 {
+		if !isGitRepository(args[0]) {
+			ui.Error("Not a git repository: " + args[0])
+			return fmt.Errorf("not a git repository")
+		}
+		absPath, err := filepath.Abs(args[0])
+		if err != nil {
+			return err
+		}
+		repos = []workspace.Repo{{Path: args[0], Name: filepath.Base(absPath)}}
+	} else {
+		ui.Info("Discovering Git repositories...")
+		found, err := workspace.Discover(ctx, workspacePath)
+		if err != nil {
+			ui.Error("Failed to discover repositories: " + err.Error())
+			return err
+		}
+		repos = found
+	}
+
+	var conflicted []workspace.Repo
+	for _, repo := range repos {
+		if has, _, err := gitexec.HasConflicts(ctx, repo.Path); err == nil && has {
+			conflicted = append(conflicted, repo)
+		}
+	}
+
+	if len(conflicted) == 0 {
+		ui.Success("No conflicted repositories found")
+		return nil
+	}
+
+	ui.Info(fmt.Sprintf("Found %d conflicted repositories", len(conflicted)))
+
+	var integration *ai.Integration
+	if resolveWithAI {
+		integration = ai.NewIntegration()
+		if !integration.IsEnabled() {
+			ui.Warning("AI provider not configured - AI proposals disabled")
+			integration = nil
+		}
+	}
+
+	rep := report.NewReport("WIP Resolve Report", workspacePath, reportDir, "resolve")
+
+	quit := false
+	for _, repo := range conflicted {
+		if quit {
+			entry := report.ReportEntry{Repo: repo.Name, Outcome: "conflicts"}
+			entry.AddWarning("resolution not attempted (stopped by user)")
+			rep.AddEntry(entry)
+			continue
+		}
+
+		var entry report.ReportEntry
+		entry, quit = resolveRepo(ctx, repo, integration)
+		rep.AddEntry(entry)
+	}
+
+	if err := rep.Save(); err != nil {
+		ui.Warning("Failed to save report: " + err.Error())
+	}
+
+	if pullReport, err := report.LatestReport(reportDir, "pull"); err == nil {
+		if err := report.AppendSection(pullReport, "Conflict Resolution", rep.Entries); err != nil {
+			ui.Warning("Failed to update pull report: " + err.Error())
+		} else {
+			ui.Info("Updated pull report: " + filepath.Base(pullReport))
+		}
+	}
+
+	ui.Success("Resolve operation completed. Report saved.")
+	return nil
+}
+
+// resolveRepo resolves every conflicted file in a repository and finishes the
+// interrupted operation. The bool result is true when the user asked to quit.
+func resolveRepo(ctx context.Context, repo workspace.Repo, integration *ai.Integration) (report.ReportEntry, bool) {
+	entry := report.ReportEntry{Repo: repo.Name}
+	resolved := 0
+
+	for {
+		op := gitexec.InProgressOperation(ctx, repo.Path)
+		oursLabel, theirsLabel := conflictLabels(op)
+
+		_, files, err := gitexec.HasConflicts(ctx, repo.Path)
+		if err != nil {
+			entry.Outcome = "error"
+			entry.AddError(fmt.Sprintf("conflict detection failed: %v", err))
+			return entry, false
+		}
+
+		var skipped []string
+		for _, file := range files {
+			switch resolveFile(ctx, repo, file, oursLabel, theirsLabel, integration) {
+			case resolveDone:
+				resolved++
+			case resolveSkip:
+				skipped = append(skipped, file)
+			case resolveQuit:
+				entry.Outcome = "conflicts"
+				entry.Details = fmt.Sprintf("%d files resolved before stopping", resolved)
+				entry.AddWarning("resolution stopped by user")
+				return entry, true
+			}
+		}
+
+		if len(skipped) > 0 {
+			entry.Outcome = "conflicts"
+			entry.Details = fmt.Sprintf("%d files resolved", resolved)
+			entry.AddWarning(fmt.Sprintf("unresolved files: %v", skipped))
+			return entry, false
+		}
+
+		if err := finishResolvedOperation(ctx, repo.Path, op, &entry); err != nil {
+			entry.Outcome = "error"
+			entry.AddError(fmt.Sprintf("continue %s failed: %v", operationName(op), err))
+			ui.Error(fmt.Sprintf("%s: continue %s failed", repo.Name, operationName(op)))
+			return entry, false
+		}
+
+		// A rebase can stop again on the next commit with fresh conflicts
+		if has, _, _ := gitexec.HasConflicts(ctx, repo.Path); has && !gitexec.IsDryRun(ctx) {
+			ui.Info(fmt.Sprintf("%s: %s stopped with new conflicts", repo.Name, operationName(op)))
+			continue
+		}
+
+		entry.Outcome = "resolved"
+		entry.Details = fmt.Sprintf("%d files resolved, %s completed", resolved, operationName(op))
+		ui.Success(fmt.Sprintf("%s: conflicts resolved", repo.Name))
+		return entry, false
+	}
+}
+
+// resolveFile shows both sides of a conflicted file and applies the chosen resolution
+func resolveFile(ctx context.Context, repo workspace.Repo, file, oursLabel, theirsLabel string, integration *ai.Integration) resolveAction {
+	fullPath := filepath.Join(repo.Path, file)
+
+	base, _ := gitexec.ConflictStage(ctx, repo.Path, file, 1)
+	ours, oursErr := gitexec.ConflictStage(ctx, repo.Path, file, 2)
+	theirs, theirsErr := gitexec.ConflictStage(ctx, repo.Path, file, 3)
+
+	ui.Info(fmt.Sprintf("⚔️  %s: %s", repo.Name, file))
+	switch {
+	case oursErr != nil:
+		ui.Warning(fmt.Sprintf("deleted in %s, modified in %s", oursLabel, theirsLabel))
+	case theirsErr != nil:
+		ui.Warning(fmt.Sprintf("modified in %s, deleted in %s", oursLabel, theirsLabel))
+	default:
+		fmt.Printf("<<<<<<< = %s, >>>>>>> = %s\n\n", oursLabel, theirsLabel)
+		if content, err := os.ReadFile(fullPath); err == nil {
+			for _, hunk := range conflictHunks(string(content)) {
+				fmt.Println(hunk)
+				fmt.Println()
+			}
+		}
+	}
+
+	options := "[o]urs, [t]heirs, [e]dit"
+	if integration != nil {
+		options += ", [a]i"
+	}
+	options += ", [s]kip, [q]uit"
+
+	for {
+		switch strings.ToLower(ui.Prompt(fmt.Sprintf("Resolve %s? %s:", file, options))) {
+		case "o", "ours":
+			if err := takeConflictSide(ctx, repo.Path, file, "ours", oursErr != nil); err != nil {
+				ui.Error("Failed to take ours: " + err.Error())
+				continue
+			}
+			return resolveDone
+		case "t", "theirs":
+			if err := takeConflictSide(ctx, repo.Path, file, "theirs", theirsErr != nil); err != nil {
+				ui.Error("Failed to take theirs: " + err.Error())
+				continue
+			}
+			return resolveDone
+		case "e", "edit":
+			if err := ui.OpenEditor(fullPath); err != nil {
+				ui.Error("Editor failed: " + err.Error())
+				continue
+			}
+			content, err := os.ReadFile(fullPath)
+			if err == nil && hasConflictMarkers(string(content)) {
+				ui.Warning("Conflict markers remain - edit again or pick another option")
+				continue
+			}
+			if err := gitexec.AddPaths(ctx, repo.Path, file); err != nil {
+				ui.Error("Failed to stage file: " + err.Error())
+				continue
+			}
+			return resolveDone
+		case "a", "ai":
+			if integration == nil {
+				ui.Warning("unknown choice - AI is not configured")
+				continue
+			}
+			ui.Info("🤖 Requesting AI merge proposal...")
+			proposal, err := integration.ProposeMerge(ctx, ai.MergeInput{
+				Repo:        repo.Name,
+				Path:        file,
+				Base:        base,
+				Ours:        ours,
+				Theirs:      theirs,
+				OursLabel:   oursLabel,
+				TheirsLabel: theirsLabel,
+			})
+			if err != nil {
+				ui.Error("AI merge failed: " + err.Error())
+				continue
+			}
+			if hasConflictMarkers(proposal) {
+				ui.Warning("AI proposal still contains conflict markers - rejected")
+				continue
+			}
+			fmt.Println(proposal)
+			if !ui.Confirm("Accept this AI-proposed merge?") {
+				continue
+			}
+			if gitexec.IsDryRun(ctx) {
+				fmt.Printf("[DRY RUN] Would write AI merge to %s\n", fullPath)
+				return resolveDone
+			}
+			if err := os.WriteFile(fullPath, []byte(proposal), 0644); err != nil {
+				ui.Error("Failed to write merge: " + err.Error())
+				continue
+			}
+			if err := gitexec.AddPaths(ctx, repo.Path, file); err != nil {
+				ui.Error("Failed to stage file: " + err.Error())
+				continue
+			}
+			return resolveDone
+		case "", "s", "skip":
+			// An empty answer is also what a closed stdin yields, so it
+			// must end the loop rather than ask again forever
+			return resolveSkip
+		case "q", "quit":
+			return resolveQuit
+		default:
+			ui.Warning("unknown choice - answer with one of " + options)
+		}
+	}
+}
+
+// takeConflictSide keeps one side of the conflict, honouring deletions
+func takeConflictSide(ctx context.Context, repoPath, file, side string, deleted bool) error {
+	if deleted {
+		return gitexec.RemovePath(ctx, repoPath, file)
+	}
+	if err := gitexec.CheckoutConflictSide(ctx, repoPath, file, side); err != nil {
+		return err
+	}
+	return gitexec.AddPaths(ctx, repoPath, file)
+}
+
+// finishResolvedOperation completes whatever was interrupted by the conflict
+func finishResolvedOperation(ctx context.Context, repoPath, op string, entry *report.ReportEntry) error {
+	if op != "" {
+		return gitexec.ContinueOperation(ctx, repoPath, op)
+	}
+
+	// No merge/rebase in progress: the conflict came from popping the pull
+	// auto-stash, which git keeps until the conflict is resolved.
+	if err := gitexec.ResetIndex(ctx, repoPath); err != nil {
+		return err
+	}
+
+	stash := gitexec.ConfigValue(ctx, repoPath, pullStashConfigKey)
+	if stash == "" {
+		entry.AddWarning("no recorded wipctl auto-stash - stash list left as is")
+		return nil
+	}
+	err := gitexec.StashDropCommit(ctx, repoPath, stash)
+	if errors.Is(err, gitexec.ErrStashNotFound) {
+		entry.AddWarning(fmt.Sprintf("auto-stash %s is no longer in the stash list", shortSHA(stash)))
+	} else if err != nil {
+		return err
+	}
+	return gitexec.SetConfig(ctx, repoPath, pullStashConfigKey, "")
+}
+
+func conflictLabels(op string) (string, string) {
+	switch op {
+	case "rebase":
+		return "upstream being rebased onto", "your commit being replayed"
+	case "merge", "cherry-pick":
+		return "HEAD", "incoming changes"
+	default:
+		return "WIP branch (HEAD)", "your stashed local changes"
+	}
+}
+
+func operationName(op string) string {
+	if op == "" {
+		return "stash pop"
+	}
+	return op
+}
+
+func hasConflictMarkers(content string) bool {
+	for _, line := range strings.Split(content, "\n") {
+		if strings.HasPrefix(line, "<<<<<<< ") || strings.HasPrefix(line, ">>>>>>> ") {
+			return true
+		}
+	}
+	return false
+}
+
+// conflictHunks extracts each conflict region with a little surrounding context
+func conflictHunks(content string) []string {
+	lines := strings.Split(content, "\n")
+
+	var hunks []string
+	start := -1
+	for i, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "<<<<<<<"):
+			start = i
+		case strings.HasPrefix(line, ">>>>>>>") && start >= 0:
+			from := max(0, start-resolveContextLines)
+			to := min(len(lines), i+1+resolveContextLines)
+			hunks = append(hunks, fmt.Sprintf("@@ line %d @@\n%s", start+1, strings.Join(lines[from:to], "\n")))
+			start = -1
+		}
+	}
+	return hunks
+}
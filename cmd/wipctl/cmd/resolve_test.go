@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/ai"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/gitexec"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/ui"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/workspace"
+)
+
+// setupMergeConflict leaves a clone stopped in a merge that conflicts on
+// README.md, and returns the clone's path
+func setupMergeConflict(t *testing.T) string {
+	t.Helper()
+	root, origin := setupOrigin(t)
+	repo := clone(t, root, origin, "api")
+
+	git(t, repo, "checkout", "-q", "-b", "feature")
+	writeFile(t, filepath.Join(repo, "README.md"), "hello from feature\n")
+	git(t, repo, "commit", "-q", "-am", "feature")
+	git(t, repo, "checkout", "-q", "main")
+	writeFile(t, filepath.Join(repo, "README.md"), "hello from main\n")
+	git(t, repo, "commit", "-q", "-am", "main")
+
+	merge := exec.Command("git", "merge", "-q", "feature")
+	merge.Dir = repo
+	if out, err := merge.CombinedOutput(); err == nil {
+		t.Fatalf("merge did not conflict:\n%s", out)
+	}
+	return repo
+}
+
+// setupMergePlugin configures an exec AI provider that prints reply and
+// saves its request to the returned path
+func setupMergePlugin(t *testing.T, reply string) (*ai.Integration, string) {
+	t.Helper()
+	dir := t.TempDir()
+	request := filepath.Join(dir, "request.json")
+	script := filepath.Join(dir, "merge.sh")
+	body := "#!/bin/sh\ncat > " + request + "\ncat <<'EOF'\n" + reply + "EOF\n"
+	if err := os.WriteFile(script, []byte(body), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("WIPCTL_AI_PROVIDER", "exec")
+	t.Setenv("WIPCTL_AI_EXEC", script)
+
+	integration := ai.NewIntegration()
+	if !integration.IsEnabled() {
+		t.Fatal("exec provider not enabled")
+	}
+	return integration, request
+}
+
+// answer feeds the given lines to the interactive prompts
+func answer(t *testing.T, lines ...string) {
+	t.Helper()
+	ui.SetInput(strings.NewReader(strings.Join(lines, "\n") + "\n"))
+	t.Cleanup(func() { ui.SetInput(os.Stdin) })
+}
+
+func TestResolveAcceptsAIMergeProposal(t *testing.T) {
+	repo := setupMergeConflict(t)
+	integration, request := setupMergePlugin(t, "hello from main and feature\n")
+	answer(t, "a", "y")
+
+	ctx := context.Background()
+	entry, quit := resolveRepo(ctx, workspace.Repo{Path: repo, Name: "api"}, integration)
+	if quit {
+		t.Fatal("resolveRepo reported quit")
+	}
+	if entry.Outcome != "resolved" {
+		t.Fatalf("outcome = %q, want resolved (details %q, errors %v)", entry.Outcome, entry.Details, entry.Errors)
+	}
+
+	content, err := os.ReadFile(filepath.Join(repo, "README.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(content); got != "hello from main and feature" {
+		t.Errorf("README.md = %q, want the AI proposal", got)
+	}
+	if op := gitexec.InProgressOperation(ctx, repo); op != "" {
+		t.Errorf("operation %q still in progress, want the merge committed", op)
+	}
+	if parents := git(t, repo, "rev-list", "--parents", "-n", "1", "HEAD"); len(strings.Fields(parents)) != 3 {
+		t.Errorf("HEAD parents = %q, want a merge commit", parents)
+	}
+
+	sent, err := os.ReadFile(request)
+	if err != nil {
+		t.Fatalf("plugin was not called: %v", err)
+	}
+	for _, side := range []string{"hello from main", "hello from feature", `"command":"merge"`} {
+		if !strings.Contains(string(sent), side) {
+			t.Errorf("merge request missing %q:\n%s", side, sent)
+		}
+	}
+}
+
+func TestResolveRejectsAIProposalWithMarkers(t *testing.T) {
+	repo := setupMergeConflict(t)
+	integration, _ := setupMergePlugin(t, "<<<<<<< HEAD\nhello from main\n=======\nhello from feature\n>>>>>>> feature\n")
+	// A rejected proposal never reaches the accept prompt, so the next
+	// answer is read as a fresh choice
+	answer(t, "a", "s")
+
+	ctx := context.Background()
+	entry, _ := resolveRepo(ctx, workspace.Repo{Path: repo, Name: "api"}, integration)
+	if entry.Outcome != "conflicts" {
+		t.Errorf("outcome = %q, want conflicts", entry.Outcome)
+	}
+	if has, files, _ := gitexec.HasConflicts(ctx, repo); !has || len(files) != 1 {
+		t.Errorf("conflicts = %v %v, want README.md still conflicted", has, files)
+	}
+	if op := gitexec.InProgressOperation(ctx, repo); op != "merge" {
+		t.Errorf("operation = %q, want the merge left in progress", op)
+	}
+}
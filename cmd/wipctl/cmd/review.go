@@ -2,13 +2,16 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/ai"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/aiusage"
 	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/gitexec"
 	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/status"
 	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/ui"
@@ -48,7 +51,9 @@ func init() {
 }
 
 func runReview(cmd *cobra.Command, args []string) error {
-	ctx := context.Background()
+	// Ctrl-C stops the briefing mid-stream instead of killing the process
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
 
 	// Check if specific repo is requested or workspace-wide review
 	if len(args) > 0 {
@@ -89,20 +94,11 @@ func reviewSingleRepository(ctx context.Context, repoPath string) error {
 
 	// Convert to WorkspaceContextInput for enhanced briefing
 	workspaceInput := buildSingleRepoWorkspaceInput(*reviewInput)
-	review, err := generator.WorkspaceContext(ctx, workspaceInput)
-	if err != nil {
-		ui.Error("Failed to generate context briefing: " + err.Error())
-		return err
-	}
-
-	// Display the context briefing
-	ui.Success("📋 Workspace Context Briefing")
-	ui.Info("──────────────────────────────")
-	ui.CyberpunkBanner("WORK SESSION CONTEXT")
-
-	println(review)
-
-	return nil
+	return streamBriefing(ctx, generator, workspaceInput, "context briefing", func() {
+		ui.Success("📋 Workspace Context Briefing")
+		ui.Info("──────────────────────────────")
+		ui.CyberpunkBanner("WORK SESSION CONTEXT")
+	})
 }
 
 func reviewWorkspaceContext(ctx context.Context) error {
@@ -138,19 +134,33 @@ func reviewWorkspaceContext(ctx context.Context) error {
 
 	// Convert to enhanced WorkspaceContextInput
 	workspaceInput := buildEnhancedWorkspaceInput(results)
-	briefing, err := generator.WorkspaceContext(ctx, workspaceInput)
+	return streamBriefing(ctx, generator, workspaceInput, "workspace briefing", func() {
+		ui.Success("📋 Multi-Repository Workspace Context")
+		ui.Info("─────────────────────────────────────────")
+		ui.CyberpunkBanner("WORKSPACE SESSION BRIEFING")
+	})
+}
+
+// streamBriefing generates a workspace briefing and prints it as it streams
+// in, below header. Ctrl-C ends it early without an error.
+func streamBriefing(ctx context.Context, generator ai.Generator, input ai.WorkspaceContextInput, what string, header func()) error {
+	ctx, attribution := ai.WithAttribution(ctx)
+	printer := ui.NewStreamPrinter(header)
+	_, err := ai.StreamWorkspaceContext(ctx, generator, input, printer.Write)
+	printer.Finish()
+	showAIUsage(aiusage.Total(attribution.Calls()))
+
+	if errors.Is(err, context.Canceled) {
+		ui.Warning("Briefing cancelled")
+		return nil
+	}
+	if errors.Is(err, ai.ErrPromptShown) {
+		return nil
+	}
 	if err != nil {
-		ui.Error("Failed to generate workspace briefing: " + err.Error())
+		ui.Error("Failed to generate " + what + ": " + err.Error())
 		return err
 	}
-
-	// Display the workspace briefing
-	ui.Success("📋 Multi-Repository Workspace Context")
-	ui.Info("─────────────────────────────────────────")
-	ui.CyberpunkBanner("WORKSPACE SESSION BRIEFING")
-
-	println(briefing)
-
 	return nil
 }
 
@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/gitexec"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/queue"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/wipname"
+)
+
+// rollingBaseTrailer records which commit of the working branch a rolling
+// WIP commit was taken on top of
+const rollingBaseTrailer = "Wip-Base"
+
+// rollingResult describes one rolling checkpoint
+type rollingResult struct {
+	Branch    string
+	Commit    string
+	Rebased   bool // base branch was rewritten, so the rolling ref restarted on it
+	Unchanged bool // nothing changed since the previous rolling commit
+	Queued    bool // remote was unreachable, push is in the offline queue
+}
+
+// rollingCheckpoint commits the staged tree onto the rolling wip/<host>/<branch>
+// ref and pushes it, leaving the working branch untouched. The index is reset
+// afterwards so the staged snapshot does not linger.
+//
+// The new commit's parents are:
+//   - the previous rolling commit, when the base branch has not moved
+//   - the previous rolling commit and HEAD, when the base branch moved forward
+//   - HEAD only, for the first checkpoint or when the base branch was rebased;
+//     the push then replaces the old chain under --force-with-lease
+func rollingCheckpoint(ctx context.Context, repoPath, repoName, branch, message string) (rollingResult, error) {
+	if branch == "" || branch == "HEAD" {
+		return rollingResult{}, fmt.Errorf("rolling checkpoints need a checked-out branch")
+	}
+
+	result := rollingResult{Branch: rollingBranchName(branch)}
+
+	remote, err := ensureWipRemote(ctx, repoPath, repoName)
+	if err != nil {
+		return result, err
+	}
+	if err := gitexec.FetchRemote(ctx, repoPath, remote); err != nil {
+		slog.Warn("Fetch before rolling checkpoint failed", "repo", repoPath, "remote", remote, "error", err)
+	}
+
+	head := gitexec.ResolveRef(ctx, repoPath, "HEAD")
+	if head == "" {
+		return result, fmt.Errorf("repository has no commits")
+	}
+
+	localRef := "refs/heads/" + result.Branch
+	localTip := gitexec.ResolveRef(ctx, repoPath, localRef)
+	remoteTip := gitexec.ResolveRef(ctx, repoPath, "refs/remotes/"+remote+"/"+result.Branch)
+
+	previous := localTip
+	if previous == "" {
+		previous = remoteTip
+	}
+
+	tree, err := gitexec.WriteTree(ctx, repoPath)
+	if err != nil {
+		return result, fmt.Errorf("write tree: %w", err)
+	}
+
+	parents := []string{head}
+	if previous != "" {
+		base, _ := gitexec.CommitTrailer(ctx, repoPath, previous, rollingBaseTrailer)
+		switch {
+		case base == "" || !gitexec.IsAncestor(ctx, repoPath, base, head):
+			result.Rebased = true
+		case base == head:
+			parents = []string{previous}
+			if prevTree, err := gitexec.TreeOf(ctx, repoPath, previous); err == nil && prevTree == tree {
+				result.Unchanged = true
+			}
+		default:
+			parents = []string{previous, head}
+		}
+	}
+
+	commit := previous
+	if !result.Unchanged {
+		commit, err = gitexec.CommitTree(ctx, repoPath, tree,
+			fmt.Sprintf("%s\n\n%s: %s", message, rollingBaseTrailer, head), parents...)
+		if err != nil {
+			return result, fmt.Errorf("commit-tree: %w", err)
+		}
+		if err := gitexec.UpdateRef(ctx, repoPath, localRef, commit, localTip); err != nil {
+			return result, fmt.Errorf("update %s: %w", result.Branch, err)
+		}
+	}
+	result.Commit = commit
+
+	if err := gitexec.ResetIndex(ctx, repoPath); err != nil {
+		slog.Warn("Failed to unstage after rolling checkpoint", "repo", repoPath, "error", err)
+	}
+
+	if commit != remoteTip || gitexec.IsDryRun(ctx) {
+		if err := gitexec.PushWithLease(ctx, repoPath, remote, result.Branch, remoteTip); err != nil {
+			if queuePush(ctx, repoName, repoPath, remote, result.Branch, queue.ModeLease, remoteTip, err) {
+				result.Queued = true
+				return result, nil
+			}
+			return result, fmt.Errorf("push %s: %w", result.Branch, err)
+		}
+		dequeuePush(repoPath, remote, result.Branch)
+	}
+
+	return result, nil
+}
+
+// rollingBranchName returns the rolling WIP ref name for a working branch
+func rollingBranchName(branch string) string {
+	return wipNames.RenderRolling(wipname.Fields{
+		User:   wipUser(),
+		Host:   hostName,
+		Branch: branch,
+	})
+}
+
+// describeRolling summarizes a rolling checkpoint for reports
+func describeRolling(result rollingResult) string {
+	switch {
+	case result.Queued:
+		return fmt.Sprintf("committed onto %s, push queued (remote unreachable)", result.Branch)
+	case result.Unchanged:
+		return fmt.Sprintf("%s unchanged since last checkpoint", result.Branch)
+	case result.Rebased:
+		return fmt.Sprintf("%s restarted on rebased base (force-with-lease)", result.Branch)
+	default:
+		return fmt.Sprintf("checkpointed onto %s", result.Branch)
+	}
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// rollingStep stages the work tree and takes a rolling checkpoint of main
+func rollingStep(t *testing.T, repo, message string) rollingResult {
+	t.Helper()
+	git(t, repo, "add", "-A")
+	result, err := rollingCheckpoint(context.Background(), repo, "api", "main", message)
+	if err != nil {
+		t.Fatalf("rollingCheckpoint %q: %v", message, err)
+	}
+	return result
+}
+
+// parentsOf returns the parents of a commit
+func parentsOf(t *testing.T, repo, commit string) []string {
+	t.Helper()
+	return strings.Fields(git(t, repo, "rev-list", "--parents", "-n", "1", commit))[1:]
+}
+
+func TestRollingCheckpointChain(t *testing.T) {
+	root, origin := setupOrigin(t)
+	repo := clone(t, root, origin, "api")
+	const branch = "wip/laptop/main"
+
+	// First checkpoint starts on HEAD and leaves the working branch alone
+	writeFile(t, filepath.Join(repo, "notes.txt"), "one\n")
+	head := git(t, repo, "rev-parse", "HEAD")
+	first := rollingStep(t, repo, "checkpoint one")
+	if first.Branch != branch || first.Rebased || first.Unchanged || first.Queued {
+		t.Fatalf("first = %+v, want a fresh checkpoint on %s", first, branch)
+	}
+	if got := parentsOf(t, repo, first.Commit); len(got) != 1 || got[0] != head {
+		t.Errorf("first parents = %v, want [%s]", got, head)
+	}
+	if got := git(t, repo, "rev-parse", "HEAD"); got != head {
+		t.Errorf("HEAD moved to %s", got)
+	}
+	if got := git(t, repo, "diff", "--cached", "--name-only"); got != "" {
+		t.Errorf("index still staged: %q", got)
+	}
+	if got := git(t, origin, "rev-parse", branch); got != first.Commit {
+		t.Errorf("origin %s = %s, want %s", branch, got, first.Commit)
+	}
+
+	// Same base: the chain grows on the previous rolling commit
+	writeFile(t, filepath.Join(repo, "notes.txt"), "two\n")
+	second := rollingStep(t, repo, "checkpoint two")
+	if got := parentsOf(t, repo, second.Commit); len(got) != 1 || got[0] != first.Commit {
+		t.Errorf("second parents = %v, want [%s]", got, first.Commit)
+	}
+
+	// Nothing changed: no new commit
+	again := rollingStep(t, repo, "checkpoint again")
+	if !again.Unchanged || again.Commit != second.Commit {
+		t.Errorf("again = %+v, want unchanged at %s", again, second.Commit)
+	}
+
+	// Base moved forward: the previous rolling commit and HEAD are both parents
+	writeFile(t, filepath.Join(repo, "README.md"), "hello\nmore\n")
+	git(t, repo, "commit", "-q", "-am", "forward")
+	head = git(t, repo, "rev-parse", "HEAD")
+	third := rollingStep(t, repo, "checkpoint three")
+	if got := parentsOf(t, repo, third.Commit); len(got) != 2 || got[0] != second.Commit || got[1] != head {
+		t.Errorf("third parents = %v, want [%s %s]", got, second.Commit, head)
+	}
+
+	// Base rebased: the chain restarts on HEAD and replaces the remote ref
+	git(t, repo, "commit", "-q", "--amend", "-m", "forward, reworded")
+	head = git(t, repo, "rev-parse", "HEAD")
+	fourth := rollingStep(t, repo, "checkpoint four")
+	if !fourth.Rebased {
+		t.Errorf("fourth = %+v, want rebased", fourth)
+	}
+	if got := parentsOf(t, repo, fourth.Commit); len(got) != 1 || got[0] != head {
+		t.Errorf("fourth parents = %v, want [%s]", got, head)
+	}
+	if got := git(t, origin, "rev-parse", branch); got != fourth.Commit {
+		t.Errorf("origin %s = %s, want the restarted chain %s", branch, got, fourth.Commit)
+	}
+	if got := git(t, repo, "show", fourth.Commit+":notes.txt"); got != "two" {
+		t.Errorf("notes.txt in checkpoint = %q, want the working copy", got)
+	}
+}
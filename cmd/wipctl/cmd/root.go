@@ -6,7 +6,12 @@ import (
 	"os"
 
 	"github.com/spf13/cobra"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/ai"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/config"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/hooks"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/queue"
 	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/ui"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/wipname"
 )
 
 var (
@@ -14,6 +19,9 @@ var (
 	reportDir     string
 	hostName      string
 	dryRun        bool
+
+	wipConfig  = &config.Config{}
+	hookRunner *hooks.Runner
 )
 
 var rootCmd = &cobra.Command{
@@ -31,11 +39,14 @@ Features:
 - Markdown reports per run`,
 	PersistentPreRun: func(cmd *cobra.Command, args []string) {
 		initLogging()
+		initConfig(cmd)
 	},
 }
 
 func Execute() error {
 	ui.Banner("wipctl - Workspace Git WIP Sync")
+	// Long-running AI plugins are shut down rather than left to find a closed pipe
+	defer ai.ClosePlugins()
 	return rootCmd.Execute()
 }
 
@@ -44,7 +55,7 @@ func init() {
 
 	rootCmd.PersistentFlags().StringVarP(&workspacePath, "workspace", "w", ".", "workspace directory to search for Git repos")
 	rootCmd.PersistentFlags().StringVar(&reportDir, "report-dir", "", "directory for reports (default: <workspace>/.wipctl)")
-	rootCmd.PersistentFlags().StringVar(&hostName, "host", hostname, "host identifier for WIP branches")
+	rootCmd.PersistentFlags().StringVar(&hostName, "host", hostname, "host identifier for WIP branches (default: naming.host_alias or hostname)")
 	rootCmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "show what would be done without making changes")
 }
 
@@ -57,4 +68,31 @@ func initLogging() {
 	if reportDir == "" {
 		reportDir = fmt.Sprintf("%s/.wipctl", workspacePath)
 	}
-}
\ No newline at end of file
+}
+
+func initConfig(cmd *cobra.Command) {
+	loaded, err := config.Load(reportDir)
+	if err != nil {
+		ui.Warning("Failed to load config: " + err.Error())
+	}
+	wipConfig = loaded
+	hookRunner = hooks.NewRunner(wipConfig.Hooks)
+
+	if alias := wipConfig.Naming.Alias(); alias != "" && !cmd.Flags().Changed("host") {
+		hostName = alias
+	}
+
+	names, err := wipname.New(wipConfig.Naming.BranchTemplate(), wipConfig.Naming.RollingTemplate())
+	if err != nil {
+		ui.Warning("Invalid WIP branch template, using defaults: " + err.Error())
+		names = wipname.Default()
+	}
+	wipNames = names
+
+	pushQueue = queue.Open(reportDir)
+	initAICache()
+	initAIRedaction()
+	initAIPrompts()
+	initAIPrices()
+	initAIUsageLog(cmd)
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/config"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/gitexec"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/report"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/signing"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/ui"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/workspace"
+)
+
+// allowedSignersPath is generated from the trusted keyring before verifying pulls
+var allowedSignersPath string
+
+// withSigning enables signing of WIP commits when configured
+func withSigning(ctx context.Context) context.Context {
+	if !wipConfig.Signing.Enabled() {
+		return ctx
+	}
+	return gitexec.WithSigning(ctx, gitexec.Signing{
+		Format: wipConfig.Signing.Format,
+		Key:    wipConfig.Signing.KeyPath(),
+	})
+}
+
+// prepareVerification writes the SSH allowed-signers file used by verifyWipRef
+func prepareVerification() error {
+	if wipConfig.Signing.VerifyMode() == config.VerifyOff {
+		return nil
+	}
+	path, err := signing.WriteAllowedSigners(reportDir, wipConfig.Signing.TrustedKeys())
+	if err != nil {
+		return err
+	}
+	allowedSignersPath = path
+	return nil
+}
+
+// verifyWipRef checks that a WIP ref was signed by a trusted host. It returns
+// false when the ref must not be used (verify mode "require").
+func verifyWipRef(ctx context.Context, repo workspace.Repo, ref, branch string, entry *report.ReportEntry) bool {
+	mode := wipConfig.Signing.VerifyMode()
+	if mode == config.VerifyOff {
+		return true
+	}
+
+	verdict := signing.Verdict{Reason: "signature check failed"}
+	if sig, err := gitexec.CommitSignature(ctx, repo.Path, ref, allowedSignersPath); err == nil {
+		verdict = signing.Evaluate(sig, wipConfig.Signing.TrustedKeys())
+	}
+
+	if verdict.Trusted {
+		entry.Details += fmt.Sprintf(" (signed by %s)", verdict.Host)
+		return true
+	}
+
+	message := fmt.Sprintf("%s is %s", branch, verdict.Reason)
+	if mode == config.VerifyRequire {
+		entry.Outcome = "untrusted"
+		entry.AddError(message)
+		ui.Error(fmt.Sprintf("%s: refusing %s", repo.Name, message))
+		return false
+	}
+
+	entry.AddWarning(message)
+	ui.Warning(fmt.Sprintf("%s: %s", repo.Name, message))
+	return true
+}
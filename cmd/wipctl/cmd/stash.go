@@ -0,0 +1,339 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/gitexec"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/report"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/stashset"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/ui"
+)
+
+var stashCmd = &cobra.Command{
+	Use:   "stash",
+	Short: "Stash and restore local changes across the workspace as named sets",
+	Long: `Save the local changes of every dirty repository as one named stash set.
+
+'save' stashes tracked and untracked changes in each dirty repository with the
+message "` + stashset.Message("<name>") + `" and records which stash entry
+belongs to which repository in <report-dir>/` + stashset.FileName + `.
+Entries are tracked by stash commit, not by stash@{n} position, so 'apply' and
+'drop' find exactly the entries of the set even after other stashes were
+pushed or popped.
+
+Examples:
+  wipctl stash save before-upgrade    # Stash all dirty repositories
+  wipctl stash list                   # Show sets and whether their entries still exist
+  wipctl stash apply before-upgrade   # Re-apply the set, keeping the entries
+  wipctl stash drop before-upgrade    # Drop the set's entries and forget the set`,
+}
+
+var stashSaveCmd = &cobra.Command{
+	Use:   "save <name>",
+	Short: "Stash every dirty repository under a named set",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runStashSave,
+}
+
+var stashListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List stash sets",
+	Args:  cobra.NoArgs,
+	RunE:  runStashList,
+}
+
+var stashApplyCmd = &cobra.Command{
+	Use:   "apply <name>",
+	Short: "Apply a stash set's entries to their repositories",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runStashApply,
+}
+
+var stashDropCmd = &cobra.Command{
+	Use:   "drop <name>",
+	Short: "Drop a stash set's entries and forget the set",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runStashDrop,
+}
+
+func init() {
+	rootCmd.AddCommand(stashCmd)
+	stashCmd.AddCommand(stashSaveCmd)
+	stashCmd.AddCommand(stashListCmd)
+	stashCmd.AddCommand(stashApplyCmd)
+	stashCmd.AddCommand(stashDropCmd)
+}
+
+func stashContext() context.Context {
+	ctx := context.Background()
+	if dryRun {
+		ctx = context.WithValue(ctx, gitexec.DryRunKey, true)
+		ui.Info("🧪 DRY RUN MODE - No actual git operations will be performed")
+	}
+	return ctx
+}
+
+func runStashSave(cmd *cobra.Command, args []string) error {
+	ctx := stashContext()
+	name := args[0]
+	if err := stashset.ValidateName(name); err != nil {
+		return err
+	}
+
+	store := stashset.Open(reportDir)
+	if _, exists, err := store.Get(name); err != nil {
+		ui.Error("Failed to read stash sets: " + err.Error())
+		return err
+	} else if exists {
+		return fmt.Errorf("stash set %q already exists (apply or drop it first)", name)
+	}
+
+	repos, err := discoverRepos(ctx)
+	if err != nil {
+		return err
+	}
+
+	set := stashset.Set{Name: name, Created: time.Now(), Host: hostName}
+	rep := report.NewReport("WIP Stash Save Report", workspacePath, reportDir, "stash-save")
+
+	for _, repo := range repos {
+		dirty, err := gitexec.DirtyCount(ctx, repo.Path)
+		if err != nil || dirty == 0 {
+			continue
+		}
+
+		entry := report.ReportEntry{Repo: repo.Name}
+		branch, _ := gitexec.CurrentBranch(ctx, repo.Path)
+
+		commit, err := gitexec.StashPush(ctx, repo.Path, stashset.Message(name))
+		if err != nil {
+			entry.Outcome = "error"
+			entry.AddError(fmt.Sprintf("stash failed: %v", err))
+			ui.Error(fmt.Sprintf("%s: stash failed", repo.Name))
+			rep.AddEntry(entry)
+			continue
+		}
+		if commit == "" && !dryRun {
+			continue
+		}
+
+		path, absErr := filepath.Abs(repo.Path)
+		if absErr != nil {
+			path = repo.Path
+		}
+		set.Entries = append(set.Entries, stashset.Entry{
+			Repo:   repo.Name,
+			Path:   path,
+			Branch: branch,
+			Commit: commit,
+		})
+
+		entry.Outcome = "success"
+		entry.Details = fmt.Sprintf("%s → stash %s", branch, shortSHA(commit))
+		rep.AddEntry(entry)
+		ui.Success(fmt.Sprintf("%s: stashed %d changes on %s", repo.Name, dirty, branch))
+	}
+
+	if err := rep.Save(); err != nil {
+		ui.Warning("Failed to save report: " + err.Error())
+	}
+
+	if len(set.Entries) == 0 {
+		ui.Info("No dirty repositories to stash")
+		return nil
+	}
+
+	if dryRun {
+		fmt.Printf("[DRY RUN] Would record stash set %q with %d repositories in %s\n", name, len(set.Entries), store.Path())
+		return nil
+	}
+	if err := store.Put(set); err != nil {
+		ui.Error("Failed to record stash set: " + err.Error())
+		return err
+	}
+
+	ui.Success(fmt.Sprintf("Saved stash set %q across %d repositories", name, len(set.Entries)))
+	return nil
+}
+
+func runStashList(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	sets, err := stashset.Open(reportDir).List()
+	if err != nil {
+		ui.Error("Failed to read stash sets: " + err.Error())
+		return err
+	}
+	if len(sets) == 0 {
+		ui.Info("No stash sets")
+		return nil
+	}
+
+	ui.InitTable("Stash Set", "Created", "Host", "Repositories", "Missing")
+	for _, set := range sets {
+		missing := 0
+		for _, entry := range set.Entries {
+			if _, err := gitexec.StashRef(ctx, entry.Path, entry.Commit); err != nil {
+				missing++
+			}
+		}
+
+		ui.AddTableRow(
+			ui.CyberText(set.Name, "branch"),
+			set.Created.Format("2006-01-02 15:04:05"),
+			set.Host,
+			fmt.Sprintf("%d", len(set.Entries)),
+			fmt.Sprintf("%d", missing),
+		)
+	}
+	ui.RenderTable()
+	return nil
+}
+
+func runStashApply(cmd *cobra.Command, args []string) error {
+	ctx := stashContext()
+
+	set, err := loadStashSet(args[0])
+	if err != nil {
+		return err
+	}
+
+	rep := report.NewReport("WIP Stash Apply Report", workspacePath, reportDir, "stash-apply")
+	applied := 0
+	for _, stash := range set.Entries {
+		entry := applyStashEntry(ctx, stash)
+		if entry.Outcome == "success" {
+			applied++
+		}
+		rep.AddEntry(entry)
+	}
+
+	if err := rep.Save(); err != nil {
+		ui.Warning("Failed to save report: " + err.Error())
+	}
+
+	ui.Success(fmt.Sprintf("Applied %d of %d entries of stash set %q", applied, len(set.Entries), set.Name))
+	if applied > 0 {
+		ui.Info(fmt.Sprintf("Entries are kept; run 'wipctl stash drop %s' once you no longer need them", set.Name))
+	}
+	return nil
+}
+
+// applyStashEntry applies one repository's entry of a stash set by commit
+func applyStashEntry(ctx context.Context, stash stashset.Entry) report.ReportEntry {
+	entry := report.ReportEntry{Repo: stash.Repo, Details: fmt.Sprintf("stash %s", shortSHA(stash.Commit))}
+
+	if _, err := os.Stat(stash.Path); err != nil {
+		entry.Outcome = "error"
+		entry.AddError(fmt.Sprintf("repository %s not found", stash.Path))
+		ui.Error(fmt.Sprintf("%s: repository not found", stash.Repo))
+		return entry
+	}
+
+	if _, err := gitexec.StashRef(ctx, stash.Path, stash.Commit); err != nil {
+		entry.Outcome = "error"
+		entry.AddError(fmt.Sprintf("stash entry %s is no longer in the stash list", shortSHA(stash.Commit)))
+		ui.Error(fmt.Sprintf("%s: stash entry missing", stash.Repo))
+		return entry
+	}
+
+	if branch, _ := gitexec.CurrentBranch(ctx, stash.Path); branch != stash.Branch {
+		entry.AddWarning(fmt.Sprintf("stashed on %s, applying on %s", stash.Branch, branch))
+	}
+
+	if err := gitexec.StashApply(ctx, stash.Path, stash.Commit); err != nil {
+		if hasConflicts, files, _ := gitexec.HasConflicts(ctx, stash.Path); hasConflicts {
+			entry.Outcome = "conflicts"
+			entry.AddWarning(fmt.Sprintf("conflicts in files: %v", files))
+			ui.Warning(fmt.Sprintf("%s: stash applied with conflicts, run 'wipctl resolve'", stash.Repo))
+			return entry
+		}
+		entry.Outcome = "error"
+		entry.AddError(fmt.Sprintf("stash apply failed: %v", err))
+		ui.Error(fmt.Sprintf("%s: stash apply failed", stash.Repo))
+		return entry
+	}
+
+	entry.Outcome = "success"
+	ui.Success(fmt.Sprintf("%s: applied stash %s", stash.Repo, shortSHA(stash.Commit)))
+	return entry
+}
+
+func runStashDrop(cmd *cobra.Command, args []string) error {
+	ctx := stashContext()
+
+	set, err := loadStashSet(args[0])
+	if err != nil {
+		return err
+	}
+
+	rep := report.NewReport("WIP Stash Drop Report", workspacePath, reportDir, "stash-drop")
+	failed := 0
+	for _, stash := range set.Entries {
+		entry := report.ReportEntry{Repo: stash.Repo, Details: fmt.Sprintf("stash %s", shortSHA(stash.Commit))}
+
+		err := gitexec.StashDropCommit(ctx, stash.Path, stash.Commit)
+		switch {
+		case errors.Is(err, gitexec.ErrStashNotFound):
+			entry.Outcome = "skipped"
+			entry.AddWarning("stash entry was already removed")
+		case err != nil:
+			entry.Outcome = "error"
+			entry.AddError(fmt.Sprintf("stash drop failed: %v", err))
+			ui.Error(fmt.Sprintf("%s: stash drop failed", stash.Repo))
+			failed++
+		default:
+			entry.Outcome = "success"
+			ui.Success(fmt.Sprintf("%s: dropped stash %s", stash.Repo, shortSHA(stash.Commit)))
+		}
+		rep.AddEntry(entry)
+	}
+
+	if err := rep.Save(); err != nil {
+		ui.Warning("Failed to save report: " + err.Error())
+	}
+
+	// Keep the set while any of its entries could not be dropped, so the
+	// drop can be retried
+	if failed > 0 {
+		return fmt.Errorf("failed to drop %d entries of stash set %q", failed, set.Name)
+	}
+	if dryRun {
+		fmt.Printf("[DRY RUN] Would forget stash set %q\n", set.Name)
+		return nil
+	}
+	if err := stashset.Open(reportDir).Delete(set.Name); err != nil {
+		ui.Error("Failed to update stash sets: " + err.Error())
+		return err
+	}
+
+	ui.Success(fmt.Sprintf("Dropped stash set %q", set.Name))
+	return nil
+}
+
+// loadStashSet looks up a stash set by name
+func loadStashSet(name string) (stashset.Set, error) {
+	set, ok, err := stashset.Open(reportDir).Get(name)
+	if err != nil {
+		ui.Error("Failed to read stash sets: " + err.Error())
+		return set, err
+	}
+	if !ok {
+		return set, fmt.Errorf("no stash set named %q (see 'wipctl stash list')", name)
+	}
+	return set, nil
+}
+
+// shortSHA abbreviates a commit id for display
+func shortSHA(commit string) string {
+	if len(commit) > 8 {
+		return commit[:8]
+	}
+	return commit
+}
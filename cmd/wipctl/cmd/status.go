@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"context"
+	"path/filepath"
 
 	"github.com/spf13/cobra"
 	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/operations"
@@ -26,6 +27,7 @@ Displays:
 - Untracked files count
 - Commits ahead of origin
 - Commits behind origin
+- Pushes queued while offline (see 'wipctl flush')
 
 This command does not fetch from remotes to keep it fast.`,
 	RunE: runStatus,
@@ -55,9 +57,31 @@ func runStatus(cmd *cobra.Command, args []string) error {
 
 	// Create status handler with unified architecture
 	handler := operations.NewStatusHandler(statusWithAI, statusConcurrency)
+	handler.SetPendingPushes(pendingPushes(repos))
 
 	// Process workspace status using streamlined architecture
 	return handler.ProcessWorkspaceStatus(ctx, repos)
 }
 
+// pendingPushes counts offline-queued pushes per repository name
+func pendingPushes(repos []workspace.Repo) map[string]int {
+	counts, err := pushQueue.Pending()
+	if err != nil {
+		ui.Warning("Failed to read push queue: " + err.Error())
+		return nil
+	}
+
+	pending := make(map[string]int)
+	for _, repo := range repos {
+		path, err := filepath.Abs(repo.Path)
+		if err != nil {
+			continue
+		}
+		if n := counts[path]; n > 0 {
+			pending[repo.Name] = n
+		}
+	}
+	return pending
+}
+
 // All status display logic moved to operations/status.go for DRY architecture
\ No newline at end of file
@@ -0,0 +1,232 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/gitexec"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/report"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/ui"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/workspace"
+)
+
+var (
+	switchDefault     bool
+	switchStash       bool
+	switchConcurrency int
+)
+
+var switchCmd = &cobra.Command{
+	Use:   "switch <branch|--default>",
+	Short: "Switch every repository to a branch or to its default branch",
+	Long: `Switch all repositories in the workspace to the same branch.
+
+Repositories where the branch exists locally are switched to it; where it
+only exists on origin, a local branch tracking origin/<branch> is created.
+Repositories without the branch are skipped. With --default each repository
+is switched to its own default branch (origin/HEAD, then origin/main or
+origin/master).
+
+Repositories with uncommitted changes are skipped unless --stash is given,
+which stashes the changes, switches and re-applies them on the new branch.
+
+Examples:
+  wipctl switch feature/login    # Switch repos that have feature/login
+  wipctl switch --default        # Back to main/master everywhere
+  wipctl switch --default --stash
+  wipctl sync                    # Then fast-forward default branches`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if switchDefault {
+			return cobra.NoArgs(cmd, args)
+		}
+		if len(args) != 1 {
+			return errors.New("requires a branch name or --default")
+		}
+		return nil
+	},
+	RunE: runSwitch,
+}
+
+func init() {
+	rootCmd.AddCommand(switchCmd)
+	switchCmd.Flags().BoolVar(&switchDefault, "default", false, "switch each repository to its default branch")
+	switchCmd.Flags().BoolVar(&switchStash, "stash", false, "stash uncommitted changes and re-apply them after switching instead of skipping dirty repos")
+	switchCmd.Flags().IntVar(&switchConcurrency, "concurrency", 6, "number of concurrent repository operations")
+}
+
+func runSwitch(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	if dryRun {
+		ctx = context.WithValue(ctx, gitexec.DryRunKey, true)
+		ui.Info("🧪 DRY RUN MODE - No actual git operations will be performed")
+	}
+
+	target := ""
+	if len(args) == 1 {
+		target = args[0]
+	}
+
+	ui.Info("Discovering Git repositories...")
+	repos, err := workspace.Discover(ctx, workspacePath)
+	if err != nil {
+		ui.Error("Failed to discover repositories: " + err.Error())
+		return err
+	}
+	if len(repos) == 0 {
+		ui.Warning("No Git repositories found in workspace")
+		return nil
+	}
+
+	if target == "" {
+		ui.Info(fmt.Sprintf("Switching %d repositories to their default branch", len(repos)))
+	} else {
+		ui.Info(fmt.Sprintf("Switching %d repositories to %s", len(repos), target))
+	}
+
+	rep := report.NewReport("WIP Switch Report", workspacePath, reportDir, "switch")
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, switchConcurrency)
+
+	for _, repo := range repos {
+		wg.Add(1)
+		go func(repo workspace.Repo) {
+			defer wg.Done()
+
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			entry := switchRepo(ctx, repo, target)
+
+			mu.Lock()
+			rep.AddEntry(entry)
+			mu.Unlock()
+		}(repo)
+	}
+
+	wg.Wait()
+
+	if err := rep.Save(); err != nil {
+		ui.Warning("Failed to save report: " + err.Error())
+	}
+
+	ui.Success("Switch completed: " + summarizeOutcomes(rep.Entries))
+	return nil
+}
+
+// switchRepo switches one repository to branch, or to its default branch
+// when branch is empty
+func switchRepo(ctx context.Context, repo workspace.Repo, branch string) report.ReportEntry {
+	entry := report.ReportEntry{Repo: repo.Name}
+	slog.Info("Processing repository", "repo", repo.Path)
+
+	if op := gitexec.InProgressOperation(ctx, repo.Path); op != "" {
+		entry.Outcome = "skipped"
+		entry.AddWarning(op + " in progress")
+		ui.Warning(fmt.Sprintf("%s: %s in progress", repo.Name, op))
+		return entry
+	}
+
+	if branch == "" {
+		branch = gitexec.DefaultBranch(ctx, repo.Path, "origin")
+	}
+	current, _ := gitexec.CurrentBranch(ctx, repo.Path)
+	entry.Details = fmt.Sprintf("%s → %s", current, branch)
+
+	if current == branch {
+		entry.Outcome = "unchanged"
+		return entry
+	}
+
+	local := gitexec.ResolveRef(ctx, repo.Path, "refs/heads/"+branch) != ""
+	remote := gitexec.ResolveRef(ctx, repo.Path, "refs/remotes/origin/"+branch) != ""
+	if !local && !remote {
+		entry.Outcome = "skipped"
+		entry.AddWarning(fmt.Sprintf("no branch %s", branch))
+		return entry
+	}
+
+	dirty, err := gitexec.DirtyCount(ctx, repo.Path)
+	if err != nil {
+		entry.Outcome = "error"
+		entry.AddError(fmt.Sprintf("status check failed: %v", err))
+		return entry
+	}
+	if dirty > 0 && !switchStash {
+		entry.Outcome = "skipped"
+		entry.AddWarning(fmt.Sprintf("%d uncommitted changes (use --stash to carry them over)", dirty))
+		ui.Warning(fmt.Sprintf("%s: uncommitted changes, skipped", repo.Name))
+		return entry
+	}
+
+	stash := ""
+	if dirty > 0 {
+		stash, err = gitexec.StashPush(ctx, repo.Path, fmt.Sprintf("wipctl auto-stash before switch - %s", branch))
+		if err != nil {
+			entry.Outcome = "error"
+			entry.AddError(fmt.Sprintf("stash local changes failed: %v", err))
+			return entry
+		}
+	}
+
+	if local {
+		err = gitexec.Switch(ctx, repo.Path, branch)
+	} else {
+		err = gitexec.SwitchTrack(ctx, repo.Path, branch, "origin/"+branch)
+	}
+	if err != nil {
+		entry.Outcome = "error"
+		entry.AddError(fmt.Sprintf("switch to %s failed: %v", branch, err))
+		ui.Error(fmt.Sprintf("%s: switch to %s failed", repo.Name, branch))
+		// Put the changes back where they came from
+		if stash != "" {
+			if popErr := gitexec.StashPop(ctx, repo.Path, stash); popErr != nil {
+				entry.AddError(fmt.Sprintf("restoring stashed changes failed: %v (stash %s kept)", popErr, shortSHA(stash)))
+			}
+		}
+		return entry
+	}
+
+	if stash != "" {
+		if err := gitexec.StashPop(ctx, repo.Path, stash); err != nil {
+			slog.Debug("Stash pop failed", "repo", repo.Path, "stash", stash, "error", err)
+		}
+		if hasConflicts, files, _ := gitexec.HasConflicts(ctx, repo.Path); hasConflicts {
+			entry.Outcome = "conflicts"
+			entry.AddWarning(fmt.Sprintf("conflicts in files: %v", files))
+			ui.Warning(fmt.Sprintf("%s: stashed changes conflict on %s, run 'wipctl resolve'", repo.Name, branch))
+			return entry
+		}
+	}
+
+	entry.Outcome = "success"
+	ui.Success(fmt.Sprintf("%s: switched to %s", repo.Name, branch))
+	return entry
+}
+
+// summarizeOutcomes counts report entries by outcome, e.g. "3 success, 1 skipped"
+func summarizeOutcomes(entries []report.ReportEntry) string {
+	counts := make(map[string]int)
+	for _, entry := range entries {
+		counts[entry.Outcome]++
+	}
+
+	outcomes := make([]string, 0, len(counts))
+	for outcome := range counts {
+		outcomes = append(outcomes, outcome)
+	}
+	sort.Strings(outcomes)
+
+	parts := make([]string, 0, len(outcomes))
+	for _, outcome := range outcomes {
+		parts = append(parts, fmt.Sprintf("%d %s", counts[outcome], outcome))
+	}
+	return strings.Join(parts, ", ")
+}
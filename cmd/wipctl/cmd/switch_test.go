@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/workspace"
+)
+
+// pushToOrigin commits file on branch in a scratch clone and pushes it
+func pushToOrigin(t *testing.T, root, origin, branch, file, content string) {
+	t.Helper()
+	scratch := filepath.Join(t.TempDir(), "scratch")
+	git(t, root, "clone", "-q", origin, scratch)
+	if branch != "main" {
+		git(t, scratch, "switch", "-q", "-c", branch)
+	}
+	writeFile(t, filepath.Join(scratch, file), content)
+	git(t, scratch, "add", "-A")
+	git(t, scratch, "commit", "-q", "-m", "change "+file)
+	git(t, scratch, "push", "-q", "origin", branch)
+}
+
+func TestSwitchRepo(t *testing.T) {
+	root, origin := setupOrigin(t)
+	oldStash := switchStash
+	t.Cleanup(func() { switchStash = oldStash })
+	pushToOrigin(t, root, origin, "feature/login", "login.txt", "login\n")
+
+	ctx := context.Background()
+	repo := clone(t, root, origin, "api")
+	api := workspace.Repo{Name: "api", Path: repo}
+
+	if entry := switchRepo(ctx, api, "feature/nope"); entry.Outcome != "skipped" {
+		t.Errorf("missing branch outcome = %q, want skipped", entry.Outcome)
+	}
+
+	writeFile(t, filepath.Join(repo, "README.md"), "hello\nlocal\n")
+	switchStash = false
+	if entry := switchRepo(ctx, api, "feature/login"); entry.Outcome != "skipped" {
+		t.Errorf("dirty without --stash outcome = %q, want skipped", entry.Outcome)
+	}
+	if got := git(t, repo, "branch", "--show-current"); got != "main" {
+		t.Errorf("dirty repo switched to %q", got)
+	}
+
+	switchStash = true
+	if entry := switchRepo(ctx, api, "feature/login"); entry.Outcome != "success" {
+		t.Fatalf("--stash outcome = %q, want success (errors: %v)", entry.Outcome, entry.Errors)
+	}
+	if got := git(t, repo, "branch", "--show-current"); got != "feature/login" {
+		t.Errorf("current branch = %q, want feature/login", got)
+	}
+	if got := git(t, repo, "rev-parse", "--abbrev-ref", "@{upstream}"); got != "origin/feature/login" {
+		t.Errorf("upstream = %q, want origin/feature/login", got)
+	}
+	if content, _ := os.ReadFile(filepath.Join(repo, "README.md")); string(content) != "hello\nlocal\n" {
+		t.Errorf("README.md = %q, want the local change carried over", content)
+	}
+	if got := git(t, repo, "stash", "list"); got != "" {
+		t.Errorf("stash list = %q, want the auto-stash popped", got)
+	}
+
+	git(t, repo, "checkout", "-q", "--", "README.md")
+	if entry := switchRepo(ctx, api, ""); entry.Outcome != "success" {
+		t.Fatalf("--default outcome = %q, want success (errors: %v)", entry.Outcome, entry.Errors)
+	}
+	if got := git(t, repo, "branch", "--show-current"); got != "main" {
+		t.Errorf("current branch after --default = %q, want main", got)
+	}
+	if entry := switchRepo(ctx, api, ""); entry.Outcome != "unchanged" {
+		t.Errorf("second --default outcome = %q, want unchanged", entry.Outcome)
+	}
+}
+
+func TestSyncRepo(t *testing.T) {
+	tests := []struct {
+		name    string
+		setup   func(t *testing.T, repo string)
+		outcome string
+		synced  bool
+	}{
+		{name: "checked out and behind", outcome: "success", synced: true},
+		{
+			name:    "behind while on another branch",
+			setup:   func(t *testing.T, repo string) { git(t, repo, "switch", "-q", "-c", "feature") },
+			outcome: "success",
+			synced:  true,
+		},
+		{
+			name: "diverged",
+			setup: func(t *testing.T, repo string) {
+				writeFile(t, filepath.Join(repo, "local.txt"), "local\n")
+				git(t, repo, "add", "-A")
+				git(t, repo, "commit", "-q", "-m", "local")
+			},
+			outcome: "diverged",
+		},
+		{
+			name: "local changes kept",
+			setup: func(t *testing.T, repo string) {
+				writeFile(t, filepath.Join(repo, "README.md"), "hello\nlocal\n")
+			},
+			outcome: "success",
+			synced:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, origin := setupOrigin(t)
+			repo := clone(t, root, origin, "api")
+			if tt.setup != nil {
+				tt.setup(t, repo)
+			}
+			before := git(t, repo, "rev-parse", "main")
+			current := git(t, repo, "branch", "--show-current")
+			readme, _ := os.ReadFile(filepath.Join(repo, "README.md"))
+			pushToOrigin(t, root, origin, "main", "upstream.txt", "upstream\n")
+			upstream := git(t, origin, "rev-parse", "main")
+
+			entry := syncRepo(context.Background(), workspace.Repo{Name: "api", Path: repo})
+			if entry.Outcome != tt.outcome {
+				t.Fatalf("outcome = %q, want %q (warnings: %v, errors: %v)", entry.Outcome, tt.outcome, entry.Warnings, entry.Errors)
+			}
+
+			want := before
+			if tt.synced {
+				want = upstream
+			}
+			if got := git(t, repo, "rev-parse", "main"); got != want {
+				t.Errorf("main = %s, want %s", got, want)
+			}
+			if got := git(t, repo, "branch", "--show-current"); got != current {
+				t.Errorf("current branch = %q, want %q kept", got, current)
+			}
+			if got, _ := os.ReadFile(filepath.Join(repo, "README.md")); string(got) != string(readme) {
+				t.Errorf("README.md = %q, want %q left as it was", got, readme)
+			}
+			_, err := os.Stat(filepath.Join(repo, "upstream.txt"))
+			if onMain := current == "main" && tt.synced; onMain != (err == nil) {
+				t.Errorf("upstream.txt in worktree = %v, want %v", err == nil, onMain)
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/spf13/cobra"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/gitexec"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/report"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/ui"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/workspace"
+)
+
+var syncConcurrency int
+
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Fetch and fast-forward every repository's default branch",
+	Long: `Bring each repository's default branch up to date with origin.
+
+For each repository:
+1. Check preconditions (has origin, not in rebase/merge)
+2. Fetch origin
+3. Detect the default branch (origin/HEAD, then origin/main or origin/master)
+4. Fast-forward the local default branch to origin; when it is checked out
+   this is 'git merge --ff-only', otherwise only the branch ref moves
+
+Repositories whose default branch has diverged from origin, or has local
+commits origin does not have, are left untouched. The checked out branch is
+never changed; use 'wipctl switch --default' for that.`,
+	Args: cobra.NoArgs,
+	RunE: runSync,
+}
+
+func init() {
+	rootCmd.AddCommand(syncCmd)
+	syncCmd.Flags().IntVar(&syncConcurrency, "concurrency", 6, "number of concurrent repository operations")
+}
+
+func runSync(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	if dryRun {
+		ctx = context.WithValue(ctx, gitexec.DryRunKey, true)
+		ui.Info("🧪 DRY RUN MODE - No actual git operations will be performed")
+	}
+
+	ui.Info("Discovering Git repositories...")
+	repos, err := workspace.Discover(ctx, workspacePath)
+	if err != nil {
+		ui.Error("Failed to discover repositories: " + err.Error())
+		return err
+	}
+	if len(repos) == 0 {
+		ui.Warning("No Git repositories found in workspace")
+		return nil
+	}
+
+	ui.Info(fmt.Sprintf("Syncing default branches for %d repositories", len(repos)))
+
+	rep := report.NewReport("WIP Sync Report", workspacePath, reportDir, "sync")
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, syncConcurrency)
+
+	for _, repo := range repos {
+		wg.Add(1)
+		go func(repo workspace.Repo) {
+			defer wg.Done()
+
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			entry := syncRepo(ctx, repo)
+
+			mu.Lock()
+			rep.AddEntry(entry)
+			mu.Unlock()
+		}(repo)
+	}
+
+	wg.Wait()
+
+	if err := rep.Save(); err != nil {
+		ui.Warning("Failed to save report: " + err.Error())
+	}
+
+	ui.Success("Sync completed: " + summarizeOutcomes(rep.Entries))
+	return nil
+}
+
+// syncRepo fast-forwards one repository's default branch to origin
+func syncRepo(ctx context.Context, repo workspace.Repo) report.ReportEntry {
+	entry := report.ReportEntry{Repo: repo.Name}
+	slog.Info("Processing repository", "repo", repo.Path)
+
+	ok, reason := gitexec.Preconditions(ctx, repo.Path)
+	if !ok {
+		entry.Outcome = "skipped"
+		entry.AddWarning(reason)
+		ui.Warning(fmt.Sprintf("%s: %s", repo.Name, reason))
+		return entry
+	}
+
+	if err := gitexec.FetchRemote(ctx, repo.Path, "origin"); err != nil {
+		entry.Outcome = "error"
+		entry.AddError(fmt.Sprintf("fetch failed: %v", err))
+		ui.Error(fmt.Sprintf("%s: fetch failed", repo.Name))
+		return entry
+	}
+
+	branch := gitexec.DefaultBranch(ctx, repo.Path, "origin")
+	entry.Details = fmt.Sprintf("%s ← origin/%s", branch, branch)
+
+	upstream := gitexec.ResolveRef(ctx, repo.Path, "refs/remotes/origin/"+branch)
+	if upstream == "" {
+		entry.Outcome = "skipped"
+		entry.AddWarning(fmt.Sprintf("origin has no branch %s", branch))
+		return entry
+	}
+	local := gitexec.ResolveRef(ctx, repo.Path, "refs/heads/"+branch)
+	if local == "" {
+		entry.Outcome = "skipped"
+		entry.AddWarning(fmt.Sprintf("no local branch %s (use 'wipctl switch --default' to create it)", branch))
+		return entry
+	}
+
+	switch {
+	case local == upstream:
+		entry.Outcome = "up-to-date"
+		return entry
+	case gitexec.IsAncestor(ctx, repo.Path, upstream, local):
+		entry.Outcome = "up-to-date"
+		entry.AddWarning(fmt.Sprintf("local %s has commits origin does not have", branch))
+		return entry
+	case !gitexec.IsAncestor(ctx, repo.Path, local, upstream):
+		entry.Outcome = "diverged"
+		entry.AddWarning(fmt.Sprintf("%s and origin/%s have diverged - not touched", branch, branch))
+		ui.Warning(fmt.Sprintf("%s: %s has diverged from origin, skipped", repo.Name, branch))
+		return entry
+	}
+
+	current, _ := gitexec.CurrentBranch(ctx, repo.Path)
+	var err error
+	if current == branch {
+		err = gitexec.MergeFastForward(ctx, repo.Path, upstream)
+	} else {
+		err = gitexec.FastForwardBranch(ctx, repo.Path, branch, upstream, local)
+	}
+	if err != nil {
+		entry.Outcome = "error"
+		entry.AddError(fmt.Sprintf("fast-forward failed: %v", err))
+		ui.Error(fmt.Sprintf("%s: fast-forward of %s failed", repo.Name, branch))
+		return entry
+	}
+
+	entry.Outcome = "success"
+	entry.Details = fmt.Sprintf("%s %s..%s", branch, shortSHA(local), shortSHA(upstream))
+	ui.Success(fmt.Sprintf("%s: fast-forwarded %s", repo.Name, branch))
+	return entry
+}
@@ -0,0 +1,366 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/ai"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/gitexec"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/ui"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/watch"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/workspace"
+)
+
+var (
+	watchIdle        time.Duration
+	watchMaxInterval time.Duration
+	watchPoll        time.Duration
+	watchDetach      bool
+	watchMessage     string
+	watchRolling     bool
+)
+
+// rediscoverEvery controls how often the daemon re-walks the workspace for new repos
+const rediscoverEvery = 10 * time.Minute
+
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Background daemon that auto-checkpoints idle repositories",
+	Long: `Monitor every repository in the workspace and checkpoint it automatically.
+
+The daemon polls each repository's worktree. A repository with uncommitted
+changes is checkpointed once it has been idle (no further edits) for --idle,
+or once --max-interval has passed since its first unsaved change, whichever
+comes first. Checkpoints use the same workflow, logs and reports as
+'wipctl checkpoint'.
+
+State is kept in the report directory:
+  watch.pid          PID of the running daemon
+  watch-state.json   per-repo pending/checkpoint timeline
+  watch.log          daemon output when started with --detach
+
+Examples:
+  wipctl watch                         # Run in the foreground
+  wipctl watch --detach --idle 10m     # Run in the background
+  wipctl watch status                  # Show daemon and repo state
+  wipctl watch stop                    # Stop the background daemon`,
+	RunE: runWatch,
+}
+
+var watchStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show whether the watch daemon is running and what it is tracking",
+	RunE:  runWatchStatus,
+}
+
+var watchStopCmd = &cobra.Command{
+	Use:   "stop",
+	Short: "Stop the running watch daemon",
+	RunE:  runWatchStop,
+}
+
+func init() {
+	rootCmd.AddCommand(watchCmd)
+	watchCmd.AddCommand(watchStatusCmd)
+	watchCmd.AddCommand(watchStopCmd)
+
+	watchCmd.Flags().DurationVar(&watchIdle, "idle", 5*time.Minute, "checkpoint a repo after it has been idle this long")
+	watchCmd.Flags().DurationVar(&watchMaxInterval, "max-interval", time.Hour, "checkpoint a repo at least this often while it keeps changing")
+	watchCmd.Flags().DurationVar(&watchPoll, "poll", 30*time.Second, "how often to poll repositories for changes")
+	watchCmd.Flags().BoolVar(&watchDetach, "detach", false, "run the daemon in the background")
+	watchCmd.Flags().StringVar(&watchMessage, "message", "auto", "custom message prefix for automatic checkpoints")
+	watchCmd.Flags().BoolVar(&watchRolling, "rolling", false, "add automatic checkpoints to one rolling WIP ref per branch")
+}
+
+func runWatch(cmd *cobra.Command, args []string) error {
+	if watchDetach {
+		if pid, err := watch.ReadPID(reportDir); err == nil && processAlive(pid) {
+			ui.Warning(fmt.Sprintf("watch daemon already running (pid %d)", pid))
+			return watch.ErrRunning
+		}
+		return detachWatch()
+	}
+
+	// A detached daemon has its own session, so SIGHUP only arrives when the
+	// terminal of a foreground one closes
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+	defer stop()
+
+	if dryRun {
+		ctx = context.WithValue(ctx, gitexec.DryRunKey, true)
+		ui.Info("🧪 DRY RUN MODE - No actual git operations will be performed")
+	}
+
+	ctx = withSigning(ctx)
+
+	pid := os.Getpid()
+	if err := watch.ClaimPID(reportDir, pid, processAlive); err != nil {
+		if errors.Is(err, watch.ErrRunning) {
+			ui.Warning(err.Error())
+		} else {
+			ui.Error("Failed to write pid file: " + err.Error())
+		}
+		return err
+	}
+	defer watch.RemovePID(reportDir, pid)
+
+	checkpointMessage = watchMessage
+	checkpointRolling = watchRolling
+
+	generator := ai.NewGenerator(ai.LoadConfigFromEnv())
+	tracker := watch.NewTracker(watchIdle, watchMaxInterval)
+	state := watch.State{
+		PID:         pid,
+		Workspace:   workspacePath,
+		Started:     time.Now(),
+		Idle:        watchIdle.String(),
+		MaxInterval: watchMaxInterval.String(),
+	}
+
+	ui.Info(fmt.Sprintf("👁  Watching %s (idle=%s, max-interval=%s, poll=%s)", workspacePath, watchIdle, watchMaxInterval, watchPoll))
+	slog.Info("watch daemon started", "pid", pid, "workspace", workspacePath)
+
+	var repos []workspace.Repo
+	var discovered time.Time
+
+	ticker := time.NewTicker(watchPoll)
+	defer ticker.Stop()
+
+	for {
+		now := time.Now()
+		if repos == nil || now.Sub(discovered) >= rediscoverEvery {
+			found, err := workspace.Discover(ctx, workspacePath)
+			if err != nil {
+				slog.Error("workspace discovery failed", "error", err)
+			} else {
+				repos = found
+				discovered = now
+			}
+		}
+
+		pollWatchedRepos(ctx, repos, tracker, generator)
+
+		state.LastPoll = time.Now()
+		state.Repos = tracker.Snapshot()
+		if err := watch.SaveState(reportDir, state); err != nil {
+			slog.Warn("failed to save watch state", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			ui.Info("Watch daemon stopping")
+			slog.Info("watch daemon stopped", "pid", pid)
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// pollWatchedRepos observes every repository and checkpoints those that are due
+func pollWatchedRepos(ctx context.Context, repos []workspace.Repo, tracker *watch.Tracker, generator ai.Generator) {
+	results := make(map[string]*gitexec.RepoStatus)
+	keep := make(map[string]bool)
+
+	for _, repo := range repos {
+		if ctx.Err() != nil {
+			return
+		}
+		keep[repo.Path] = true
+
+		status, err := gitexec.Status(ctx, repo.Path)
+		if err != nil || status.Error != "" || !status.HasOrigin || status.InProgress {
+			tracker.Observe(repo.Path, repo.Name, "", false, time.Now())
+			continue
+		}
+		results[repo.Path] = status
+
+		hasChanges := status.Dirty > 0 || status.Untracked > 0
+		signature := ""
+		if hasChanges {
+			signature, err = gitexec.WorktreeSignature(ctx, repo.Path)
+			if err != nil {
+				slog.Warn("worktree signature failed", "repo", repo.Path, "error", err)
+				continue
+			}
+		}
+		tracker.Observe(repo.Path, repo.Name, signature, hasChanges, time.Now())
+	}
+	tracker.Forget(keep)
+
+	due := tracker.Due(time.Now())
+	if len(due) == 0 {
+		return
+	}
+
+	slog.Info("auto checkpoint triggered", "repos", due)
+	rep, err := checkpointRepositories(ctx, "Auto Checkpoint", due, results, len(repos), generator)
+	if err != nil {
+		// Back off until the next change or max interval instead of retrying every poll
+		slog.Error("auto checkpoint aborted", "error", err)
+		for _, path := range due {
+			tracker.MarkCheckpointed(path, "hook-failed", time.Now())
+		}
+		return
+	}
+
+	// Keyed by path: repositories in different directories may share a name
+	outcomes := make(map[string]string)
+	for _, entry := range rep.Entries {
+		outcomes[entry.Path] = entry.Outcome
+	}
+	for _, path := range due {
+		outcome := outcomes[path]
+		slog.Info("auto checkpoint finished", "repo", path, "outcome", outcome)
+		tracker.MarkCheckpointed(path, outcome, time.Now())
+	}
+}
+
+// detachWatch re-executes wipctl in the background with output sent to the watch log
+func detachWatch() error {
+	if err := os.MkdirAll(reportDir, 0755); err != nil {
+		return fmt.Errorf("create report directory: %w", err)
+	}
+
+	logPath := filepath.Join(reportDir, watch.LogFileName)
+	logFile, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		ui.Error("Failed to open watch log: " + err.Error())
+		return err
+	}
+	defer logFile.Close()
+
+	var args []string
+	for _, arg := range os.Args[1:] {
+		if arg == "--detach" || strings.HasPrefix(arg, "--detach=") {
+			continue
+		}
+		args = append(args, arg)
+	}
+
+	child := exec.Command(os.Args[0], args...)
+	child.Stdout = logFile
+	child.Stderr = logFile
+	child.SysProcAttr = detachedProcAttr()
+	if err := child.Start(); err != nil {
+		ui.Error("Failed to start watch daemon: " + err.Error())
+		return err
+	}
+
+	// Claim the PID file for the child before reporting success, so a second
+	// concurrent --detach loses here instead of leaving two daemons running
+	if err := watch.ClaimPID(reportDir, child.Process.Pid, processAlive); err != nil {
+		child.Process.Kill() //nolint:errcheck // the child never got to run
+		child.Wait()         //nolint:errcheck
+		if errors.Is(err, watch.ErrRunning) {
+			ui.Warning(err.Error())
+		} else {
+			ui.Error("Failed to write pid file: " + err.Error())
+		}
+		return err
+	}
+
+	ui.Success(fmt.Sprintf("Watch daemon started (pid %d), logging to %s", child.Process.Pid, logPath))
+	return child.Process.Release()
+}
+
+func runWatchStatus(cmd *cobra.Command, args []string) error {
+	pid, err := watch.ReadPID(reportDir)
+	running := err == nil && processAlive(pid)
+
+	if running {
+		ui.Success(fmt.Sprintf("Watch daemon running (pid %d)", pid))
+	} else {
+		ui.Warning("Watch daemon is not running")
+	}
+
+	state, err := watch.LoadState(reportDir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		ui.Error("Failed to read watch state: " + err.Error())
+		return err
+	}
+
+	ui.Info(fmt.Sprintf("Workspace: %s | idle=%s max-interval=%s | last poll %s",
+		state.Workspace, state.Idle, state.MaxInterval, formatAge(state.LastPoll)))
+
+	ui.InitTable("Repository", "Pending", "Last Change", "Last Checkpoint", "Outcome")
+	for _, repo := range state.Repos {
+		pending := "-"
+		if repo.Pending {
+			pending = "since " + formatAge(repo.PendingSince)
+		}
+		ui.AddTableRow(
+			ui.CyberText(repo.Name, "repo"),
+			pending,
+			formatOptionalAge(repo.LastChange),
+			formatOptionalAge(repo.LastCheckpoint),
+			orDash(repo.LastOutcome),
+		)
+	}
+	ui.RenderTable()
+
+	return nil
+}
+
+func runWatchStop(cmd *cobra.Command, args []string) error {
+	pid, err := watch.ReadPID(reportDir)
+	if err != nil || !processAlive(pid) {
+		ui.Warning("Watch daemon is not running")
+		return nil
+	}
+
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	if err := process.Signal(syscall.SIGTERM); err != nil {
+		ui.Error(fmt.Sprintf("Failed to stop watch daemon (pid %d): %v", pid, err))
+		return err
+	}
+
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		if !processAlive(pid) {
+			ui.Success(fmt.Sprintf("Watch daemon stopped (pid %d)", pid))
+			return nil
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+
+	ui.Warning(fmt.Sprintf("Watch daemon (pid %d) did not exit yet - a checkpoint may still be running", pid))
+	return nil
+}
+
+func processAlive(pid int) bool {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	return process.Signal(syscall.Signal(0)) == nil
+}
+
+func formatOptionalAge(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return formatAge(t)
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
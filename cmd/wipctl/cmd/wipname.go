@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/user"
+	"sort"
+	"time"
+
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/gitexec"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/wipname"
+)
+
+// wipNames renders and parses WIP branch names; set from config in initConfig
+var wipNames = wipname.Default()
+
+// wipRef is a WIP branch found in a repository
+type wipRef struct {
+	gitexec.Ref
+	Branch string // branch name without refs/heads/ or refs/remotes/<remote>/
+	Fields wipname.Fields
+}
+
+// renderWipBranch builds a WIP branch name for the current host and user
+func renderWipBranch(branch, feature string, ts time.Time) string {
+	return wipNames.Render(wipname.Fields{
+		User:    wipUser(),
+		Host:    hostName,
+		Feature: feature,
+		Branch:  branch,
+		Time:    ts,
+	})
+}
+
+func wipUser() string {
+	if name := wipConfig.Naming.UserName(); name != "" {
+		return name
+	}
+	if current, err := user.Current(); err == nil && current.Username != "" {
+		return current.Username
+	}
+	return os.Getenv("USER")
+}
+
+// listWipRefs returns the refs under refs/heads/ or refs/remotes/<remote>/
+// whose names parse as WIP branches, newest commit first
+func listWipRefs(ctx context.Context, repoPath, remote string) ([]wipRef, error) {
+	base := "refs/heads/"
+	if remote != "" {
+		base = "refs/remotes/" + remote + "/"
+	}
+
+	var patterns []string
+	for _, prefix := range wipNames.Prefixes() {
+		patterns = append(patterns, base+prefix)
+	}
+
+	refs, err := gitexec.ListRefs(ctx, repoPath, patterns...)
+	if err != nil {
+		return nil, err
+	}
+
+	var wips []wipRef
+	for _, ref := range refs {
+		branch := ref.Name[len(base):]
+		fields, ok := wipNames.Parse(branch)
+		if !ok {
+			continue
+		}
+		wips = append(wips, wipRef{Ref: ref, Branch: branch, Fields: fields})
+	}
+
+	sort.SliceStable(wips, func(i, j int) bool {
+		if !wips[i].Time.Equal(wips[j].Time) {
+			return wips[i].Time.After(wips[j].Time)
+		}
+		return wips[i].Fields.Time.After(wips[j].Fields.Time)
+	})
+	return wips, nil
+}
+
+// latestWipRef returns the most recently committed WIP branch of a remote
+func latestWipRef(ctx context.Context, repoPath, remote string) (wipRef, error) {
+	wips, err := listWipRefs(ctx, repoPath, remote)
+	if err != nil {
+		return wipRef{}, err
+	}
+	if len(wips) == 0 {
+		return wipRef{}, fmt.Errorf("no WIP branches found")
+	}
+	return wips[0], nil
+}
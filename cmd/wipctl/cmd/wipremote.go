@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/gitexec"
+)
+
+// ensureWipRemote returns the remote WIP branches are pushed to and pulled from.
+// Without a wip_remote config this is origin. Otherwise the mirror remote is
+// added on first use, creating its bare repository when the root is local.
+// A remote that already points somewhere else is treated as user-managed.
+func ensureWipRemote(ctx context.Context, repoPath, repoName string) (string, error) {
+	cfg := wipConfig.WipRemote
+	remote := cfg.RemoteName()
+	if cfg == nil {
+		return remote, nil
+	}
+
+	url := cfg.URLFor(mirrorPath(repoPath, repoName))
+	existing, err := gitexec.RemoteURL(ctx, repoPath, remote)
+	if err == nil && existing != url {
+		return remote, nil
+	}
+	if url == "" {
+		return "", fmt.Errorf("wip remote %q is not set up and no root is configured", remote)
+	}
+
+	if cfg.IsLocal() {
+		if _, statErr := os.Stat(url); errors.Is(statErr, os.ErrNotExist) {
+			if err := gitexec.InitBare(ctx, url); err != nil {
+				return "", fmt.Errorf("create WIP mirror %s: %w", url, err)
+			}
+			slog.Info("Created WIP mirror", "repo", repoPath, "path", url)
+		}
+	}
+
+	if err != nil {
+		if err := gitexec.AddRemote(ctx, repoPath, remote, url); err != nil {
+			return "", fmt.Errorf("add remote %s: %w", remote, err)
+		}
+		slog.Info("Added WIP remote", "repo", repoPath, "remote", remote, "url", url)
+	}
+
+	return remote, nil
+}
+
+// mirrorPath is where a repository's mirror lives under the wip_remote root:
+// its path inside the workspace, so a/api and b/api do not share a mirror.
+// A repository outside the workspace, or the workspace itself, falls back to
+// its name.
+func mirrorPath(repoPath, repoName string) string {
+	rel := workspaceRelPath(repoPath)
+	if rel == "." || rel == ".." || strings.HasPrefix(rel, "../") {
+		return repoName
+	}
+	return rel
+}
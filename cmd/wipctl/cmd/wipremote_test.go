@@ -0,0 +1,205 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/ai"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/config"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/queue"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/workspace"
+)
+
+// git runs a git command in dir and fails the test on error
+func git(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// setupOrigin creates a bare origin with one commit on main, isolates git
+// from the user's config and restores the command globals after the test
+func setupOrigin(t *testing.T) (root, origin string) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	t.Setenv("GIT_AUTHOR_NAME", "wipctl")
+	t.Setenv("GIT_AUTHOR_EMAIL", "wipctl@localhost")
+	t.Setenv("GIT_COMMITTER_NAME", "wipctl")
+	t.Setenv("GIT_COMMITTER_EMAIL", "wipctl@localhost")
+
+	root = t.TempDir()
+	origin = filepath.Join(root, "origin.git")
+	git(t, root, "init", "-q", "--bare", "-b", "main", origin)
+
+	seed := filepath.Join(root, "seed")
+	git(t, root, "clone", "-q", origin, seed)
+	writeFile(t, filepath.Join(seed, "README.md"), "hello\n")
+	git(t, seed, "add", "-A")
+	git(t, seed, "commit", "-q", "-m", "initial")
+	git(t, seed, "push", "-q", "origin", "HEAD:main")
+
+	oldConfig, oldAutoAdd, oldQueue, oldHost, oldHooks := wipConfig, autoAdd, pushQueue, hostName, hookRunner
+	oldWorkspace, oldReports := workspacePath, reportDir
+	t.Cleanup(func() {
+		wipConfig, autoAdd, pushQueue, hostName, hookRunner = oldConfig, oldAutoAdd, oldQueue, oldHost, oldHooks
+		workspacePath, reportDir = oldWorkspace, oldReports
+	})
+	wipConfig = &config.Config{}
+	autoAdd = true
+	reportDir = filepath.Join(root, "reports")
+	pushQueue = queue.Open(reportDir)
+	hostName = "laptop"
+	hookRunner = nil
+
+	return root, origin
+}
+
+// clone clones origin to root/<dir> and returns the clone's path
+func clone(t *testing.T, root, origin, dir string) string {
+	t.Helper()
+	path := filepath.Join(root, dir)
+	git(t, root, "clone", "-q", origin, path)
+	return path
+}
+
+// setupWipRemote is setupOrigin with a wip_remote root for mirrors
+func setupWipRemote(t *testing.T) (root, origin, mirrors string) {
+	t.Helper()
+	root, origin = setupOrigin(t)
+	mirrors = filepath.Join(root, "mirrors")
+	wipConfig = &config.Config{WipRemote: &config.WipRemote{Root: mirrors}}
+	return root, origin, mirrors
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+}
+
+func TestWipRemotePushAndPull(t *testing.T) {
+	root, origin, mirrors := setupWipRemote(t)
+	ctx := context.Background()
+
+	laptop := filepath.Join(root, "laptop", "api")
+	git(t, root, "clone", "-q", origin, laptop)
+	writeFile(t, filepath.Join(laptop, "README.md"), "hello\nwork in progress\n")
+	writeFile(t, filepath.Join(laptop, "notes.txt"), "todo\n")
+
+	workspacePath = filepath.Join(root, "laptop")
+	const wipBranch = "wip/laptop/20260101-120000"
+	entry := processRepoPush(ctx, workspace.Repo{Name: "api", Path: laptop}, &ai.NoneGenerator{}, wipBranch)
+	if entry.Outcome != "success" {
+		t.Fatalf("push outcome = %q, want success (errors: %v, warnings: %v)", entry.Outcome, entry.Errors, entry.Warnings)
+	}
+
+	mirror := filepath.Join(mirrors, "api.git")
+	if _, err := os.Stat(mirror); err != nil {
+		t.Fatalf("WIP mirror was not created: %v", err)
+	}
+	if got := git(t, mirror, "branch", "--list", wipBranch); !strings.Contains(got, wipBranch) {
+		t.Errorf("mirror branches = %q, want %s", got, wipBranch)
+	}
+	if got := git(t, origin, "branch", "--list", "wip/*"); got != "" {
+		t.Errorf("origin got WIP branches %q, want none", got)
+	}
+	if got := git(t, laptop, "remote", "get-url", "wip"); got != mirror {
+		t.Errorf("wip remote url = %q, want %q", got, mirror)
+	}
+
+	desktop := filepath.Join(root, "desktop", "api")
+	git(t, root, "clone", "-q", origin, desktop)
+
+	workspacePath = filepath.Join(root, "desktop")
+	entry = processRepoPull(ctx, workspace.Repo{Name: "api", Path: desktop})
+	if entry.Outcome != "success" {
+		t.Fatalf("pull outcome = %q, want success (errors: %v, warnings: %v)", entry.Outcome, entry.Errors, entry.Warnings)
+	}
+	if got := git(t, desktop, "branch", "--show-current"); got != wipBranch {
+		t.Errorf("current branch after pull = %q, want %q", got, wipBranch)
+	}
+	content, err := os.ReadFile(filepath.Join(desktop, "notes.txt"))
+	if err != nil || string(content) != "todo\n" {
+		t.Errorf("notes.txt after pull = %q (%v), want %q", content, err, "todo\n")
+	}
+}
+
+func TestWipRemotePullKeepsLocalChanges(t *testing.T) {
+	root, origin, _ := setupWipRemote(t)
+	ctx := context.Background()
+
+	laptop := filepath.Join(root, "laptop", "api")
+	git(t, root, "clone", "-q", origin, laptop)
+	writeFile(t, filepath.Join(laptop, "notes.txt"), "todo\n")
+	entry := processRepoPush(ctx, workspace.Repo{Name: "api", Path: laptop}, &ai.NoneGenerator{}, "wip/laptop/20260101-120000")
+	if entry.Outcome != "success" {
+		t.Fatalf("push outcome = %q, want success (errors: %v)", entry.Outcome, entry.Errors)
+	}
+
+	desktop := filepath.Join(root, "desktop", "api")
+	git(t, root, "clone", "-q", origin, desktop)
+	writeFile(t, filepath.Join(desktop, "scratch.txt"), "local\n")
+
+	entry = processRepoPull(ctx, workspace.Repo{Name: "api", Path: desktop})
+	if entry.Outcome != "success" {
+		t.Fatalf("pull outcome = %q, want success (errors: %v, warnings: %v)", entry.Outcome, entry.Errors, entry.Warnings)
+	}
+	if content, err := os.ReadFile(filepath.Join(desktop, "scratch.txt")); err != nil || string(content) != "local\n" {
+		t.Errorf("scratch.txt after pull = %q (%v), want the local change restored", content, err)
+	}
+	if got := git(t, desktop, "stash", "list"); got != "" {
+		t.Errorf("stash list after pull = %q, want the auto-stash popped", got)
+	}
+}
+
+func TestWipRemoteKeepsSameNamedReposApart(t *testing.T) {
+	root, origin, mirrors := setupWipRemote(t)
+	workspacePath = filepath.Join(root, "ws")
+	ctx := context.Background()
+
+	for _, dir := range []string{"a", "b"} {
+		repo := filepath.Join(workspacePath, dir, "api")
+		git(t, root, "clone", "-q", origin, repo)
+		if _, err := ensureWipRemote(ctx, repo, "api"); err != nil {
+			t.Fatalf("ensureWipRemote %s: %v", dir, err)
+		}
+		want := filepath.Join(mirrors, dir, "api.git")
+		if got := git(t, repo, "remote", "get-url", "wip"); got != want {
+			t.Errorf("%s/api wip remote = %q, want %q", dir, got, want)
+		}
+		if _, err := os.Stat(want); err != nil {
+			t.Errorf("%s/api mirror was not created: %v", dir, err)
+		}
+	}
+}
+
+func TestEnsureWipRemoteDefaultsToOrigin(t *testing.T) {
+	root, origin, _ := setupWipRemote(t)
+	wipConfig = &config.Config{}
+
+	repo := filepath.Join(root, "api")
+	git(t, root, "clone", "-q", origin, repo)
+
+	remote, err := ensureWipRemote(context.Background(), repo, "api")
+	if err != nil {
+		t.Fatalf("ensureWipRemote: %v", err)
+	}
+	if remote != "origin" {
+		t.Errorf("remote = %q, want origin", remote)
+	}
+}
@@ -8,6 +8,7 @@ require (
 	github.com/fatih/color v1.16.0
 	github.com/pterm/pterm v0.12.81
 	github.com/spf13/cobra v1.8.0
+	golang.org/x/sys v0.36.0
 )
 
 require (
@@ -24,7 +25,6 @@ require (
 	github.com/rivo/uniseg v0.4.7 // indirect
 	github.com/spf13/pflag v1.0.5 // indirect
 	github.com/xo/terminfo v0.0.0-20220910002029-abceb7e1c41e // indirect
-	golang.org/x/sys v0.36.0 // indirect
 	golang.org/x/term v0.35.0 // indirect
 	golang.org/x/text v0.26.0 // indirect
 )
@@ -4,12 +4,18 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/exec"
 	"strings"
+	"sync/atomic"
 	"time"
+
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/aicache"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/config"
 )
 
 type CommitMsgInput struct {
@@ -20,13 +26,28 @@ type CommitMsgInput struct {
 	DiffStat      string   `json:"diff_stat"`
 	Untracked     []string `json:"untracked"`
 	PriorSubjects []string `json:"prior_subjects"`
+	Diff          string   `json:"diff,omitempty"`         // staged hunks selected by DiffBudget
+	DiffOmitted   []string `json:"diff_omitted,omitempty"` // files whose hunks were left out, with the reason
 }
 
 type Generator interface {
 	CommitMessage(ctx context.Context, input CommitMsgInput) (string, error)
 	Synopsis(ctx context.Context, input SynopsisInput) (string, error)
 	PRReview(ctx context.Context, input PRReviewInput) (string, error)
+	PRDescription(ctx context.Context, input PRReviewInput) (string, error)
 	WorkspaceContext(ctx context.Context, input WorkspaceContextInput) (string, error)
+	ProposeMerge(ctx context.Context, input MergeInput) (string, error)
+}
+
+// MergeInput describes one conflicted file for an AI-proposed resolution
+type MergeInput struct {
+	Repo        string `json:"repo"`
+	Path        string `json:"path"`
+	Base        string `json:"base"`
+	Ours        string `json:"ours"`
+	Theirs      string `json:"theirs"`
+	OursLabel   string `json:"ours_label"`
+	TheirsLabel string `json:"theirs_label"`
 }
 
 type SynopsisInput struct {
@@ -79,22 +100,96 @@ type WorkspaceRepo struct {
 }
 
 type Config struct {
-	Provider    string
+	Provider    string // one provider or a fallback chain, e.g. "claude,openai,ollama,none"
 	Endpoint    string
 	Model       string
 	Token       string
 	ExecPath    string
 	MaxTokens   int
 	Temperature float64
+	ContextSize int // model context window in tokens; 0 looks it up by model
+	DiffTokens  int // cap on staged diff content in commit prompts; 0 is the default, negative disables
+
+	ExecProtocol int           // 1 runs the plugin per request, 2 keeps it running (JSON-RPC)
+	ExecTimeout  time.Duration // per plugin request; 0 is DefaultExecTimeout
+
+	Cache        *aicache.Cache // response cache; nil disables caching
+	CacheRefresh bool           // skip cached responses but store fresh ones (--no-cache)
+
+	// Providers holds per-provider settings (WIPCTL_AI_<NAME>_*), which win
+	// over Endpoint, Model and Token; those only apply to the first provider
+	Providers map[string]ProviderSettings
+
+	Redaction  *config.Redaction // rules applied to every request
+	ShowPrompt bool              // print requests instead of sending them (--show-prompt)
+}
+
+// ProviderSettings are the connection settings of one provider in a chain
+type ProviderSettings struct {
+	Endpoint string
+	Model    string
+	Token    string
+}
+
+// chainProviders are the providers that take per-provider settings
+var chainProviders = []string{"claude", "openai", "ollama"}
+
+// providerChain expands the Provider setting into one Config per provider
+func (c Config) providerChain() []Config {
+	var chain []Config
+	for i, name := range ProviderNames(c.Provider) {
+		link := c
+		link.Provider = name
+		if i > 0 {
+			link.Endpoint, link.Model, link.Token = "", "", ""
+		}
+
+		key := name
+		if key == "anthropic" {
+			key = "claude"
+		}
+		if settings, ok := c.Providers[key]; ok {
+			if settings.Endpoint != "" {
+				link.Endpoint = settings.Endpoint
+			}
+			if settings.Model != "" {
+				link.Model = settings.Model
+			}
+			if settings.Token != "" {
+				link.Token = settings.Token
+			}
+		}
+		chain = append(chain, link)
+	}
+	return chain
 }
 
+// NewGenerator builds the generator for config: the provider chain with
+// retries and circuit breakers, behind the response cache when one is set
 func NewGenerator(config Config) Generator {
+	names := ProviderNames(config.Provider)
+	if len(names) == 0 || (len(names) == 1 && names[0] == "none") {
+		return &NoneGenerator{}
+	}
+
+	generator := newChainGenerator(config)
+	if config.Cache != nil {
+		return newCachingGenerator(generator, config)
+	}
+	return generator
+}
+
+func newProviderGenerator(config Config) Generator {
 	switch config.Provider {
 	case "exec":
-		return &ExecGenerator{execPath: config.ExecPath}
+		return newExecGenerator(config)
 	case "openai":
+		endpoint := config.Endpoint
+		if endpoint == "" {
+			endpoint = "https://api.openai.com"
+		}
 		return &OpenAIGenerator{
-			endpoint:    config.Endpoint,
+			endpoint:    endpoint,
 			model:       config.Model,
 			token:       config.Token,
 			maxTokens:   config.MaxTokens,
@@ -132,35 +227,140 @@ func (g *NoneGenerator) PRReview(ctx context.Context, input PRReviewInput) (stri
 	return "No AI provider configured - PR review unavailable", nil
 }
 
+func (g *NoneGenerator) PRDescription(ctx context.Context, input PRReviewInput) (string, error) {
+	return "", ErrAINotEnabled
+}
+
 func (g *NoneGenerator) WorkspaceContext(ctx context.Context, input WorkspaceContextInput) (string, error) {
 	return "No AI provider configured - workspace context unavailable", nil
 }
 
+func (g *NoneGenerator) ProposeMerge(ctx context.Context, input MergeInput) (string, error) {
+	return "", ErrAINotEnabled
+}
+
+// ExecGenerator delegates to a user plugin, either run once per request
+// (protocol 1) or kept running and spoken to over JSON-RPC (protocol 2)
 type ExecGenerator struct {
 	execPath string
+	protocol int
+	timeout  time.Duration
+}
+
+func newExecGenerator(config Config) *ExecGenerator {
+	g := &ExecGenerator{execPath: config.ExecPath, protocol: config.ExecProtocol, timeout: config.ExecTimeout}
+	if g.protocol != ExecJSONRPC {
+		g.protocol = ExecOneShot
+	}
+	if g.timeout <= 0 {
+		g.timeout = DefaultExecTimeout
+	}
+	return g
 }
 
+// CommitMessage accepts either a JSON object or plain text from the plugin.
+// The prompt is built in the plugin, so a rejected reply is repaired here
+// rather than sent back for another try.
 func (g *ExecGenerator) CommitMessage(ctx context.Context, input CommitMsgInput) (string, error) {
-	return g.execCommand(ctx, "commit", input)
+	reply, err := g.execCommand(ctx, "commit", input)
+	if err != nil {
+		return "", err
+	}
+
+	commit, err := ParseCommit(reply)
+	if err != nil {
+		commit = StructuredCommit{Subject: firstLine(stripCodeFence(reply))}
+	}
+	return commit.Force().Render(), nil
 }
 
 func (g *ExecGenerator) Synopsis(ctx context.Context, input SynopsisInput) (string, error) {
 	return g.execCommand(ctx, "synopsis", input)
 }
 
+// StreamSynopsis streams from JSON-RPC plugins that support it; other
+// plugins deliver the synopsis in one piece
+func (g *ExecGenerator) StreamSynopsis(ctx context.Context, input SynopsisInput, onChunk StreamFunc) (string, error) {
+	return g.streamCommand(ctx, "synopsis", input, onChunk)
+}
+
 func (g *ExecGenerator) PRReview(ctx context.Context, input PRReviewInput) (string, error) {
 	return g.execCommand(ctx, "prreview", input)
 }
 
+func (g *ExecGenerator) PRDescription(ctx context.Context, input PRReviewInput) (string, error) {
+	return g.execCommand(ctx, "prdescription", input)
+}
+
 func (g *ExecGenerator) WorkspaceContext(ctx context.Context, input WorkspaceContextInput) (string, error) {
 	return g.execCommand(ctx, "workspace", input)
 }
 
+func (g *ExecGenerator) StreamWorkspaceContext(ctx context.Context, input WorkspaceContextInput, onChunk StreamFunc) (string, error) {
+	return g.streamCommand(ctx, "workspace", input, onChunk)
+}
+
+func (g *ExecGenerator) ProposeMerge(ctx context.Context, input MergeInput) (string, error) {
+	return g.execCommand(ctx, "merge", input)
+}
+
 func (g *ExecGenerator) execCommand(ctx context.Context, command string, input interface{}) (string, error) {
+	return g.streamCommand(ctx, command, input, nil)
+}
+
+// streamCommand runs a plugin command, passing partial results to onChunk
+// when the plugin streams and the whole reply when it does not
+func (g *ExecGenerator) streamCommand(ctx context.Context, command string, input interface{}, onChunk StreamFunc) (string, error) {
 	if g.execPath == "" {
 		return "", fmt.Errorf("exec path not configured")
 	}
 
+	if provider, ok := previewing(ctx); ok {
+		request := interface{}(map[string]interface{}{"command": command, "input": input})
+		if g.protocol == ExecJSONRPC {
+			id := int64(1)
+			request = rpcMessage{JSONRPC: "2.0", ID: &id, Method: command, Params: map[string]interface{}{"input": input, "stream": onChunk != nil}}
+		}
+		preview, err := json.Marshal(request)
+		if err != nil {
+			return "", fmt.Errorf("marshal input: %w", err)
+		}
+		showPrompt(provider, g.execPath, preview)
+		return "", ErrPromptShown
+	}
+
+	if g.protocol == ExecJSONRPC {
+		started := time.Now()
+		var streamed atomic.Bool
+		var forward StreamFunc
+		if onChunk != nil {
+			forward = func(chunk string) {
+				streamed.Store(true)
+				onChunk(chunk)
+			}
+		}
+		result, err := sharedPlugin(g.execPath).call(ctx, command, input, forward, g.timeout)
+		if err != nil {
+			return "", err
+		}
+		recordUsage(ctx, "exec", result.Usage.Model, "", result.Usage.InputTokens, result.Usage.OutputTokens, started)
+		text := strings.TrimSpace(result.Text)
+		if onChunk != nil && !streamed.Load() {
+			onChunk(text)
+		}
+		return text, nil
+	}
+
+	text, err := g.runOnce(ctx, command, input)
+	if err == nil && onChunk != nil {
+		onChunk(text)
+	}
+	return text, err
+}
+
+// runOnce runs the plugin for a single request: {command, input} on stdin,
+// the reply on stdout. stderr is logged and quoted when the plugin fails.
+func (g *ExecGenerator) runOnce(ctx context.Context, command string, input interface{}) (string, error) {
 	inputJson, err := json.Marshal(map[string]interface{}{
 		"command": command,
 		"input":   input,
@@ -169,13 +369,31 @@ func (g *ExecGenerator) execCommand(ctx context.Context, command string, input i
 		return "", fmt.Errorf("marshal input: %w", err)
 	}
 
+	ctx, cancel := context.WithTimeout(ctx, g.timeout)
+	defer cancel()
+
+	var stderr bytes.Buffer
 	cmd := exec.CommandContext(ctx, g.execPath)
 	cmd.Stdin = bytes.NewReader(inputJson)
+	cmd.Stderr = &stderr
+	// Children of a killed script may hold stdout open; do not wait for them
+	cmd.WaitDelay = time.Second
 
+	started := time.Now()
 	output, err := cmd.Output()
+	if tail := stderrTail(stderr.Bytes(), 5); tail != "" {
+		slog.Info("AI plugin stderr", "plugin", g.execPath, "output", tail)
+		if err != nil {
+			err = fmt.Errorf("%w: %s", err, tail)
+		}
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return "", fmt.Errorf("%w: %s timed out after %s", errPluginDown, g.execPath, g.timeout)
+	}
 	if err != nil {
 		return "", fmt.Errorf("exec command failed: %w", err)
 	}
+	recordUsage(ctx, "exec", "", "", 0, 0, started)
 
 	return strings.TrimSpace(string(output)), nil
 }
@@ -189,81 +407,53 @@ type OpenAIGenerator struct {
 }
 
 func (g *OpenAIGenerator) CommitMessage(ctx context.Context, input CommitMsgInput) (string, error) {
-	systemPrompt := "You are an expert helping developers write precise Git commit messages. Use conventional commits when possible (feat|fix|chore|refactor|docs|test|build|ci|perf). Keep a one-line subject (<= 72 chars). Add a short body with bullets if needed. No code fences."
-
-	userPrompt := g.buildPrompt(input)
+	return structuredCommitMessage(ctx, input, func(ctx context.Context, systemPrompt, userPrompt string) (string, error) {
+		return g.makeRequestWith(ctx, systemPrompt, userPrompt, map[string]interface{}{
+			"response_format": map[string]string{"type": "json_object"},
+		})
+	})
+}
 
-	reqBody := map[string]interface{}{
-		"model": g.model,
-		"messages": []map[string]string{
-			{"role": "system", "content": systemPrompt},
-			{"role": "user", "content": userPrompt},
-		},
-		"max_tokens":  g.maxTokens,
-		"temperature": g.temperature,
-	}
+func (g *OpenAIGenerator) Synopsis(ctx context.Context, input SynopsisInput) (string, error) {
+	return g.StreamSynopsis(ctx, input, nil)
+}
 
-	jsonData, err := json.Marshal(reqBody)
+func (g *OpenAIGenerator) StreamSynopsis(ctx context.Context, input SynopsisInput, onChunk StreamFunc) (string, error) {
+	systemPrompt, userPrompt, err := renderPrompt(KindSynopsis, input)
 	if err != nil {
-		return "", fmt.Errorf("marshal request: %w", err)
+		return "", err
 	}
+	return g.streamRequest(ctx, systemPrompt, userPrompt, onChunk)
+}
 
-	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
-	defer cancel()
-
-	req, err := http.NewRequestWithContext(ctx, "POST", g.endpoint+"/v1/chat/completions", bytes.NewBuffer(jsonData))
+func (g *OpenAIGenerator) PRReview(ctx context.Context, input PRReviewInput) (string, error) {
+	systemPrompt, userPrompt, err := renderPrompt(KindPRReview, input)
 	if err != nil {
-		return "", fmt.Errorf("create request: %w", err)
+		return "", err
 	}
+	return g.makeRequest(ctx, systemPrompt, userPrompt)
+}
 
-	req.Header.Set("Content-Type", "application/json")
-	if g.token != "" {
-		req.Header.Set("Authorization", "Bearer "+g.token)
+func (g *OpenAIGenerator) PRDescription(ctx context.Context, input PRReviewInput) (string, error) {
+	// A description runs to several paragraphs
+	describer := *g
+	if describer.maxTokens < descriptionMaxTokens {
+		describer.maxTokens = descriptionMaxTokens
 	}
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	systemPrompt, userPrompt, err := renderPrompt(KindPRDescription, input)
 	if err != nil {
-		return "", fmt.Errorf("http request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("HTTP %d", resp.StatusCode)
-	}
-
-	var response struct {
-		Choices []struct {
-			Message struct {
-				Content string `json:"content"`
-			} `json:"message"`
-		} `json:"choices"`
-	}
-
-	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-		return "", fmt.Errorf("decode response: %w", err)
-	}
-
-	if len(response.Choices) == 0 {
-		return "", fmt.Errorf("no choices in response")
+		return "", err
 	}
-
-	return strings.TrimSpace(response.Choices[0].Message.Content), nil
+	return describer.makeRequest(ctx, systemPrompt, userPrompt)
 }
 
-func (g *OpenAIGenerator) Synopsis(ctx context.Context, input SynopsisInput) (string, error) {
-	systemPrompt := "You are an expert developer creating workspace intelligence reports. Generate a concise, professional synopsis of development activity across repositories."
-	userPrompt := g.buildSynopsisPrompt(input)
-	return g.makeRequest(ctx, systemPrompt, userPrompt)
-}
-
-func (g *OpenAIGenerator) PRReview(ctx context.Context, input PRReviewInput) (string, error) {
-	systemPrompt := "You are an expert code reviewer. Provide a thorough but concise PR review with actionable feedback."
-	userPrompt := g.buildPRReviewPrompt(input)
-	return g.makeRequest(ctx, systemPrompt, userPrompt)
+func (g *OpenAIGenerator) makeRequest(ctx context.Context, systemPrompt, userPrompt string) (string, error) {
+	return g.makeRequestWith(ctx, systemPrompt, userPrompt, nil)
 }
 
-func (g *OpenAIGenerator) makeRequest(ctx context.Context, systemPrompt, userPrompt string) (string, error) {
+// makeRequestWith sends a chat completion with extra request fields, e.g. response_format
+func (g *OpenAIGenerator) makeRequestWith(ctx context.Context, systemPrompt, userPrompt string, extra map[string]interface{}) (string, error) {
 	reqBody := map[string]interface{}{
 		"model": g.model,
 		"messages": []map[string]string{
@@ -273,6 +463,9 @@ func (g *OpenAIGenerator) makeRequest(ctx context.Context, systemPrompt, userPro
 		"max_tokens":  g.maxTokens,
 		"temperature": g.temperature,
 	}
+	for key, value := range extra {
+		reqBody[key] = value
+	}
 
 	jsonData, err := json.Marshal(reqBody)
 	if err != nil {
@@ -292,28 +485,31 @@ func (g *OpenAIGenerator) makeRequest(ctx context.Context, systemPrompt, userPro
 		req.Header.Set("Authorization", "Bearer "+g.token)
 	}
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	started := time.Now()
+	resp, err := sendRequest(req, jsonData)
 	if err != nil {
 		return "", fmt.Errorf("http request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("HTTP %d", resp.StatusCode)
+		return "", newHTTPError(resp)
 	}
 
 	var response struct {
+		Model   string `json:"model"`
 		Choices []struct {
 			Message struct {
 				Content string `json:"content"`
 			} `json:"message"`
 		} `json:"choices"`
+		Usage openAIUsage `json:"usage"`
 	}
 
 	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
 		return "", fmt.Errorf("decode response: %w", err)
 	}
+	recordUsage(ctx, "openai", response.Model, g.model, response.Usage.PromptTokens, response.Usage.CompletionTokens, started)
 
 	if len(response.Choices) == 0 {
 		return "", fmt.Errorf("no choices in response")
@@ -322,6 +518,12 @@ func (g *OpenAIGenerator) makeRequest(ctx context.Context, systemPrompt, userPro
 	return strings.TrimSpace(response.Choices[0].Message.Content), nil
 }
 
+// openAIUsage is the token count of a chat completion
+type openAIUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+}
+
 // 🔥 CLAUDE API GENERATOR - CYBERPUNK INTELLIGENCE 🔥
 type ClaudeGenerator struct {
 	endpoint    string
@@ -332,40 +534,137 @@ type ClaudeGenerator struct {
 }
 
 func (g *ClaudeGenerator) CommitMessage(ctx context.Context, input CommitMsgInput) (string, error) {
-	systemPrompt := "You are an expert helping developers write precise Git commit messages. Use conventional commits when possible (feat|fix|chore|refactor|docs|test|build|ci|perf). Keep a one-line subject (<= 72 chars). Add a short body with bullets if needed. No code fences."
-	userPrompt := g.buildCommitPrompt(input)
-	return g.makeClaudeRequest(ctx, systemPrompt, userPrompt)
+	return structuredCommitMessage(ctx, input, g.makeCommitToolRequest)
 }
 
 func (g *ClaudeGenerator) Synopsis(ctx context.Context, input SynopsisInput) (string, error) {
-	systemPrompt := "You are an expert developer creating workspace intelligence reports. Generate a concise, professional synopsis of development activity across repositories. Focus on key insights and patterns."
-	userPrompt := g.buildSynopsisPrompt(input)
-	return g.makeClaudeRequest(ctx, systemPrompt, userPrompt)
+	return g.StreamSynopsis(ctx, input, nil)
+}
+
+func (g *ClaudeGenerator) StreamSynopsis(ctx context.Context, input SynopsisInput, onChunk StreamFunc) (string, error) {
+	systemPrompt, userPrompt, err := renderPrompt(KindSynopsis, input)
+	if err != nil {
+		return "", err
+	}
+	return g.streamClaudeRequest(ctx, systemPrompt, userPrompt, onChunk)
 }
 
 func (g *ClaudeGenerator) PRReview(ctx context.Context, input PRReviewInput) (string, error) {
-	systemPrompt := "You are an expert code reviewer. Provide a thorough but concise PR review with actionable feedback. Focus on code quality, potential issues, and improvement suggestions."
-	userPrompt := g.buildPRReviewPrompt(input)
+	systemPrompt, userPrompt, err := renderPrompt(KindPRReview, input)
+	if err != nil {
+		return "", err
+	}
 	return g.makeClaudeRequest(ctx, systemPrompt, userPrompt)
 }
 
+func (g *ClaudeGenerator) PRDescription(ctx context.Context, input PRReviewInput) (string, error) {
+	// A description runs to several paragraphs
+	describer := *g
+	if describer.maxTokens < descriptionMaxTokens {
+		describer.maxTokens = descriptionMaxTokens
+	}
+
+	systemPrompt, userPrompt, err := renderPrompt(KindPRDescription, input)
+	if err != nil {
+		return "", err
+	}
+	return describer.makeClaudeRequest(ctx, systemPrompt, userPrompt)
+}
+
 func (g *ClaudeGenerator) WorkspaceContext(ctx context.Context, input WorkspaceContextInput) (string, error) {
-	systemPrompt := `You are a development session assistant helping a developer understand where they left off in their work.
+	return g.StreamWorkspaceContext(ctx, input, nil)
+}
 
-Your role is to analyze their workspace state and provide a clear, actionable briefing that answers:
-- What was I working on when I stopped?
-- Which repositories have active work?
-- What's the current state of each project?
-- Where should I start when I return to work?
-- What are the next logical steps?
+func (g *ClaudeGenerator) StreamWorkspaceContext(ctx context.Context, input WorkspaceContextInput, onChunk StreamFunc) (string, error) {
+	systemPrompt, userPrompt, err := renderPrompt(KindWorkspaceContext, input)
+	if err != nil {
+		return "", err
+	}
+	return g.streamClaudeRequest(ctx, systemPrompt, userPrompt, onChunk)
+}
 
-Focus on work session continuity, not code quality. This is for "future me" context passing.`
+func (g *ClaudeGenerator) ProposeMerge(ctx context.Context, input MergeInput) (string, error) {
+	// Whole-file answers need far more room than commit messages
+	merger := *g
+	if merger.maxTokens < mergeMaxTokens {
+		merger.maxTokens = mergeMaxTokens
+	}
 
-	userPrompt := g.buildWorkspaceContextPrompt(input)
-	return g.makeClaudeRequest(ctx, systemPrompt, userPrompt)
+	systemPrompt, userPrompt, err := renderPrompt(KindMerge, input)
+	if err != nil {
+		return "", err
+	}
+	response, err := merger.makeClaudeRequest(ctx, systemPrompt, userPrompt)
+	if err != nil {
+		return "", err
+	}
+	return stripCodeFence(response), nil
+}
+
+// commitToolName is the tool Claude is made to call with the commit message
+const commitToolName = "record_commit_message"
+
+// claudeResponse is the part of a Messages API response wipctl reads
+type claudeResponse struct {
+	Model   string `json:"model"`
+	Content []struct {
+		Type  string          `json:"type"`
+		Text  string          `json:"text"`
+		Input json.RawMessage `json:"input"`
+	} `json:"content"`
+	Usage claudeUsage `json:"usage"`
+}
+
+// claudeUsage is the token count of a Messages API response
+type claudeUsage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
 }
 
 func (g *ClaudeGenerator) makeClaudeRequest(ctx context.Context, systemPrompt, userPrompt string) (string, error) {
+	response, err := g.sendClaudeRequest(ctx, systemPrompt, userPrompt, nil)
+	if err != nil {
+		return "", err
+	}
+
+	for _, block := range response.Content {
+		if block.Type == "" || block.Type == "text" {
+			return strings.TrimSpace(block.Text), nil
+		}
+	}
+	return "", fmt.Errorf("no content in response")
+}
+
+// makeCommitToolRequest forces a call of the commit message tool and returns
+// the tool input, which the API has already checked against commitSchema
+func (g *ClaudeGenerator) makeCommitToolRequest(ctx context.Context, systemPrompt, userPrompt string) (string, error) {
+	response, err := g.sendClaudeRequest(ctx, systemPrompt, userPrompt, map[string]interface{}{
+		"tools": []map[string]interface{}{{
+			"name":         commitToolName,
+			"description":  "Record the Conventional Commits message for the staged changes",
+			"input_schema": commitSchema,
+		}},
+		"tool_choice": map[string]string{"type": "tool", "name": commitToolName},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	for _, block := range response.Content {
+		if block.Type == "tool_use" && len(block.Input) > 0 {
+			return string(block.Input), nil
+		}
+	}
+	// Endpoints without tool support answer in text; ParseCommit copes
+	for _, block := range response.Content {
+		if block.Text != "" {
+			return strings.TrimSpace(block.Text), nil
+		}
+	}
+	return "", fmt.Errorf("no content in response")
+}
+
+func (g *ClaudeGenerator) sendClaudeRequest(ctx context.Context, systemPrompt, userPrompt string, extra map[string]interface{}) (*claudeResponse, error) {
 	endpoint := g.endpoint
 	if endpoint == "" {
 		endpoint = "https://api.anthropic.com"
@@ -379,10 +678,13 @@ func (g *ClaudeGenerator) makeClaudeRequest(ctx context.Context, systemPrompt, u
 			{"role": "user", "content": systemPrompt + "\n\n" + userPrompt},
 		},
 	}
+	for key, value := range extra {
+		reqBody[key] = value
+	}
 
 	jsonData, err := json.Marshal(reqBody)
 	if err != nil {
-		return "", fmt.Errorf("marshal request: %w", err)
+		return nil, fmt.Errorf("marshal request: %w", err)
 	}
 
 	ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
@@ -390,7 +692,7 @@ func (g *ClaudeGenerator) makeClaudeRequest(ctx context.Context, systemPrompt, u
 
 	req, err := http.NewRequestWithContext(ctx, "POST", endpoint+"/v1/messages", bytes.NewBuffer(jsonData))
 	if err != nil {
-		return "", fmt.Errorf("create request: %w", err)
+		return nil, fmt.Errorf("create request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
@@ -399,32 +701,28 @@ func (g *ClaudeGenerator) makeClaudeRequest(ctx context.Context, systemPrompt, u
 		req.Header.Set("x-api-key", g.token)
 	}
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	started := time.Now()
+	resp, err := sendRequest(req, jsonData)
 	if err != nil {
-		return "", fmt.Errorf("http request: %w", err)
+		return nil, fmt.Errorf("http request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("HTTP %d", resp.StatusCode)
-	}
-
-	var response struct {
-		Content []struct {
-			Text string `json:"text"`
-		} `json:"content"`
+		return nil, newHTTPError(resp)
 	}
 
+	var response claudeResponse
 	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-		return "", fmt.Errorf("decode response: %w", err)
+		return nil, fmt.Errorf("decode response: %w", err)
 	}
+	recordUsage(ctx, "claude", response.Model, g.model, response.Usage.InputTokens, response.Usage.OutputTokens, started)
 
 	if len(response.Content) == 0 {
-		return "", fmt.Errorf("no content in response")
+		return nil, fmt.Errorf("no content in response")
 	}
 
-	return strings.TrimSpace(response.Content[0].Text), nil
+	return &response, nil
 }
 
 type OllamaGenerator struct {
@@ -433,78 +731,69 @@ type OllamaGenerator struct {
 }
 
 func (g *OllamaGenerator) CommitMessage(ctx context.Context, input CommitMsgInput) (string, error) {
-	endpoint := g.endpoint
-	if endpoint == "" {
-		endpoint = "http://localhost:11434"
-	}
-
-	systemPrompt := "You are an expert helping developers write precise Git commit messages. Use conventional commits when possible (feat|fix|chore|refactor|docs|test|build|ci|perf). Keep a one-line subject (<= 72 chars). Add a short body with bullets if needed. No code fences."
+	return structuredCommitMessage(ctx, input, func(ctx context.Context, systemPrompt, userPrompt string) (string, error) {
+		return g.makeOllamaRequestWith(ctx, systemPrompt, userPrompt, map[string]interface{}{"format": "json"})
+	})
+}
 
-	userPrompt := (&OpenAIGenerator{}).buildPrompt(input)
-	fullPrompt := systemPrompt + "\n\n" + userPrompt
+func (g *OllamaGenerator) Synopsis(ctx context.Context, input SynopsisInput) (string, error) {
+	return g.StreamSynopsis(ctx, input, nil)
+}
 
-	reqBody := map[string]interface{}{
-		"model":  g.model,
-		"prompt": fullPrompt,
-		"stream": false,
+func (g *OllamaGenerator) StreamSynopsis(ctx context.Context, input SynopsisInput, onChunk StreamFunc) (string, error) {
+	systemPrompt, userPrompt, err := renderPrompt(KindSynopsis, input)
+	if err != nil {
+		return "", err
 	}
+	return g.streamOllamaRequest(ctx, systemPrompt, userPrompt, onChunk)
+}
 
-	jsonData, err := json.Marshal(reqBody)
+func (g *OllamaGenerator) PRReview(ctx context.Context, input PRReviewInput) (string, error) {
+	systemPrompt, userPrompt, err := renderPrompt(KindPRReview, input)
 	if err != nil {
-		return "", fmt.Errorf("marshal request: %w", err)
+		return "", err
 	}
+	return g.makeOllamaRequest(ctx, systemPrompt, userPrompt)
+}
 
-	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
-	defer cancel()
-
-	req, err := http.NewRequestWithContext(ctx, "POST", endpoint+"/api/generate", bytes.NewBuffer(jsonData))
+func (g *OllamaGenerator) PRDescription(ctx context.Context, input PRReviewInput) (string, error) {
+	systemPrompt, userPrompt, err := renderPrompt(KindPRDescription, input)
 	if err != nil {
-		return "", fmt.Errorf("create request: %w", err)
+		return "", err
 	}
+	return g.makeOllamaRequest(ctx, systemPrompt, userPrompt)
+}
 
-	req.Header.Set("Content-Type", "application/json")
+func (g *OllamaGenerator) WorkspaceContext(ctx context.Context, input WorkspaceContextInput) (string, error) {
+	return g.StreamWorkspaceContext(ctx, input, nil)
+}
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+func (g *OllamaGenerator) StreamWorkspaceContext(ctx context.Context, input WorkspaceContextInput, onChunk StreamFunc) (string, error) {
+	systemPrompt, userPrompt, err := renderPrompt(KindWorkspaceContext, input)
 	if err != nil {
-		return "", fmt.Errorf("http request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("HTTP %d", resp.StatusCode)
+		return "", err
 	}
+	return g.streamOllamaRequest(ctx, systemPrompt, userPrompt, onChunk)
+}
 
-	var response struct {
-		Response string `json:"response"`
+func (g *OllamaGenerator) ProposeMerge(ctx context.Context, input MergeInput) (string, error) {
+	systemPrompt, userPrompt, err := renderPrompt(KindMerge, input)
+	if err != nil {
+		return "", err
 	}
-
-	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-		return "", fmt.Errorf("decode response: %w", err)
+	response, err := g.makeOllamaRequest(ctx, systemPrompt, userPrompt)
+	if err != nil {
+		return "", err
 	}
-
-	return strings.TrimSpace(response.Response), nil
+	return stripCodeFence(response), nil
 }
 
-func (g *OllamaGenerator) Synopsis(ctx context.Context, input SynopsisInput) (string, error) {
-	systemPrompt := "You are an expert developer creating workspace intelligence reports. Generate a concise, professional synopsis of development activity across repositories."
-	userPrompt := (&ClaudeGenerator{}).buildSynopsisPrompt(input)
-	return g.makeOllamaRequest(ctx, systemPrompt, userPrompt)
-}
-
-func (g *OllamaGenerator) PRReview(ctx context.Context, input PRReviewInput) (string, error) {
-	systemPrompt := "You are an expert code reviewer. Provide a thorough but concise PR review with actionable feedback."
-	userPrompt := (&ClaudeGenerator{}).buildPRReviewPrompt(input)
-	return g.makeOllamaRequest(ctx, systemPrompt, userPrompt)
-}
-
-func (g *OllamaGenerator) WorkspaceContext(ctx context.Context, input WorkspaceContextInput) (string, error) {
-	systemPrompt := "You are a development session assistant helping a developer understand where they left off in their work. Focus on work session continuity, not code quality. This is for 'future me' context passing."
-	userPrompt := (&ClaudeGenerator{}).buildWorkspaceContextPrompt(input)
-	return g.makeOllamaRequest(ctx, systemPrompt, userPrompt)
+func (g *OllamaGenerator) makeOllamaRequest(ctx context.Context, systemPrompt, userPrompt string) (string, error) {
+	return g.makeOllamaRequestWith(ctx, systemPrompt, userPrompt, nil)
 }
 
-func (g *OllamaGenerator) makeOllamaRequest(ctx context.Context, systemPrompt, userPrompt string) (string, error) {
+// makeOllamaRequestWith sends a generate request with extra fields, e.g. format
+func (g *OllamaGenerator) makeOllamaRequestWith(ctx context.Context, systemPrompt, userPrompt string, extra map[string]interface{}) (string, error) {
 	endpoint := g.endpoint
 	if endpoint == "" {
 		endpoint = "http://localhost:11434"
@@ -517,6 +806,9 @@ func (g *OllamaGenerator) makeOllamaRequest(ctx context.Context, systemPrompt, u
 		"prompt": fullPrompt,
 		"stream": false,
 	}
+	for key, value := range extra {
+		reqBody[key] = value
+	}
 
 	jsonData, err := json.Marshal(reqBody)
 	if err != nil {
@@ -533,152 +825,64 @@ func (g *OllamaGenerator) makeOllamaRequest(ctx context.Context, systemPrompt, u
 
 	req.Header.Set("Content-Type", "application/json")
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	started := time.Now()
+	resp, err := sendRequest(req, jsonData)
 	if err != nil {
 		return "", fmt.Errorf("http request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("HTTP %d", resp.StatusCode)
+		return "", newHTTPError(resp)
 	}
 
 	var response struct {
 		Response string `json:"response"`
+		ollamaUsage
 	}
 
 	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
 		return "", fmt.Errorf("decode response: %w", err)
 	}
+	recordUsage(ctx, "ollama", "", g.model, response.PromptEvalCount, response.EvalCount, started)
 
 	return strings.TrimSpace(response.Response), nil
 }
 
-func (g *OpenAIGenerator) buildPrompt(input CommitMsgInput) string {
-	var parts []string
-
-	parts = append(parts, fmt.Sprintf("Repository: %s", input.Repo))
-	parts = append(parts, fmt.Sprintf("Branch: %s", input.Branch))
-	parts = append(parts, fmt.Sprintf("Host: %s", input.Host))
-
-	if input.NameStatus != "" {
-		parts = append(parts, "\nFile Changes:")
-		parts = append(parts, input.NameStatus)
-	}
-
-	if input.DiffStat != "" {
-		parts = append(parts, "\nDiff Summary:")
-		parts = append(parts, input.DiffStat)
-	}
-
-	if len(input.Untracked) > 0 {
-		parts = append(parts, fmt.Sprintf("\nUntracked files: %s", strings.Join(input.Untracked, ", ")))
-	}
-
-	if len(input.PriorSubjects) > 0 {
-		parts = append(parts, "\nRecent commit messages:")
-		for _, subject := range input.PriorSubjects {
-			parts = append(parts, "- "+subject)
-		}
-	}
-
-	parts = append(parts, "\nGenerate a concise commit message for these changes:")
-
-	return strings.Join(parts, "\n")
+// ollamaUsage is the token count Ollama reports with the final response
+type ollamaUsage struct {
+	PromptEvalCount int `json:"prompt_eval_count"`
+	EvalCount       int `json:"eval_count"`
 }
 
-// 🔥 CLAUDE PROMPT BUILDERS 🔥
-
-func (g *ClaudeGenerator) buildCommitPrompt(input CommitMsgInput) string {
-	return (&OpenAIGenerator{}).buildPrompt(input) // Reuse existing commit prompt logic
+func (g *OpenAIGenerator) WorkspaceContext(ctx context.Context, input WorkspaceContextInput) (string, error) {
+	return g.StreamWorkspaceContext(ctx, input, nil)
 }
 
-func (g *ClaudeGenerator) buildSynopsisPrompt(input SynopsisInput) string {
-	var parts []string
-
-	parts = append(parts, "WORKSPACE INTELLIGENCE SYNOPSIS")
-	parts = append(parts, "=====================================")
-	parts = append(parts, fmt.Sprintf("Total Repositories: %d", len(input.Repositories)))
-	parts = append(parts, fmt.Sprintf("Total Files Changed: %d", input.TotalFiles))
-	parts = append(parts, fmt.Sprintf("Total Lines Changed: %d", input.TotalLines))
-	parts = append(parts, fmt.Sprintf("Total Commits: %d", input.TotalCommits))
-	parts = append(parts, "")
-
-	parts = append(parts, "REPOSITORY DETAILS:")
-	for _, repo := range input.Repositories {
-		parts = append(parts, fmt.Sprintf("• %s (%s):", repo.Name, repo.Branch))
-		parts = append(parts, fmt.Sprintf("  Status: %s", repo.Status))
-		if repo.FilesChanged > 0 {
-			parts = append(parts, fmt.Sprintf("  Files: %d, Lines: +%d/-%d, Commits: %d",
-				repo.FilesChanged, repo.LinesAdded, repo.LinesRemoved, repo.Commits))
-		}
-		parts = append(parts, "")
+func (g *OpenAIGenerator) StreamWorkspaceContext(ctx context.Context, input WorkspaceContextInput, onChunk StreamFunc) (string, error) {
+	systemPrompt, userPrompt, err := renderPrompt(KindWorkspaceContext, input)
+	if err != nil {
+		return "", err
 	}
-
-	parts = append(parts, "Please generate a concise executive summary of this workspace activity.")
-	parts = append(parts, "Focus on:")
-	parts = append(parts, "- Overall development patterns")
-	parts = append(parts, "- Key areas of activity")
-	parts = append(parts, "- Notable insights or trends")
-	parts = append(parts, "- Brief assessment of workspace health")
-
-	return strings.Join(parts, "\n")
+	return g.streamRequest(ctx, systemPrompt, userPrompt, onChunk)
 }
 
-func (g *ClaudeGenerator) buildPRReviewPrompt(input PRReviewInput) string {
-	var parts []string
-
-	parts = append(parts, "PULL REQUEST REVIEW REQUEST")
-	parts = append(parts, "===========================")
-	parts = append(parts, fmt.Sprintf("Repository: %s", input.Repo))
-	parts = append(parts, fmt.Sprintf("Branch: %s", input.Branch))
-	parts = append(parts, fmt.Sprintf("Files Changed: %d", input.FilesCount))
-	parts = append(parts, fmt.Sprintf("Lines: +%d/-%d", input.LinesAdded, input.LinesRemoved))
-	parts = append(parts, "")
-
-	if input.NameStatus != "" {
-		parts = append(parts, "FILE CHANGES:")
-		parts = append(parts, input.NameStatus)
-		parts = append(parts, "")
+func (g *OpenAIGenerator) ProposeMerge(ctx context.Context, input MergeInput) (string, error) {
+	// Whole-file answers need far more room than commit messages
+	merger := *g
+	if merger.maxTokens < mergeMaxTokens {
+		merger.maxTokens = mergeMaxTokens
 	}
 
-	if input.DiffStat != "" {
-		parts = append(parts, "DIFF SUMMARY:")
-		parts = append(parts, input.DiffStat)
-		parts = append(parts, "")
+	systemPrompt, userPrompt, err := renderPrompt(KindMerge, input)
+	if err != nil {
+		return "", err
 	}
-
-	if len(input.CommitMsgs) > 0 {
-		parts = append(parts, "COMMIT MESSAGES:")
-		for _, msg := range input.CommitMsgs {
-			parts = append(parts, "• "+msg)
-		}
-		parts = append(parts, "")
+	response, err := merger.makeRequest(ctx, systemPrompt, userPrompt)
+	if err != nil {
+		return "", err
 	}
-
-	parts = append(parts, "Please provide a thorough PR review covering:")
-	parts = append(parts, "- Code quality assessment")
-	parts = append(parts, "- Potential issues or concerns")
-	parts = append(parts, "- Improvement suggestions")
-	parts = append(parts, "- Overall readiness for merge")
-
-	return strings.Join(parts, "\n")
-}
-
-// Add missing methods to OpenAI generator
-func (g *OpenAIGenerator) buildSynopsisPrompt(input SynopsisInput) string {
-	return (&ClaudeGenerator{}).buildSynopsisPrompt(input)
-}
-
-func (g *OpenAIGenerator) buildPRReviewPrompt(input PRReviewInput) string {
-	return (&ClaudeGenerator{}).buildPRReviewPrompt(input)
-}
-
-func (g *OpenAIGenerator) WorkspaceContext(ctx context.Context, input WorkspaceContextInput) (string, error) {
-	systemPrompt := "You are a development session assistant helping a developer understand where they left off in their work. Focus on work session continuity, not code quality. This is for 'future me' context passing."
-	userPrompt := (&ClaudeGenerator{}).buildWorkspaceContextPrompt(input)
-	return g.makeRequest(ctx, systemPrompt, userPrompt)
+	return stripCodeFence(response), nil
 }
 
 func LoadConfigFromEnv() Config {
@@ -696,6 +900,48 @@ func LoadConfigFromEnv() Config {
 		}
 	}
 
+	contextSize := 0
+	if val := os.Getenv("WIPCTL_AI_CONTEXT_TOKENS"); val != "" {
+		if parsed, err := json.Number(val).Int64(); err == nil {
+			contextSize = int(parsed)
+		}
+	}
+
+	diffTokens := 0
+	if val := os.Getenv("WIPCTL_AI_DIFF_TOKENS"); val != "" {
+		if parsed, err := json.Number(val).Int64(); err == nil {
+			diffTokens = int(parsed)
+			if diffTokens == 0 {
+				diffTokens = -1 // explicit 0 turns diff content off
+			}
+		}
+	}
+
+	execProtocol := ExecOneShot
+	if val := os.Getenv("WIPCTL_AI_EXEC_PROTOCOL"); val == "2" || val == "jsonrpc" {
+		execProtocol = ExecJSONRPC
+	}
+
+	execTimeout := time.Duration(0)
+	if val := os.Getenv("WIPCTL_AI_EXEC_TIMEOUT"); val != "" {
+		if parsed, err := aicache.ParseDuration(val); err == nil {
+			execTimeout = parsed
+		}
+	}
+
+	providers := make(map[string]ProviderSettings)
+	for _, name := range chainProviders {
+		prefix := "WIPCTL_AI_" + strings.ToUpper(name) + "_"
+		settings := ProviderSettings{
+			Endpoint: os.Getenv(prefix + "ENDPOINT"),
+			Model:    os.Getenv(prefix + "MODEL"),
+			Token:    os.Getenv(prefix + "TOKEN"),
+		}
+		if settings != (ProviderSettings{}) {
+			providers[name] = settings
+		}
+	}
+
 	return Config{
 		Provider:    os.Getenv("WIPCTL_AI_PROVIDER"),
 		Endpoint:    os.Getenv("WIPCTL_AI_ENDPOINT"),
@@ -704,45 +950,37 @@ func LoadConfigFromEnv() Config {
 		ExecPath:    os.Getenv("WIPCTL_AI_EXEC"),
 		MaxTokens:   maxTokens,
 		Temperature: temperature,
-	}
-}
-
-func (g *ClaudeGenerator) buildWorkspaceContextPrompt(input WorkspaceContextInput) string {
-	return fmt.Sprintf(`🔄 WORKSPACE SESSION BRIEFING 🔄
+		ContextSize: contextSize,
+		DiffTokens:  diffTokens,
 
-You're helping a developer understand where they left off. Analyze this workspace state:
+		ExecProtocol: execProtocol,
+		ExecTimeout:  execTimeout,
 
-OVERVIEW:
-- Total Repositories: %d
-- Active Repositories: %d
-- Repositories with Changes: %d
-- Total Files Modified: %d
-- Total Lines Changed: %d
+		Cache:        sharedCache,
+		CacheRefresh: sharedRefresh,
 
-REPOSITORY STATUS:
-%s
+		Providers: providers,
 
-Please provide a briefing that answers:
+		Redaction:  sharedRedaction,
+		ShowPrompt: sharedShowPrompt,
+	}
+}
 
-1. **WORK SESSION SUMMARY**: What was I working on when I stopped?
-2. **ACTIVE PROJECTS**: Which repositories have ongoing work?
-3. **CURRENT STATE**: What's the status of each active project?
-4. **PRIORITY GUIDANCE**: Where should I start when I return?
-5. **NEXT STEPS**: What are the logical next actions?
+const mergeMaxTokens = 4096
 
-Format your response for a developer returning to work who needs to quickly understand:
-- What they were in the middle of
-- Which repos need attention
-- What the current state means
-- Where to pick up development
+const descriptionMaxTokens = 1024
 
-Focus on actionable context, not code quality assessment.`,
-		len(input.Repositories),
-		input.ActiveRepos,
-		input.DirtyRepos,
-		input.TotalFiles,
-		input.TotalLines,
-		g.formatWorkspaceRepos(input.Repositories))
+// stripCodeFence removes a surrounding ``` fence that models add despite instructions
+func stripCodeFence(text string) string {
+	trimmed := strings.TrimSpace(text)
+	if !strings.HasPrefix(trimmed, "```") {
+		return text
+	}
+	lines := strings.Split(trimmed, "\n")
+	if len(lines) < 2 || strings.TrimSpace(lines[len(lines)-1]) != "```" {
+		return text
+	}
+	return strings.Join(lines[1:len(lines)-1], "\n") + "\n"
 }
 
 //nolint:unused // TODO: will be used for multi-repo formatting
@@ -759,39 +997,6 @@ func (g *ClaudeGenerator) formatRepositories(repos []RepoSummary) string {
 	return strings.Join(parts, "\n")
 }
 
-func (g *ClaudeGenerator) formatWorkspaceRepos(repos []WorkspaceRepo) string {
-	var parts []string
-	for _, repo := range repos {
-		status := "🟢 Clean"
-		if repo.Status == "dirty" {
-			status = "🟡 Has Changes"
-		} else if repo.Status == "in-progress" {
-			status = "🔄 In Progress"
-		}
-
-		recentWork := "No recent work"
-		if len(repo.RecentWork) > 0 {
-			recentWork = strings.Join(repo.RecentWork[:min(3, len(repo.RecentWork))], "; ")
-		}
-
-		changes := "No changes"
-		if repo.Changes != "" {
-			changes = repo.Changes
-		}
-
-		parts = append(parts, fmt.Sprintf(`
-📁 **%s** (%s)
-   Status: %s
-   Files: %d changed | Lines: +%d/-%d | Commits: %d
-   Recent Work: %s
-   Current Changes: %s`,
-			repo.Name, repo.Branch, status, repo.FilesChanged,
-			repo.LinesAdded, repo.LinesRemoved, repo.Commits,
-			recentWork, changes))
-	}
-	return strings.Join(parts, "\n")
-}
-
 func min(a, b int) int {
 	if a < b {
 		return a
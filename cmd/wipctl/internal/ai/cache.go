@@ -0,0 +1,169 @@
+package ai
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/aicache"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/config"
+)
+
+// Kinds of AI requests, used in cache keys and entries
+const (
+	KindCommit           = "commit"
+	KindSynopsis         = "synopsis"
+	KindPRReview         = "pr-review"
+	KindPRDescription    = "pr-description"
+	KindWorkspaceContext = "workspace-context"
+	KindMerge            = "merge"
+)
+
+var (
+	sharedCache   *aicache.Cache
+	sharedRefresh bool
+)
+
+// UseCache makes generators built from LoadConfigFromEnv cache responses in
+// cache; nil disables caching. With refresh set cached responses are not used,
+// but fresh ones are still stored.
+func UseCache(cache *aicache.Cache, refresh bool) {
+	sharedCache = cache
+	sharedRefresh = refresh
+}
+
+// cachingGenerator answers repeated requests from the on-disk cache. The key
+// covers provider, model, request settings, prompt version and the whole
+// input, so any change to the diff, branch, prompt, token limit, temperature
+// or redaction rules is a miss.
+type cachingGenerator struct {
+	inner    Generator
+	cache    *aicache.Cache
+	provider string
+	model    string
+	settings cacheSettings
+	refresh  bool
+}
+
+// cacheSettings are the options besides the prompt that shape a response
+type cacheSettings struct {
+	MaxTokens   int               `json:"max_tokens"`
+	Temperature float64           `json:"temperature"`
+	Masks       []config.Mask     `json:"masks"` // built-in masks, which change between releases
+	Redaction   *config.Redaction `json:"redaction"`
+}
+
+func newCachingGenerator(inner Generator, config Config) Generator {
+	var models []string
+	for _, link := range config.providerChain() {
+		if link.Provider == "exec" {
+			models = append(models, link.ExecPath)
+		} else {
+			models = append(models, link.Model)
+		}
+	}
+	return &cachingGenerator{
+		inner:    inner,
+		cache:    config.Cache,
+		provider: config.Provider,
+		model:    strings.Join(models, ","),
+		settings: cacheSettings{
+			MaxTokens:   config.MaxTokens,
+			Temperature: config.Temperature,
+			Masks:       defaultMasks,
+			Redaction:   config.Redaction,
+		},
+		refresh: config.CacheRefresh,
+	}
+}
+
+func (g *cachingGenerator) CommitMessage(ctx context.Context, input CommitMsgInput) (string, error) {
+	return g.cached(ctx, KindCommit, input, nil, func(ctx context.Context) (string, error) {
+		return g.inner.CommitMessage(ctx, input)
+	})
+}
+
+func (g *cachingGenerator) Synopsis(ctx context.Context, input SynopsisInput) (string, error) {
+	return g.StreamSynopsis(ctx, input, nil)
+}
+
+func (g *cachingGenerator) StreamSynopsis(ctx context.Context, input SynopsisInput, onChunk StreamFunc) (string, error) {
+	return g.cached(ctx, KindSynopsis, input, onChunk, func(ctx context.Context) (string, error) {
+		return StreamSynopsis(ctx, g.inner, input, onChunk)
+	})
+}
+
+func (g *cachingGenerator) PRReview(ctx context.Context, input PRReviewInput) (string, error) {
+	return g.cached(ctx, KindPRReview, input, nil, func(ctx context.Context) (string, error) {
+		return g.inner.PRReview(ctx, input)
+	})
+}
+
+func (g *cachingGenerator) PRDescription(ctx context.Context, input PRReviewInput) (string, error) {
+	return g.cached(ctx, KindPRDescription, input, nil, func(ctx context.Context) (string, error) {
+		return g.inner.PRDescription(ctx, input)
+	})
+}
+
+func (g *cachingGenerator) WorkspaceContext(ctx context.Context, input WorkspaceContextInput) (string, error) {
+	return g.StreamWorkspaceContext(ctx, input, nil)
+}
+
+func (g *cachingGenerator) StreamWorkspaceContext(ctx context.Context, input WorkspaceContextInput, onChunk StreamFunc) (string, error) {
+	return g.cached(ctx, KindWorkspaceContext, input, onChunk, func(ctx context.Context) (string, error) {
+		return StreamWorkspaceContext(ctx, g.inner, input, onChunk)
+	})
+}
+
+func (g *cachingGenerator) ProposeMerge(ctx context.Context, input MergeInput) (string, error) {
+	return g.cached(ctx, KindMerge, input, nil, func(ctx context.Context) (string, error) {
+		return g.inner.ProposeMerge(ctx, input)
+	})
+}
+
+// cached returns the cached response for kind and input, handing it to
+// onChunk in one piece, or calls generate and stores what it returns.
+// Failed and cancelled requests are not stored.
+func (g *cachingGenerator) cached(ctx context.Context, kind string, input interface{}, onChunk StreamFunc, generate func(context.Context) (string, error)) (string, error) {
+	attribution := attributionFrom(ctx)
+	if attribution == nil {
+		ctx, attribution = WithAttribution(ctx)
+	}
+
+	key, err := aicache.Key(g.provider, g.model, g.settings, kind, promptKey(kind), input)
+	if err != nil {
+		slog.Debug("AI cache key failed", "kind", kind, "error", err)
+		return generate(ctx)
+	}
+
+	if !g.refresh {
+		if entry, ok := g.cache.Get(key); ok {
+			slog.Debug("AI cache hit", "kind", kind, "key", key[:12])
+			attribution.Provider = entry.Provider
+			attribution.Cached = true
+			if onChunk != nil {
+				onChunk(entry.Response)
+			}
+			return entry.Response, nil
+		}
+	}
+
+	response, err := generate(ctx)
+	if err != nil || response == "" {
+		return response, err
+	}
+
+	provider := attribution.Provider
+	if provider == "none" {
+		// A template stand-in; the real providers should be asked next time
+		return response, nil
+	}
+	if provider == "" {
+		provider = g.provider
+	}
+	entry := aicache.Entry{Kind: kind, Provider: provider, Model: g.model, Response: response}
+	if err := g.cache.Put(key, entry); err != nil {
+		slog.Warn("Failed to cache AI response", "kind", kind, "error", err)
+	}
+	return response, nil
+}
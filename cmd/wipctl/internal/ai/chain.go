@@ -0,0 +1,415 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/aiusage"
+)
+
+// Retry and circuit breaker tuning for provider chains
+const (
+	maxProviderAttempts = 3                // tries per provider for retryable errors
+	retryBaseDelay      = time.Second      // first backoff, doubled per attempt
+	maxRetryDelay       = 20 * time.Second // longer Retry-After values skip to the next provider
+	breakerThreshold    = 2                // consecutive failed requests that open the breaker
+	breakerCooldown     = 2 * time.Minute  // how long an open breaker skips its provider
+)
+
+// HTTPError is a non-200 response from a provider API
+type HTTPError struct {
+	Status     int
+	Message    string        // error message from the response body, if any
+	RetryAfter time.Duration // from the Retry-After header; 0 if absent
+}
+
+func (e *HTTPError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("HTTP %d", e.Status)
+	}
+	return fmt.Sprintf("HTTP %d: %s", e.Status, e.Message)
+}
+
+// Retryable reports whether the request may succeed if sent again: rate
+// limits (429), server errors and overload (5xx, Anthropic's 529)
+func (e *HTTPError) Retryable() bool {
+	return e.Status == http.StatusTooManyRequests || e.Status >= 500
+}
+
+func (e *HTTPError) retryDelay() time.Duration {
+	return e.RetryAfter
+}
+
+// retryableError is a provider error that may say to try again: HTTP errors
+// and plugin errors
+type retryableError interface {
+	error
+	Retryable() bool
+	retryDelay() time.Duration
+}
+
+// newHTTPError reads the error details of a non-200 response
+func newHTTPError(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	return &HTTPError{
+		Status:     resp.StatusCode,
+		Message:    errorMessage(body),
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+	}
+}
+
+// errorMessage extracts the message from an API error body. Anthropic and
+// OpenAI send {"error": {"message": ...}}, Ollama sends {"error": "..."}.
+func errorMessage(body []byte) string {
+	var structured struct {
+		Error json.RawMessage `json:"error"`
+	}
+	if json.Unmarshal(body, &structured) == nil && len(structured.Error) > 0 {
+		var nested struct {
+			Type    string `json:"type"`
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(structured.Error, &nested) == nil && nested.Message != "" {
+			if nested.Type != "" {
+				return nested.Type + ": " + nested.Message
+			}
+			return nested.Message
+		}
+		var plain string
+		if json.Unmarshal(structured.Error, &plain) == nil {
+			return plain
+		}
+	}
+
+	text := strings.Join(strings.Fields(string(body)), " ")
+	if len(text) > 200 {
+		text = text[:200] + "..."
+	}
+	return text
+}
+
+// parseRetryAfter accepts delay-seconds or an HTTP date
+func parseRetryAfter(value string) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if when, err := http.ParseTime(value); err == nil {
+		if delay := time.Until(when); delay > 0 {
+			return delay
+		}
+	}
+	return 0
+}
+
+// Attribution records which provider answered a request and which were
+// tried before it. Put one in the context with WithAttribution.
+type Attribution struct {
+	Provider string          // provider that produced the response
+	Cached   bool            // answered from the response cache
+	Failed   []string        // earlier providers with the reason they were passed over
+	Usage    []aiusage.Usage // every completed provider call, including rejected replies
+}
+
+// Unused is the attribution of a response that was not used, e.g. a commit
+// message replaced by the template: no provider, but the usage still counts
+func (a *Attribution) Unused() *Attribution {
+	return &Attribution{Usage: a.Usage}
+}
+
+// Calls returns the usage of the requests (nil-safe)
+func (a *Attribution) Calls() []aiusage.Usage {
+	if a == nil {
+		return nil
+	}
+	return a.Usage
+}
+
+// String describes the attribution, e.g. "openai (claude: HTTP 529)"
+func (a *Attribution) String() string {
+	if a == nil || a.Provider == "" {
+		return ""
+	}
+	text := a.Provider
+	if a.Cached {
+		text += ", cached"
+	}
+	if len(a.Failed) > 0 {
+		text += " (" + strings.Join(a.Failed, "; ") + ")"
+	}
+	return text
+}
+
+type attributionKey struct{}
+
+// WithAttribution returns a context that records which provider answers the
+// requests made with it
+func WithAttribution(ctx context.Context) (context.Context, *Attribution) {
+	attribution := &Attribution{}
+	return context.WithValue(ctx, attributionKey{}, attribution), attribution
+}
+
+func attributionFrom(ctx context.Context) *Attribution {
+	if attribution, ok := ctx.Value(attributionKey{}).(*Attribution); ok {
+		return attribution
+	}
+	return nil
+}
+
+// ProviderNames splits a provider setting such as "claude,openai,ollama,none"
+// into the chain of provider names
+func ProviderNames(provider string) []string {
+	var names []string
+	for _, name := range strings.FieldsFunc(provider, func(r rune) bool { return r == ',' || r == ' ' }) {
+		names = append(names, strings.ToLower(name))
+	}
+	return names
+}
+
+// chainLink is one provider in a chain with its circuit breaker
+type chainLink struct {
+	name      string
+	generator Generator
+	breaker   *breaker
+}
+
+// chainGenerator tries providers in order. Rate limits and server errors are
+// retried with backoff (honoring Retry-After) before moving on to the next
+// provider; providers that keep failing are skipped for the rest of the run.
+type chainGenerator struct {
+	links []chainLink
+}
+
+func newChainGenerator(config Config) Generator {
+	chain := &chainGenerator{}
+	for _, link := range config.providerChain() {
+		chain.links = append(chain.links, chainLink{
+			name:      link.Provider,
+			generator: newRedactingGenerator(newProviderGenerator(link), link),
+			breaker:   &breaker{provider: link.Provider},
+		})
+	}
+	return chain
+}
+
+func (g *chainGenerator) CommitMessage(ctx context.Context, input CommitMsgInput) (string, error) {
+	return g.run(ctx, nil, func(ctx context.Context, gen Generator, _ StreamFunc) (string, error) {
+		return gen.CommitMessage(ctx, input)
+	})
+}
+
+func (g *chainGenerator) Synopsis(ctx context.Context, input SynopsisInput) (string, error) {
+	return g.StreamSynopsis(ctx, input, nil)
+}
+
+func (g *chainGenerator) StreamSynopsis(ctx context.Context, input SynopsisInput, onChunk StreamFunc) (string, error) {
+	return g.run(ctx, onChunk, func(ctx context.Context, gen Generator, onChunk StreamFunc) (string, error) {
+		if onChunk == nil {
+			return gen.Synopsis(ctx, input)
+		}
+		return StreamSynopsis(ctx, gen, input, onChunk)
+	})
+}
+
+func (g *chainGenerator) PRReview(ctx context.Context, input PRReviewInput) (string, error) {
+	return g.run(ctx, nil, func(ctx context.Context, gen Generator, _ StreamFunc) (string, error) {
+		return gen.PRReview(ctx, input)
+	})
+}
+
+func (g *chainGenerator) PRDescription(ctx context.Context, input PRReviewInput) (string, error) {
+	return g.run(ctx, nil, func(ctx context.Context, gen Generator, _ StreamFunc) (string, error) {
+		return gen.PRDescription(ctx, input)
+	})
+}
+
+func (g *chainGenerator) WorkspaceContext(ctx context.Context, input WorkspaceContextInput) (string, error) {
+	return g.StreamWorkspaceContext(ctx, input, nil)
+}
+
+func (g *chainGenerator) StreamWorkspaceContext(ctx context.Context, input WorkspaceContextInput, onChunk StreamFunc) (string, error) {
+	return g.run(ctx, onChunk, func(ctx context.Context, gen Generator, onChunk StreamFunc) (string, error) {
+		if onChunk == nil {
+			return gen.WorkspaceContext(ctx, input)
+		}
+		return StreamWorkspaceContext(ctx, gen, input, onChunk)
+	})
+}
+
+func (g *chainGenerator) ProposeMerge(ctx context.Context, input MergeInput) (string, error) {
+	return g.run(ctx, nil, func(ctx context.Context, gen Generator, _ StreamFunc) (string, error) {
+		return gen.ProposeMerge(ctx, input)
+	})
+}
+
+// run sends a request down the chain. Once streamed text has been shown the
+// request is not retried or handed on, since the output cannot be taken back.
+func (g *chainGenerator) run(ctx context.Context, onChunk StreamFunc, request func(context.Context, Generator, StreamFunc) (string, error)) (string, error) {
+	attribution := attributionFrom(ctx)
+
+	var streamed atomic.Bool
+	var tracked StreamFunc
+	if onChunk != nil {
+		tracked = func(chunk string) {
+			streamed.Store(true)
+			onChunk(chunk)
+		}
+	}
+
+	var failures []string
+	var lastErr error
+	shown := false
+	for _, link := range g.links {
+		if !link.breaker.allow() {
+			failures = append(failures, link.name+": skipped, circuit open")
+			continue
+		}
+
+		response, err := g.attempt(ctx, link, func() (string, error) {
+			return request(ctx, link.generator, tracked)
+		}, &streamed)
+		if err == nil {
+			link.breaker.success()
+			if attribution != nil {
+				attribution.Provider = link.name
+				attribution.Failed = failures
+			}
+			return response, nil
+		}
+
+		if ctx.Err() != nil || streamed.Load() {
+			return "", err
+		}
+		if errors.Is(err, ErrPromptShown) {
+			// Show what every provider in the chain would get
+			shown = true
+			continue
+		}
+		if providerFault(err) {
+			link.breaker.failure(err)
+		}
+
+		slog.Warn("AI provider failed", "provider", link.name, "error", err)
+		failures = append(failures, fmt.Sprintf("%s: %v", link.name, err))
+		lastErr = err
+	}
+
+	if attribution != nil {
+		attribution.Failed = failures
+	}
+	if shown {
+		return "", ErrPromptShown
+	}
+	if lastErr == nil {
+		return "", fmt.Errorf("all AI providers skipped: %s", strings.Join(failures, "; "))
+	}
+	if len(g.links) == 1 {
+		return "", lastErr
+	}
+	return "", fmt.Errorf("all AI providers failed: %s", strings.Join(failures, "; "))
+}
+
+// attempt sends one request to a provider, retrying rate limits and server
+// errors with exponential backoff or the provider's Retry-After
+func (g *chainGenerator) attempt(ctx context.Context, link chainLink, send func() (string, error), streamed *atomic.Bool) (string, error) {
+	var err error
+	for attempt := 1; attempt <= maxProviderAttempts; attempt++ {
+		var response string
+		response, err = send()
+		if err == nil {
+			return response, nil
+		}
+
+		var retryErr retryableError
+		if !errors.As(err, &retryErr) || !retryErr.Retryable() || streamed.Load() || attempt == maxProviderAttempts {
+			return "", err
+		}
+
+		delay := retryErr.retryDelay()
+		if delay == 0 {
+			delay = retryBaseDelay<<(attempt-1) + time.Duration(rand.Int63n(int64(250*time.Millisecond)))
+		}
+		if delay > maxRetryDelay {
+			// Not worth waiting for; keep the provider out until it is ready again
+			link.breaker.openFor(delay)
+			return "", fmt.Errorf("%w (retry after %s)", err, delay.Round(time.Second))
+		}
+
+		slog.Info("AI provider busy, retrying", "provider", link.name, "error", retryErr.Error(), "delay", delay.Round(time.Millisecond).String(), "attempt", attempt)
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+	return "", err
+}
+
+// providerFault reports whether an error says something about the provider's
+// health (HTTP errors, unreachable endpoints, plugins that will not run or
+// report a transient failure) rather than about one reply
+func providerFault(err error) bool {
+	var httpErr *HTTPError
+	var pluginErr *PluginError
+	var netErr net.Error
+	var opErr *net.OpError
+	if errors.As(err, &pluginErr) {
+		return pluginErr.Retryable()
+	}
+	return errors.As(err, &httpErr) || errors.Is(err, errPluginDown) || errors.As(err, &netErr) || errors.As(err, &opErr)
+}
+
+// breaker is a per-run circuit breaker: after breakerThreshold consecutive
+// failures its provider is skipped for breakerCooldown, then tried again
+type breaker struct {
+	provider  string
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+}
+
+func (b *breaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return time.Now().After(b.openUntil)
+}
+
+func (b *breaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures = 0
+	b.openUntil = time.Time{}
+}
+
+func (b *breaker) failure(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures++
+	if b.failures >= breakerThreshold && time.Now().After(b.openUntil) {
+		b.openUntil = time.Now().Add(breakerCooldown)
+		slog.Warn("AI provider circuit open, skipping it for this run", "provider", b.provider, "cooldown", breakerCooldown.String(), "error", err)
+	}
+}
+
+func (b *breaker) openFor(delay time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if until := time.Now().Add(delay); until.After(b.openUntil) {
+		b.openUntil = until
+	}
+}
@@ -0,0 +1,166 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeGenerator answers synopsis requests with reply or err and counts them
+type fakeGenerator struct {
+	NoneGenerator
+	reply string
+	err   error
+	calls int
+}
+
+func (g *fakeGenerator) Synopsis(ctx context.Context, input SynopsisInput) (string, error) {
+	g.calls++
+	return g.reply, g.err
+}
+
+// testChain builds a chain of the given generators, named in order
+func testChain(names []string, generators ...Generator) *chainGenerator {
+	chain := &chainGenerator{}
+	for i, generator := range generators {
+		chain.links = append(chain.links, chainLink{
+			name:      names[i],
+			generator: generator,
+			breaker:   &breaker{provider: names[i]},
+		})
+	}
+	return chain
+}
+
+// rateLimitServer answers every request with a 429 and the given Retry-After
+func rateLimitServer(t *testing.T, retryAfter string) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Header().Set("Retry-After", retryAfter)
+		w.WriteHeader(http.StatusTooManyRequests)
+		fmt.Fprint(w, `{"error":{"type":"rate_limit_error","message":"slow down"}}`)
+	}))
+	t.Cleanup(server.Close)
+	return server, &hits
+}
+
+func TestChainRetriesRateLimitThenFallsOver(t *testing.T) {
+	server, hits := rateLimitServer(t, "1")
+	fallback := &fakeGenerator{reply: "from fallback"}
+	chain := testChain([]string{"claude", "fallback"}, &ClaudeGenerator{endpoint: server.URL, model: "claude-test"}, fallback)
+
+	ctx, attribution := WithAttribution(context.Background())
+	started := time.Now()
+	got, err := chain.Synopsis(ctx, SynopsisInput{})
+	if err != nil {
+		t.Fatalf("Synopsis: %v", err)
+	}
+	if got != "from fallback" {
+		t.Errorf("response = %q, want the fallback's", got)
+	}
+	if n := hits.Load(); n != maxProviderAttempts {
+		t.Errorf("claude requests = %d, want %d", n, maxProviderAttempts)
+	}
+	if waited := time.Since(started); waited < time.Duration(maxProviderAttempts-1)*time.Second {
+		t.Errorf("retried after %s, want Retry-After honored", waited)
+	}
+	if attribution.Provider != "fallback" || len(attribution.Failed) != 1 || !strings.Contains(attribution.Failed[0], "claude: HTTP 429") {
+		t.Errorf("attribution = %+v, want fallback after claude: HTTP 429", attribution)
+	}
+}
+
+func TestChainSkipsLongRetryAfter(t *testing.T) {
+	server, hits := rateLimitServer(t, "120")
+	fallback := &fakeGenerator{reply: "from fallback"}
+	chain := testChain([]string{"claude", "fallback"}, &ClaudeGenerator{endpoint: server.URL, model: "claude-test"}, fallback)
+
+	for i := 0; i < 2; i++ {
+		if got, err := chain.Synopsis(context.Background(), SynopsisInput{}); err != nil || got != "from fallback" {
+			t.Fatalf("request %d = %q, %v, want the fallback's", i, got, err)
+		}
+	}
+	if n := hits.Load(); n != 1 {
+		t.Errorf("claude requests = %d, want 1: no wait for a long Retry-After, then skipped until it passes", n)
+	}
+	if fallback.calls != 2 {
+		t.Errorf("fallback calls = %d, want 2", fallback.calls)
+	}
+}
+
+func TestChainBreakerOpens(t *testing.T) {
+	failing := &fakeGenerator{err: &HTTPError{Status: http.StatusBadRequest, Message: "bad request"}}
+	fallback := &fakeGenerator{reply: "from fallback"}
+	chain := testChain([]string{"failing", "fallback"}, failing, fallback)
+
+	for i := 0; i < breakerThreshold; i++ {
+		if _, err := chain.Synopsis(context.Background(), SynopsisInput{}); err != nil {
+			t.Fatalf("request %d: %v", i, err)
+		}
+	}
+	if failing.calls != breakerThreshold {
+		t.Fatalf("failing calls = %d, want %d", failing.calls, breakerThreshold)
+	}
+
+	ctx, attribution := WithAttribution(context.Background())
+	if got, err := chain.Synopsis(ctx, SynopsisInput{}); err != nil || got != "from fallback" {
+		t.Fatalf("request after threshold = %q, %v", got, err)
+	}
+	if failing.calls != breakerThreshold {
+		t.Errorf("failing calls = %d, want the open breaker to skip it", failing.calls)
+	}
+	if len(attribution.Failed) != 1 || attribution.Failed[0] != "failing: skipped, circuit open" {
+		t.Errorf("failed = %q, want failing skipped", attribution.Failed)
+	}
+
+	// Once every link is out the error says so
+	chain.links[1].breaker.openFor(time.Minute)
+	_, err := chain.Synopsis(context.Background(), SynopsisInput{})
+	if err == nil || !strings.HasPrefix(err.Error(), "all AI providers skipped") {
+		t.Errorf("error = %v, want all AI providers skipped", err)
+	}
+}
+
+func TestChainBreakerIgnoresBadReplies(t *testing.T) {
+	failing := &fakeGenerator{err: errors.New("no content in response")}
+	fallback := &fakeGenerator{reply: "from fallback"}
+	chain := testChain([]string{"failing", "fallback"}, failing, fallback)
+
+	for i := 0; i < breakerThreshold+1; i++ {
+		if _, err := chain.Synopsis(context.Background(), SynopsisInput{}); err != nil {
+			t.Fatalf("request %d: %v", i, err)
+		}
+	}
+	if failing.calls != breakerThreshold+1 {
+		t.Errorf("failing calls = %d, want %d: a bad reply says nothing about the provider's health", failing.calls, breakerThreshold+1)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		value    string
+		min, max time.Duration
+	}{
+		{value: ""},
+		{value: "5", min: 5 * time.Second, max: 5 * time.Second},
+		{value: " 30 ", min: 30 * time.Second, max: 30 * time.Second},
+		{value: "0"},
+		{value: "-3"},
+		{value: "soon"},
+		{value: time.Now().Add(90 * time.Second).UTC().Format(http.TimeFormat), min: 85 * time.Second, max: 90 * time.Second},
+		{value: time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat)},
+	}
+
+	for _, tt := range tests {
+		if got := parseRetryAfter(tt.value); got < tt.min || got > tt.max {
+			t.Errorf("parseRetryAfter(%q) = %s, want %s to %s", tt.value, got, tt.min, tt.max)
+		}
+	}
+}
@@ -0,0 +1,352 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+// maxHeaderLength is the longest accepted "type(scope)!: subject" line
+const maxHeaderLength = 72
+
+// maxCommitAttempts bounds how often a provider is asked again after
+// returning an invalid commit message
+const maxCommitAttempts = 3
+
+// ConventionalTypes are the commit types accepted in messages
+var ConventionalTypes = []string{"feat", "fix", "chore", "refactor", "docs", "test", "build", "ci", "perf", "style", "revert"}
+
+// typeAliases maps common near-misses onto conventional types
+var typeAliases = map[string]string{
+	"feature":     "feat",
+	"features":    "feat",
+	"bugfix":      "fix",
+	"bug":         "fix",
+	"hotfix":      "fix",
+	"doc":         "docs",
+	"tests":       "test",
+	"testing":     "test",
+	"refactoring": "refactor",
+	"performance": "perf",
+	"deps":        "build",
+	"chores":      "chore",
+}
+
+// commitSchema is the JSON schema of StructuredCommit, for providers that
+// enforce one (tool use)
+var commitSchema = map[string]interface{}{
+	"type": "object",
+	"properties": map[string]interface{}{
+		"type":     map[string]interface{}{"type": "string", "enum": ConventionalTypes},
+		"scope":    map[string]interface{}{"type": "string", "description": "optional short lowercase area of the codebase"},
+		"subject":  map[string]interface{}{"type": "string", "description": "imperative summary without trailing period; the header type(scope): subject must fit in 72 characters"},
+		"body":     map[string]interface{}{"type": "string", "description": "optional explanation of what changed and why"},
+		"breaking": map[string]interface{}{"type": []string{"boolean", "string"}, "description": "false, or a description of the breaking change"},
+	},
+	"required": []string{"type", "subject"},
+}
+
+// StructuredCommit is a commit message in Conventional Commits form
+type StructuredCommit struct {
+	Type     string   `json:"type"`
+	Scope    string   `json:"scope,omitempty"`
+	Subject  string   `json:"subject"`
+	Body     string   `json:"body,omitempty"`
+	Breaking Breaking `json:"breaking,omitempty"`
+}
+
+// Breaking marks a breaking change; models send either a bool or a
+// description, so both are accepted
+type Breaking struct {
+	Is   bool
+	Note string
+}
+
+// UnmarshalJSON accepts true/false, a description string or null
+func (b *Breaking) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	switch {
+	case bytes.Equal(data, []byte("null")):
+		*b = Breaking{}
+		return nil
+	case len(data) > 0 && data[0] == '"':
+		var note string
+		if err := json.Unmarshal(data, &note); err != nil {
+			return err
+		}
+		note = strings.TrimSpace(note)
+		lower := strings.ToLower(note)
+		if note == "" || lower == "false" || lower == "no" || lower == "none" {
+			*b = Breaking{}
+			return nil
+		}
+		*b = Breaking{Is: true, Note: note}
+		if lower == "true" || lower == "yes" {
+			b.Note = ""
+		}
+		return nil
+	default:
+		return json.Unmarshal(data, &b.Is)
+	}
+}
+
+// MarshalJSON writes the note when there is one, otherwise the flag
+func (b Breaking) MarshalJSON() ([]byte, error) {
+	if b.Note != "" {
+		return json.Marshal(b.Note)
+	}
+	return json.Marshal(b.Is)
+}
+
+// Header returns the "type(scope)!: subject" line
+func (c StructuredCommit) Header() string {
+	var sb strings.Builder
+	sb.WriteString(c.Type)
+	if c.Scope != "" {
+		sb.WriteString("(" + c.Scope + ")")
+	}
+	if c.Breaking.Is {
+		sb.WriteString("!")
+	}
+	sb.WriteString(": " + c.Subject)
+	return sb.String()
+}
+
+// Render formats the commit as a git commit message
+func (c StructuredCommit) Render() string {
+	parts := []string{c.Header()}
+	if c.Body != "" {
+		parts = append(parts, c.Body)
+	}
+	if c.Breaking.Note != "" {
+		parts = append(parts, "BREAKING CHANGE: "+c.Breaking.Note)
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// Validate lists what keeps the commit from being a valid Conventional
+// Commits message; nil means it is valid
+func (c StructuredCommit) Validate() []string {
+	var problems []string
+	if !isConventionalType(c.Type) {
+		problems = append(problems, fmt.Sprintf("type %q is not one of %s", c.Type, strings.Join(ConventionalTypes, ", ")))
+	}
+	if c.Scope != "" && !scopePattern.MatchString(c.Scope) {
+		problems = append(problems, fmt.Sprintf("scope %q must be a short lowercase word (letters, digits, - _ / .)", c.Scope))
+	}
+	if c.Subject == "" {
+		problems = append(problems, "subject is empty")
+	}
+	if strings.ContainsAny(c.Subject, "\r\n") {
+		problems = append(problems, "subject must be a single line")
+	}
+	if strings.HasSuffix(c.Subject, ".") {
+		problems = append(problems, "subject must not end with a period")
+	}
+	if n := len([]rune(c.Header())); n > maxHeaderLength {
+		problems = append(problems, fmt.Sprintf("header is %d characters, the limit is %d", n, maxHeaderLength))
+	}
+	return problems
+}
+
+var (
+	scopePattern        = regexp.MustCompile(`^[a-z0-9][a-z0-9._/-]*$`)
+	conventionalPattern = regexp.MustCompile(`^(\w+)(?:\(([^)]*)\))?(!)?:\s*(.+)$`)
+	preamblePattern     = regexp.MustCompile(`(?i)^(here('s| is| are)|sure|certainly|okay|ok)\b.*:\s*$`)
+)
+
+func isConventionalType(t string) bool {
+	for _, known := range ConventionalTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Repair fixes what can be fixed without asking the model again: type
+// aliases and case, scope spelling, stray whitespace, a trailing period and
+// a subject that spills over several lines
+func (c StructuredCommit) Repair() StructuredCommit {
+	c.Type = strings.ToLower(strings.TrimSpace(c.Type))
+	if alias, ok := typeAliases[c.Type]; ok {
+		c.Type = alias
+	}
+
+	c.Scope = strings.ToLower(strings.TrimSpace(c.Scope))
+	c.Scope = strings.Join(strings.Fields(c.Scope), "-")
+
+	subject := strings.TrimSpace(c.Subject)
+	if first, rest, found := strings.Cut(subject, "\n"); found {
+		subject = strings.TrimSpace(first)
+		c.Body = strings.TrimSpace(strings.TrimSpace(rest) + "\n\n" + c.Body)
+	}
+	// Models sometimes repeat the header inside the subject
+	if m := conventionalPattern.FindStringSubmatch(subject); m != nil && isConventionalType(strings.ToLower(m[1])) {
+		subject = m[4]
+	}
+	c.Subject = strings.TrimRight(strings.TrimSpace(subject), ".")
+	c.Body = strings.TrimSpace(c.Body)
+	return c
+}
+
+// Force makes the commit valid no matter what, as a last resort once the
+// model has had its retries: unknown types become chore, bad scopes are
+// dropped and long subjects are cut at a word boundary
+func (c StructuredCommit) Force() StructuredCommit {
+	c = c.Repair()
+	if !isConventionalType(c.Type) {
+		c.Type = "chore"
+	}
+	if c.Scope != "" && !scopePattern.MatchString(c.Scope) {
+		c.Scope = ""
+	}
+	if c.Subject == "" {
+		c.Subject = "update work in progress"
+	}
+
+	for len([]rune(c.Header())) > maxHeaderLength {
+		if c.Scope != "" {
+			c.Scope = ""
+			continue
+		}
+		room := maxHeaderLength - (len([]rune(c.Header())) - len([]rune(c.Subject)))
+		c.Subject = truncateWords(c.Subject, room)
+	}
+	return c
+}
+
+func truncateWords(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	cut := string(runes[:limit])
+	if i := strings.LastIndex(cut, " "); i > limit/2 {
+		cut = cut[:i]
+	}
+	return strings.TrimRight(cut, " ,;:-.")
+}
+
+// ParseCommit reads a provider's reply: a JSON object (possibly fenced or
+// wrapped in prose) or, for providers without JSON support, a plain
+// "type(scope): subject" message
+func ParseCommit(reply string) (StructuredCommit, error) {
+	var commit StructuredCommit
+
+	text := strings.TrimSpace(stripCodeFence(reply))
+	if start, end := strings.Index(text, "{"), strings.LastIndex(text, "}"); start >= 0 && end > start {
+		if err := json.Unmarshal([]byte(text[start:end+1]), &commit); err == nil {
+			return commit, nil
+		}
+	}
+
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			continue
+		}
+		if len(lines) == 0 && isPreamble(line) {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) == 0 {
+		return commit, errors.New("empty reply")
+	}
+
+	header := strings.Trim(strings.TrimSpace(lines[0]), "`\"'")
+	m := conventionalPattern.FindStringSubmatch(header)
+	if m == nil {
+		return commit, fmt.Errorf("reply is neither JSON nor a conventional commit: %q", header)
+	}
+
+	commit = StructuredCommit{
+		Type:     m[1],
+		Scope:    m[2],
+		Breaking: Breaking{Is: m[3] == "!"},
+		Subject:  m[4],
+		Body:     strings.TrimSpace(strings.Join(lines[1:], "\n")),
+	}
+	if body, note, found := strings.Cut(commit.Body, "BREAKING CHANGE:"); found {
+		commit.Body = strings.TrimSpace(body)
+		commit.Breaking = Breaking{Is: true, Note: strings.TrimSpace(note)}
+	}
+	return commit, nil
+}
+
+// firstLine returns the first line of text that is neither blank nor a
+// conversational preamble
+func firstLine(text string) string {
+	for _, line := range strings.Split(text, "\n") {
+		if !isPreamble(line) && !strings.HasPrefix(strings.TrimSpace(line), "```") {
+			return strings.Trim(strings.TrimSpace(line), "`\"'")
+		}
+	}
+	return ""
+}
+
+// isPreamble reports whether a line is blank or conversational filler such
+// as "Here is the commit message:"
+func isPreamble(line string) bool {
+	line = strings.TrimSpace(line)
+	return line == "" || preamblePattern.MatchString(line)
+}
+
+// commitRequester sends one commit prompt to a provider and returns its raw reply
+type commitRequester func(ctx context.Context, systemPrompt, userPrompt string) (string, error)
+
+// structuredCommitMessage asks a provider for a structured commit message,
+// validating each reply against Conventional Commits. Invalid replies are
+// repaired locally where possible, otherwise the provider is asked again with
+// the problems listed; after the last attempt the best reply is forced valid.
+func structuredCommitMessage(ctx context.Context, input CommitMsgInput, request commitRequester) (string, error) {
+	systemPrompt, userPrompt, err := renderPrompt(KindCommit, input)
+	if err != nil {
+		return "", err
+	}
+	prompt := userPrompt
+
+	var best *StructuredCommit
+	var lastErr error
+	for attempt := 1; attempt <= maxCommitAttempts; attempt++ {
+		reply, err := request(ctx, systemPrompt, prompt)
+		if err != nil {
+			return "", err
+		}
+
+		commit, err := ParseCommit(reply)
+		if err != nil {
+			lastErr = err
+			slog.Debug("Unparseable AI commit message", "attempt", attempt, "error", err)
+			prompt = retryPrompt(userPrompt, reply, []string{err.Error()})
+			continue
+		}
+
+		commit = commit.Repair()
+		problems := commit.Validate()
+		if len(problems) == 0 {
+			return commit.Render(), nil
+		}
+
+		slog.Debug("Invalid AI commit message", "attempt", attempt, "problems", problems)
+		best = &commit
+		prompt = retryPrompt(userPrompt, reply, problems)
+	}
+
+	if best == nil {
+		return "", fmt.Errorf("no valid commit message after %d attempts: %w", maxCommitAttempts, lastErr)
+	}
+	return best.Force().Render(), nil
+}
+
+// retryPrompt repeats the request with the previous reply and what was wrong with it
+func retryPrompt(userPrompt, reply string, problems []string) string {
+	return fmt.Sprintf("%s\n\nYour previous reply was:\n%s\n\nIt was rejected because:\n- %s\n\nReply again with the JSON object only.",
+		userPrompt, strings.TrimSpace(reply), strings.Join(problems, "\n- "))
+}
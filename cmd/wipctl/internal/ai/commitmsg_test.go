@@ -0,0 +1,181 @@
+package ai
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseCommit(t *testing.T) {
+	tests := []struct {
+		name    string
+		reply   string
+		want    StructuredCommit
+		wantErr bool
+	}{
+		{
+			name:  "json",
+			reply: `{"type":"feat","scope":"api","subject":"add login","body":"Adds a form."}`,
+			want:  StructuredCommit{Type: "feat", Scope: "api", Subject: "add login", Body: "Adds a form."},
+		},
+		{
+			name:  "fenced json with prose",
+			reply: "Here is the commit:\n```json\n{\"type\":\"fix\",\"subject\":\"handle nil\",\"breaking\":\"drops v1\"}\n```",
+			want:  StructuredCommit{Type: "fix", Subject: "handle nil", Breaking: Breaking{Is: true, Note: "drops v1"}},
+		},
+		{
+			name:  "json breaking bool",
+			reply: `{"type":"feat","subject":"x","breaking":true}`,
+			want:  StructuredCommit{Type: "feat", Subject: "x", Breaking: Breaking{Is: true}},
+		},
+		{
+			name:  "plain header",
+			reply: "feat(ui)!: redo layout",
+			want:  StructuredCommit{Type: "feat", Scope: "ui", Subject: "redo layout", Breaking: Breaking{Is: true}},
+		},
+		{
+			name:  "plain with preamble and body",
+			reply: "Sure, here is the message:\n\nfix: close file\n\nThe handle leaked.\n\nBREAKING CHANGE: Open now returns an error",
+			want:  StructuredCommit{Type: "fix", Subject: "close file", Body: "The handle leaked.", Breaking: Breaking{Is: true, Note: "Open now returns an error"}},
+		},
+		{
+			name:    "not a commit",
+			reply:   "I updated some files",
+			wantErr: true,
+		},
+		{
+			name:    "empty",
+			reply:   "  \n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseCommit(tt.reply)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseCommit error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("ParseCommit = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRepair(t *testing.T) {
+	tests := []struct {
+		name  string
+		input StructuredCommit
+		want  StructuredCommit
+	}{
+		{
+			name:  "type alias and case",
+			input: StructuredCommit{Type: " Feature ", Subject: "add login"},
+			want:  StructuredCommit{Type: "feat", Subject: "add login"},
+		},
+		{
+			name:  "scope spelling",
+			input: StructuredCommit{Type: "fix", Scope: "Auth Service", Subject: "x"},
+			want:  StructuredCommit{Type: "fix", Scope: "auth-service", Subject: "x"},
+		},
+		{
+			name:  "trailing period",
+			input: StructuredCommit{Type: "fix", Subject: "close file."},
+			want:  StructuredCommit{Type: "fix", Subject: "close file"},
+		},
+		{
+			name:  "multi-line subject moves to body",
+			input: StructuredCommit{Type: "fix", Subject: "close file\nthe handle leaked", Body: "more"},
+			want:  StructuredCommit{Type: "fix", Subject: "close file", Body: "the handle leaked\n\nmore"},
+		},
+		{
+			name:  "header repeated in subject",
+			input: StructuredCommit{Type: "feat", Subject: "feat(api): add login"},
+			want:  StructuredCommit{Type: "feat", Subject: "add login"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.input.Repair(); got != tt.want {
+				t.Errorf("Repair = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestForce(t *testing.T) {
+	long := strings.Repeat("word ", 30)
+
+	tests := []struct {
+		name  string
+		input StructuredCommit
+		check func(t *testing.T, got StructuredCommit)
+	}{
+		{
+			name:  "unknown type becomes chore",
+			input: StructuredCommit{Type: "wip", Subject: "stuff"},
+			check: func(t *testing.T, got StructuredCommit) {
+				if got.Type != "chore" {
+					t.Errorf("type = %q, want chore", got.Type)
+				}
+			},
+		},
+		{
+			name:  "invalid scope dropped",
+			input: StructuredCommit{Type: "fix", Scope: "(bad)", Subject: "x"},
+			check: func(t *testing.T, got StructuredCommit) {
+				if got.Scope != "" {
+					t.Errorf("scope = %q, want none", got.Scope)
+				}
+			},
+		},
+		{
+			name:  "empty subject filled",
+			input: StructuredCommit{Type: "fix"},
+			check: func(t *testing.T, got StructuredCommit) {
+				if got.Subject == "" {
+					t.Error("subject is empty")
+				}
+			},
+		},
+		{
+			name:  "long header drops scope then truncates",
+			input: StructuredCommit{Type: "feat", Scope: "api", Subject: long},
+			check: func(t *testing.T, got StructuredCommit) {
+				if got.Scope != "" {
+					t.Errorf("scope = %q, want it dropped first", got.Scope)
+				}
+				if !strings.HasPrefix(long, got.Subject) || strings.HasSuffix(got.Subject, " ") {
+					t.Errorf("subject %q is not cut at a word boundary", got.Subject)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.input.Force()
+			if problems := got.Validate(); problems != nil {
+				t.Errorf("Force result %q is invalid: %v", got.Header(), problems)
+			}
+			tt.check(t, got)
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	commit := StructuredCommit{Type: "feat", Scope: "api", Subject: "add login", Body: "Adds a form.", Breaking: Breaking{Is: true, Note: "drops v1"}}
+	want := "feat(api)!: add login\n\nAdds a form.\n\nBREAKING CHANGE: drops v1"
+	if got := commit.Render(); got != want {
+		t.Errorf("Render = %q, want %q", got, want)
+	}
+
+	parsed, err := ParseCommit(want)
+	if err != nil {
+		t.Fatalf("ParseCommit: %v", err)
+	}
+	if parsed != commit {
+		t.Errorf("ParseCommit(Render) = %+v, want %+v", parsed, commit)
+	}
+}
@@ -0,0 +1,375 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/gitexec"
+)
+
+// defaultDiffTokens caps the diff content sent with a commit prompt even when
+// the model's context would allow more; commit messages don't need more
+const defaultDiffTokens = 4000
+
+// promptReserveTokens is kept free for the rest of the prompt (file lists,
+// diffstat, prior subjects, instructions)
+const promptReserveTokens = 1024
+
+// minChunkTokens is the smallest useful piece of a diff; below it the rest of
+// the budget is not worth spending
+const minChunkTokens = 64
+
+// defaultContextTokens is assumed for models we know nothing about
+const defaultContextTokens = 8192
+
+// ollamaContextTokens is Ollama's default num_ctx, whatever the model supports
+const ollamaContextTokens = 4096
+
+// modelContextTokens maps model name prefixes to context window sizes;
+// longer prefixes are matched first
+var modelContextTokens = map[string]int{
+	"claude":      200000,
+	"gpt-4o":      128000,
+	"gpt-4.1":     1000000,
+	"gpt-4-turbo": 128000,
+	"gpt-4":       8192,
+	"gpt-3.5":     16385,
+	"o1":          128000,
+	"o3":          200000,
+	"o4":          200000,
+}
+
+// lockFiles are dependency lockfiles; their diffs say nothing about intent
+var lockFiles = map[string]bool{
+	"go.sum":            true,
+	"package-lock.json": true,
+	"yarn.lock":         true,
+	"pnpm-lock.yaml":    true,
+	"Cargo.lock":        true,
+	"poetry.lock":       true,
+	"Pipfile.lock":      true,
+	"uv.lock":           true,
+	"Gemfile.lock":      true,
+	"composer.lock":     true,
+	"flake.lock":        true,
+}
+
+// generatedSuffixes and generatedDirs mark generated or vendored code
+var (
+	generatedSuffixes = []string{".pb.go", ".pb.gw.go", "_gen.go", "_generated.go", ".gen.go", ".min.js", ".min.css", ".map", ".snap"}
+	generatedDirs     = []string{"vendor/", "node_modules/", "dist/", "build/", "third_party/"}
+	testMarkers       = []string{"_test.go", ".test.", ".spec.", "test/", "tests/", "__tests__/"}
+	docExtensions     = map[string]bool{".md": true, ".txt": true, ".rst": true, ".adoc": true}
+	configExtensions  = map[string]bool{".json": true, ".yaml": true, ".yml": true, ".toml": true, ".ini": true, ".cfg": true, ".conf": true, ".xml": true, ".csv": true}
+)
+
+// File priorities for diff selection, most useful first
+const (
+	prioritySource = iota
+	priorityTest
+	priorityDocs
+	prioritySkip
+)
+
+// ContextTokens returns the context window assumed for the configured model.
+// For a provider chain it is the smallest window in the chain, so a prompt
+// built for the first provider still fits its fallbacks.
+func (c Config) ContextTokens() int {
+	if c.ContextSize > 0 {
+		return c.ContextSize
+	}
+
+	smallest := 0
+	for _, link := range c.providerChain() {
+		if link.Provider == "none" || link.Provider == "exec" {
+			continue
+		}
+		if tokens := link.modelContextTokens(); smallest == 0 || tokens < smallest {
+			smallest = tokens
+		}
+	}
+	if smallest > 0 {
+		return smallest
+	}
+	return c.modelContextTokens()
+}
+
+// modelContextTokens looks up the context window of a single provider's model
+func (c Config) modelContextTokens() int {
+	if c.Provider == "ollama" {
+		return ollamaContextTokens
+	}
+
+	model := strings.ToLower(c.Model)
+	best := ""
+	for prefix := range modelContextTokens {
+		if strings.HasPrefix(model, prefix) && len(prefix) > len(best) {
+			best = prefix
+		}
+	}
+	if best == "" {
+		return defaultContextTokens
+	}
+	return modelContextTokens[best]
+}
+
+// DiffBudget returns how many tokens of staged diff a commit prompt may carry:
+// what the context window leaves after MaxTokens and the rest of the prompt,
+// capped at DiffTokens (or defaultDiffTokens). 0 means no diff content.
+func (c Config) DiffBudget() int {
+	if c.DiffTokens < 0 {
+		return 0
+	}
+
+	limit := c.DiffTokens
+	if limit == 0 {
+		limit = defaultDiffTokens
+	}
+
+	available := c.ContextTokens() - c.MaxTokens - promptReserveTokens
+	if available < limit {
+		limit = available
+	}
+	if limit < minChunkTokens {
+		return 0
+	}
+	return limit
+}
+
+// EstimateTokens approximates the token count of text (~4 bytes per token)
+func EstimateTokens(text string) int {
+	return (len(text) + 3) / 4
+}
+
+// StagedDiff reads the staged diff of a repository and selects what fits in
+// budget tokens. It returns the selected diff and the files left out, each
+// with the reason, e.g. "go.sum (lockfile)".
+func StagedDiff(ctx context.Context, repoPath string, budget int) (string, []string) {
+	if budget <= 0 {
+		return "", nil
+	}
+	diff, err := gitexec.DiffCached(ctx, repoPath)
+	if err != nil || diff == "" {
+		return "", nil
+	}
+	return SelectDiff(diff, budget)
+}
+
+// fileDiff is one file's section of a unified diff
+type fileDiff struct {
+	path     string
+	header   []string
+	hunks    [][]string
+	binary   bool
+	priority int
+	reason   string
+}
+
+// SelectDiff picks the most useful parts of a unified diff within budget
+// tokens. Source files come first, then tests, then docs and config;
+// lockfiles, generated, vendored and binary files are never included. A file
+// that does not fit is cut at a hunk boundary (a single oversized hunk is
+// truncated) and the cut is noted inline; files that get no room at all are
+// returned as omitted.
+func SelectDiff(diff string, budget int) (string, []string) {
+	files := parseDiff(diff)
+	for i := range files {
+		files[i].priority, files[i].reason = classifyFile(files[i])
+	}
+	// Small, focused changes first so one huge file cannot crowd them out
+	sort.SliceStable(files, func(i, j int) bool {
+		if files[i].priority != files[j].priority {
+			return files[i].priority < files[j].priority
+		}
+		return files[i].size() < files[j].size()
+	})
+
+	included := 0
+	for _, file := range files {
+		if file.priority != prioritySkip {
+			included++
+		}
+	}
+
+	// No single file may take more than half the budget while others wait,
+	// but the last files get whatever the earlier ones left over
+	perFile := max(budget/2, minChunkTokens*4)
+
+	var out []string
+	var omitted []string
+	remaining := budget
+
+	for _, file := range files {
+		if file.priority == prioritySkip {
+			omitted = append(omitted, fmt.Sprintf("%s (%s)", file.path, file.reason))
+			continue
+		}
+
+		limit := min(remaining, max(perFile, remaining/included))
+		included--
+		section := renderFile(file, limit)
+		if section == "" {
+			omitted = append(omitted, fmt.Sprintf("%s (over budget)", file.path))
+			continue
+		}
+		out = append(out, section)
+		remaining -= EstimateTokens(section)
+	}
+
+	return strings.Join(out, "\n"), omitted
+}
+
+// size is the number of diff lines in the file's hunks
+func (f fileDiff) size() int {
+	return countLines(f.hunks)
+}
+
+// renderFile renders as much of a file's diff as fits in limit tokens, or ""
+// when not even the start of its first hunk fits
+func renderFile(file fileDiff, limit int) string {
+	header := strings.Join(file.header, "\n")
+	used := EstimateTokens(header) + 1
+	if used+minChunkTokens > limit {
+		return ""
+	}
+
+	lines := append([]string{}, file.header...)
+	for i, hunk := range file.hunks {
+		text := strings.Join(hunk, "\n")
+		cost := EstimateTokens(text) + 1
+		if used+cost <= limit {
+			lines = append(lines, hunk...)
+			used += cost
+			continue
+		}
+
+		if i == 0 {
+			// Chunk an oversized first hunk rather than dropping the file
+			kept, total := truncateLines(hunk, limit-used-minChunkTokens/4)
+			lines = append(lines, kept...)
+			lines = append(lines, fmt.Sprintf("... hunk truncated, %d of %d lines shown", len(kept), total))
+			i++
+		}
+		if rest := file.hunks[i:]; len(rest) > 0 {
+			lines = append(lines, fmt.Sprintf("... %d more hunks (%d lines) not shown", len(rest), countLines(rest)))
+		}
+		break
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// truncateLines keeps the leading lines that fit in limit tokens and returns
+// them with the original line count
+func truncateLines(lines []string, limit int) ([]string, int) {
+	size := 0
+	for i, line := range lines {
+		size += len(line) + 1
+		if (size+3)/4 > limit {
+			return lines[:i], len(lines)
+		}
+	}
+	return lines, len(lines)
+}
+
+func countLines(hunks [][]string) int {
+	n := 0
+	for _, hunk := range hunks {
+		n += len(hunk)
+	}
+	return n
+}
+
+// parseDiff splits "git diff" output into per-file headers and hunks. Index
+// lines are dropped since they cost tokens and mean nothing to a model.
+func parseDiff(diff string) []fileDiff {
+	var files []fileDiff
+	var current *fileDiff
+
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			files = append(files, fileDiff{path: diffGitPath(line), header: []string{line}})
+			current = &files[len(files)-1]
+		case current == nil:
+			continue
+		case strings.HasPrefix(line, "@@"):
+			current.hunks = append(current.hunks, []string{line})
+		case len(current.hunks) > 0:
+			last := len(current.hunks) - 1
+			current.hunks[last] = append(current.hunks[last], line)
+		case strings.HasPrefix(line, "index "):
+			continue
+		case strings.HasPrefix(line, "Binary files ") || strings.HasPrefix(line, "GIT binary patch"):
+			current.binary = true
+			current.header = append(current.header, line)
+		case strings.HasPrefix(line, "+++ b/"):
+			current.path = strings.TrimPrefix(line, "+++ b/")
+			current.header = append(current.header, line)
+		default:
+			current.header = append(current.header, line)
+		}
+	}
+	return files
+}
+
+// diffGitPath takes the destination path from a "diff --git a/x b/x" line
+func diffGitPath(line string) string {
+	if i := strings.LastIndex(line, " b/"); i >= 0 {
+		return line[i+3:]
+	}
+	return strings.TrimPrefix(line, "diff --git ")
+}
+
+// classifyFile ranks a file for diff selection and, for files that are never
+// included, says why
+func classifyFile(file fileDiff) (int, string) {
+	name := path.Base(file.path)
+	switch {
+	case file.binary:
+		return prioritySkip, "binary"
+	case lockFiles[name]:
+		return prioritySkip, "lockfile"
+	case isGenerated(file):
+		return prioritySkip, "generated"
+	}
+
+	for _, marker := range testMarkers {
+		if strings.Contains(file.path, marker) {
+			return priorityTest, ""
+		}
+	}
+
+	ext := strings.ToLower(path.Ext(name))
+	if docExtensions[ext] || configExtensions[ext] {
+		return priorityDocs, ""
+	}
+	return prioritySource, ""
+}
+
+func isGenerated(file fileDiff) bool {
+	for _, suffix := range generatedSuffixes {
+		if strings.HasSuffix(file.path, suffix) {
+			return true
+		}
+	}
+	for _, dir := range generatedDirs {
+		if strings.HasPrefix(file.path, dir) || strings.Contains(file.path, "/"+dir) {
+			return true
+		}
+	}
+
+	// The Go convention, also used by many other generators, within the
+	// first lines of the file
+	if len(file.hunks) > 0 {
+		for _, line := range file.hunks[0][1:min(len(file.hunks[0]), 12)] {
+			if strings.Contains(line, "Code generated") && strings.Contains(line, "DO NOT EDIT") {
+				return true
+			}
+		}
+	}
+	return false
+}
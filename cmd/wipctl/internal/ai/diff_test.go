@@ -0,0 +1,176 @@
+package ai
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// fileDiffText builds a one-hunk diff for path with n added lines
+func fileDiffText(path string, n int) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "diff --git a/%s b/%s\n", path, path)
+	sb.WriteString("index 1111111..2222222 100644\n")
+	fmt.Fprintf(&sb, "--- a/%s\n+++ b/%s\n", path, path)
+	fmt.Fprintf(&sb, "@@ -1,0 +1,%d @@\n", n)
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&sb, "+line %d of %s\n", i, path)
+	}
+	return sb.String()
+}
+
+func TestParseDiff(t *testing.T) {
+	diff := fileDiffText("main.go", 2) +
+		"diff --git a/logo.png b/logo.png\nindex 1111111..2222222 100644\nBinary files a/logo.png and b/logo.png differ\n" +
+		"diff --git a/old name.txt b/new name.txt\nsimilarity index 90%\nrename from old name.txt\nrename to new name.txt\n"
+
+	files := parseDiff(diff)
+	if len(files) != 3 {
+		t.Fatalf("parseDiff returned %d files, want 3", len(files))
+	}
+
+	tests := []struct {
+		path   string
+		binary bool
+		hunks  int
+	}{
+		{path: "main.go", hunks: 1},
+		{path: "logo.png", binary: true},
+		{path: "new name.txt"},
+	}
+	for i, tt := range tests {
+		file := files[i]
+		if file.path != tt.path || file.binary != tt.binary || len(file.hunks) != tt.hunks {
+			t.Errorf("file %d = {%q binary=%v hunks=%d}, want {%q binary=%v hunks=%d}",
+				i, file.path, file.binary, len(file.hunks), tt.path, tt.binary, tt.hunks)
+		}
+		for _, line := range file.header {
+			if strings.HasPrefix(line, "index ") {
+				t.Errorf("file %s kept index line %q", file.path, line)
+			}
+		}
+	}
+	if got := len(files[0].hunks[0]); got != 3 {
+		t.Errorf("main.go hunk has %d lines, want header plus 2", got)
+	}
+}
+
+func TestClassifyFile(t *testing.T) {
+	tests := []struct {
+		path     string
+		binary   bool
+		first    string
+		priority int
+		reason   string
+	}{
+		{path: "cmd/main.go", priority: prioritySource},
+		{path: "cmd/main_test.go", priority: priorityTest},
+		{path: "web/__tests__/app.js", priority: priorityTest},
+		{path: "README.md", priority: priorityDocs},
+		{path: "config/app.yaml", priority: priorityDocs},
+		{path: "go.sum", priority: prioritySkip, reason: "lockfile"},
+		{path: "web/package-lock.json", priority: prioritySkip, reason: "lockfile"},
+		{path: "api/v1/api.pb.go", priority: prioritySkip, reason: "generated"},
+		{path: "vendor/x/y.go", priority: prioritySkip, reason: "generated"},
+		{path: "web/node_modules/a/index.js", priority: prioritySkip, reason: "generated"},
+		{path: "mocks.go", first: "+// Code generated by mockgen. DO NOT EDIT.", priority: prioritySkip, reason: "generated"},
+		{path: "logo.png", binary: true, priority: prioritySkip, reason: "binary"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			file := fileDiff{path: tt.path, binary: tt.binary}
+			if tt.first != "" {
+				file.hunks = [][]string{{"@@ -0,0 +1,1 @@", tt.first}}
+			}
+			priority, reason := classifyFile(file)
+			if priority != tt.priority || reason != tt.reason {
+				t.Errorf("classifyFile = (%d, %q), want (%d, %q)", priority, reason, tt.priority, tt.reason)
+			}
+		})
+	}
+}
+
+func TestSelectDiff(t *testing.T) {
+	tests := []struct {
+		name        string
+		diff        string
+		budget      int
+		contains    []string
+		notContains []string
+		omitted     []string
+	}{
+		{
+			name:     "everything fits",
+			diff:     fileDiffText("main.go", 3) + fileDiffText("README.md", 3),
+			budget:   4000,
+			contains: []string{"+line 2 of main.go", "+line 2 of README.md"},
+		},
+		{
+			name:        "skipped files reported with reason",
+			diff:        fileDiffText("main.go", 3) + fileDiffText("go.sum", 3) + fileDiffText("dist/app.js", 3),
+			budget:      4000,
+			contains:    []string{"+line 2 of main.go"},
+			notContains: []string{"go.sum b/go.sum", "of dist/app.js"},
+			omitted:     []string{"go.sum (lockfile)", "dist/app.js (generated)"},
+		},
+		{
+			name:        "source before docs when over budget",
+			diff:        fileDiffText("README.md", 200) + fileDiffText("main.go", 5),
+			budget:      400,
+			contains:    []string{"+line 4 of main.go", "hunk truncated"},
+			notContains: []string{"+line 199 of README.md"},
+		},
+		{
+			name:    "no room at all",
+			diff:    fileDiffText("main.go", 5),
+			budget:  10,
+			omitted: []string{"main.go (over budget)"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selected, omitted := SelectDiff(tt.diff, tt.budget)
+			for _, want := range tt.contains {
+				if !strings.Contains(selected, want) {
+					t.Errorf("selected diff lacks %q:\n%s", want, selected)
+				}
+			}
+			for _, unwanted := range tt.notContains {
+				if strings.Contains(selected, unwanted) {
+					t.Errorf("selected diff contains %q:\n%s", unwanted, selected)
+				}
+			}
+			if !reflect.DeepEqual(omitted, tt.omitted) {
+				t.Errorf("omitted = %q, want %q", omitted, tt.omitted)
+			}
+			if EstimateTokens(selected) > tt.budget {
+				t.Errorf("selected %d tokens, budget %d", EstimateTokens(selected), tt.budget)
+			}
+		})
+	}
+}
+
+func TestDiffBudget(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+		want   int
+	}{
+		{name: "default cap", config: Config{Provider: "openai", Model: "gpt-4o"}, want: defaultDiffTokens},
+		{name: "configured cap", config: Config{Provider: "openai", Model: "gpt-4o", DiffTokens: 10000}, want: 10000},
+		{name: "disabled", config: Config{Provider: "openai", Model: "gpt-4o", DiffTokens: -1}, want: 0},
+		{name: "small context", config: Config{Provider: "ollama", MaxTokens: 1000}, want: ollamaContextTokens - 1000 - promptReserveTokens},
+		{name: "context exhausted", config: Config{Provider: "openai", Model: "gpt-4", MaxTokens: 8000}, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.config.DiffBudget(); got != tt.want {
+				t.Errorf("DiffBudget = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -46,6 +46,17 @@ func (ai *Integration) GenerateSynopsis(ctx context.Context, results map[string]
 	return ai.generator.Synopsis(ctx, synopsisInput)
 }
 
+// StreamSynopsis creates an AI-powered workspace synopsis, passing text to
+// onChunk as the provider generates it
+func (ai *Integration) StreamSynopsis(ctx context.Context, results map[string]*gitexec.RepoStatus, onChunk StreamFunc) (string, error) {
+	if !ai.IsEnabled() {
+		return "", ErrAINotEnabled
+	}
+
+	synopsisInput := ai.buildSynopsisInput(results)
+	return StreamSynopsis(ctx, ai.generator, synopsisInput, onChunk)
+}
+
 // GenerateCommitMessage creates an AI-powered commit message
 func (ai *Integration) GenerateCommitMessage(ctx context.Context, input CommitMsgInput) (string, error) {
 	if !ai.IsEnabled() {
@@ -64,6 +75,15 @@ func (ai *Integration) GeneratePRReview(ctx context.Context, input PRReviewInput
 	return ai.generator.PRReview(ctx, input)
 }
 
+// ProposeMerge asks the AI provider for a resolved version of a conflicted file
+func (ai *Integration) ProposeMerge(ctx context.Context, input MergeInput) (string, error) {
+	if !ai.IsEnabled() {
+		return "", ErrAINotEnabled
+	}
+
+	return ai.generator.ProposeMerge(ctx, input)
+}
+
 // buildSynopsisInput consolidates synopsis input building logic
 func (ai *Integration) buildSynopsisInput(results map[string]*gitexec.RepoStatus) SynopsisInput {
 	var repositories []RepoSummary
@@ -0,0 +1,535 @@
+package ai
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Exec plugin protocols: a process per request reading one JSON document
+// from stdin, or a long-running JSON-RPC 2.0 peer on stdin and stdout
+const (
+	ExecOneShot = 1
+	ExecJSONRPC = 2
+)
+
+// DefaultExecTimeout bounds one plugin request; for streamed requests it is
+// the longest pause between partial results
+const DefaultExecTimeout = 60 * time.Second
+
+// pluginCloseTimeout is how long a plugin gets to exit once its stdin is
+// closed before it is killed
+const pluginCloseTimeout = 5 * time.Second
+
+// pluginCommands are the commands a plugin may list as capabilities
+var pluginCommands = []string{"commit", "synopsis", "prreview", "prdescription", "workspace", "merge"}
+
+// JSON-RPC error codes wipctl produces or interprets
+const (
+	rpcMethodNotFound = -32601
+)
+
+// PluginError is an error object returned by a JSON-RPC plugin. Plugins mark
+// transient failures as retryable so the provider chain retries or falls back.
+type PluginError struct {
+	Code       int
+	Message    string
+	Transient  bool          // data.retryable
+	RetryAfter time.Duration // data.retry_after, in seconds on the wire
+}
+
+func (e *PluginError) Error() string {
+	return fmt.Sprintf("plugin error %d: %s", e.Code, e.Message)
+}
+
+// Retryable reports whether the plugin said the request may succeed if sent again
+func (e *PluginError) Retryable() bool {
+	return e.Transient
+}
+
+func (e *PluginError) retryDelay() time.Duration {
+	return e.RetryAfter
+}
+
+// errPluginDown wraps failures to start, talk to or keep the plugin running;
+// like an unreachable endpoint they count against the provider's breaker
+var errPluginDown = errors.New("plugin unavailable")
+
+// rpcMessage is any JSON-RPC 2.0 request, response or notification
+type rpcMessage struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      *int64          `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  interface{}     `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    struct {
+		Retryable  bool    `json:"retryable"`
+		RetryAfter float64 `json:"retry_after"`
+	} `json:"data"`
+}
+
+// pluginInfo is the plugin's answer to the initialize handshake
+type pluginInfo struct {
+	Protocol     int      `json:"protocol"`
+	Name         string   `json:"name"`
+	Capabilities []string `json:"capabilities"`
+	Streaming    bool     `json:"streaming"`
+}
+
+// pluginResult is the result of a generation request
+type pluginResult struct {
+	Text  string `json:"text"`
+	Usage struct {
+		Model        string `json:"model"`
+		InputTokens  int    `json:"input_tokens"`
+		OutputTokens int    `json:"output_tokens"`
+	} `json:"usage"`
+}
+
+// pluginPartial is the params of a "partial" notification
+type pluginPartial struct {
+	ID   int64  `json:"id"`
+	Text string `json:"text"`
+}
+
+// pluginCall is a request waiting for its response. Partial results are
+// handed to the waiting caller, so onChunk never runs on the reader goroutine.
+type pluginCall struct {
+	partials chan string
+	done     chan rpcMessage
+	gone     chan struct{} // closed once the caller stopped waiting
+}
+
+// plugin is one running JSON-RPC plugin process, shared by every generator
+// using the same executable. It is started on first use and restarted on the
+// next request after it exits; it is expected to exit when stdin closes.
+// Timeouts belong to each request, since generators sharing the process may
+// configure different ones.
+type plugin struct {
+	path string
+
+	startMu sync.Mutex // held while starting, so requests wait for the handshake
+
+	mu      sync.Mutex
+	process *exec.Cmd
+	stdin   io.WriteCloser
+	exited  chan struct{}
+	exitErr error
+	info    pluginInfo
+	nextID  int64
+	pending map[int64]*pluginCall
+	stderr  []string // last lines, for error messages
+
+	writeMu sync.Mutex
+}
+
+var (
+	pluginsMu sync.Mutex
+	plugins   = make(map[string]*plugin)
+)
+
+// sharedPlugin returns the plugin process handle for an executable
+func sharedPlugin(path string) *plugin {
+	pluginsMu.Lock()
+	defer pluginsMu.Unlock()
+	p, ok := plugins[path]
+	if !ok {
+		p = &plugin{path: path}
+		plugins[path] = p
+	}
+	return p
+}
+
+// ClosePlugins stops every running JSON-RPC plugin. It is called before
+// wipctl exits, so plugins are not left to notice a closed pipe on their own.
+func ClosePlugins() {
+	pluginsMu.Lock()
+	running := make([]*plugin, 0, len(plugins))
+	for _, p := range plugins {
+		running = append(running, p)
+	}
+	pluginsMu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, p := range running {
+		wg.Add(1)
+		go func(p *plugin) {
+			defer wg.Done()
+			p.Close()
+		}(p)
+	}
+	wg.Wait()
+}
+
+// call sends one generation request, passing partial results to onChunk
+// when the plugin streams. timeout bounds the wait for any output.
+func (p *plugin) call(ctx context.Context, command string, input interface{}, onChunk StreamFunc, timeout time.Duration) (pluginResult, error) {
+	var result pluginResult
+	info, err := p.start(ctx, timeout)
+	if err != nil {
+		return result, err
+	}
+	if !containsString(info.Capabilities, command) {
+		return result, &PluginError{Code: rpcMethodNotFound, Message: fmt.Sprintf("%s does not support %s", p.describe(info), command)}
+	}
+
+	params := map[string]interface{}{
+		"input":  input,
+		"stream": onChunk != nil && info.Streaming,
+	}
+	response, err := p.roundTrip(ctx, command, params, onChunk, timeout)
+	if err != nil {
+		return result, err
+	}
+	if err := json.Unmarshal(response, &result); err != nil {
+		return result, fmt.Errorf("%w: decode %s result: %v", errPluginDown, command, err)
+	}
+	return result, nil
+}
+
+// start launches the plugin and completes the handshake unless it is running
+func (p *plugin) start(ctx context.Context, timeout time.Duration) (pluginInfo, error) {
+	p.startMu.Lock()
+	defer p.startMu.Unlock()
+
+	p.mu.Lock()
+	if p.exited != nil {
+		select {
+		case <-p.exited:
+			slog.Warn("AI plugin exited, restarting", "plugin", p.path, "error", p.exitErr)
+		default:
+			info := p.info
+			p.mu.Unlock()
+			return info, nil
+		}
+	}
+
+	cmd := exec.Command(p.path)
+	stdin, err := cmd.StdinPipe()
+	if err == nil {
+		var stdout, stderr io.ReadCloser
+		if stdout, err = cmd.StdoutPipe(); err == nil {
+			if stderr, err = cmd.StderrPipe(); err == nil {
+				err = cmd.Start()
+				if err == nil {
+					p.process = cmd
+					p.stdin = stdin
+					p.exited = make(chan struct{})
+					p.pending = make(map[int64]*pluginCall)
+					p.info = pluginInfo{}
+					p.stderr = nil
+					go p.readStderr(stderr)
+					go p.readStdout(cmd, stdout, p.exited)
+				}
+			}
+		}
+	}
+	p.mu.Unlock()
+	if err != nil {
+		return pluginInfo{}, fmt.Errorf("%w: start %s: %v", errPluginDown, p.path, err)
+	}
+
+	response, err := p.roundTrip(ctx, "initialize", map[string]interface{}{
+		"protocol":     ExecJSONRPC,
+		"client":       "wipctl",
+		"capabilities": pluginCommands,
+	}, nil, timeout)
+	var info pluginInfo
+	if err == nil {
+		err = json.Unmarshal(response, &info)
+	}
+	if err == nil && info.Protocol != ExecJSONRPC {
+		err = fmt.Errorf("plugin speaks protocol %d, want %d", info.Protocol, ExecJSONRPC)
+	}
+	if err != nil {
+		p.kill()
+		<-p.exited
+		if !errors.Is(err, errPluginDown) {
+			err = fmt.Errorf("%w: handshake with %s: %v", errPluginDown, p.path, err)
+		}
+		return pluginInfo{}, err
+	}
+
+	p.mu.Lock()
+	p.info = info
+	p.mu.Unlock()
+	slog.Info("AI plugin started", "plugin", p.describe(info), "capabilities", strings.Join(info.Capabilities, ","), "streaming", info.Streaming)
+	return info, nil
+}
+
+// kill stops a plugin that failed the handshake
+func (p *plugin) kill() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.stdin.Close()
+	p.process.Process.Kill() //nolint:errcheck // it may have exited already
+}
+
+// Close asks a running plugin to exit by closing its stdin and waits for it,
+// killing it when it does not exit within pluginCloseTimeout
+func (p *plugin) Close() {
+	p.startMu.Lock()
+	defer p.startMu.Unlock()
+
+	p.mu.Lock()
+	exited := p.exited
+	p.mu.Unlock()
+	if exited == nil {
+		return
+	}
+	select {
+	case <-exited:
+		return
+	default:
+	}
+
+	p.mu.Lock()
+	p.stdin.Close()
+	p.mu.Unlock()
+	select {
+	case <-exited:
+	case <-time.After(pluginCloseTimeout):
+		slog.Warn("AI plugin did not exit, killing it", "plugin", p.path)
+		p.kill()
+		<-exited
+	}
+}
+
+// roundTrip sends a request and waits for its response. The wait ends with
+// ctx, when the plugin exits, or after the timeout without any output.
+func (p *plugin) roundTrip(ctx context.Context, method string, params interface{}, onChunk StreamFunc, timeout time.Duration) (json.RawMessage, error) {
+	call := &pluginCall{partials: make(chan string), done: make(chan rpcMessage, 1), gone: make(chan struct{})}
+
+	p.mu.Lock()
+	exited := p.exited
+	p.nextID++
+	id := p.nextID
+	p.pending[id] = call
+	p.mu.Unlock()
+	defer p.forget(id)
+
+	if err := p.send(rpcMessage{JSONRPC: "2.0", ID: &id, Method: method, Params: params}); err != nil {
+		return nil, fmt.Errorf("%w: send %s: %v", errPluginDown, method, err)
+	}
+
+	idle := time.NewTimer(timeout)
+	defer idle.Stop()
+	for {
+		select {
+		case response := <-call.done:
+			if response.Error != nil {
+				return nil, &PluginError{
+					Code:       response.Error.Code,
+					Message:    response.Error.Message,
+					Transient:  response.Error.Data.Retryable,
+					RetryAfter: time.Duration(response.Error.Data.RetryAfter * float64(time.Second)),
+				}
+			}
+			return response.Result, nil
+		case text := <-call.partials:
+			if onChunk != nil {
+				onChunk(text)
+			}
+			if !idle.Stop() {
+				<-idle.C
+			}
+			idle.Reset(timeout)
+		case <-exited:
+			return nil, p.exitError()
+		case <-idle.C:
+			p.cancel(id)
+			return nil, fmt.Errorf("%w: no response to %s from %s in %s", errPluginDown, method, p.path, timeout)
+		case <-ctx.Done():
+			p.cancel(id)
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// send writes one message as a line of JSON
+func (p *plugin) send(message rpcMessage) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	stdin := p.stdin
+	p.mu.Unlock()
+
+	p.writeMu.Lock()
+	defer p.writeMu.Unlock()
+	_, err = stdin.Write(append(data, '\n'))
+	return err
+}
+
+// cancel tells the plugin a request is no longer wanted; plugins may ignore it
+func (p *plugin) cancel(id int64) {
+	p.send(rpcMessage{JSONRPC: "2.0", Method: "cancel", Params: map[string]int64{"id": id}}) //nolint:errcheck // best effort
+}
+
+func (p *plugin) forget(id int64) {
+	p.mu.Lock()
+	if call, ok := p.pending[id]; ok {
+		close(call.gone)
+		delete(p.pending, id)
+	}
+	p.mu.Unlock()
+}
+
+// readStdout dispatches responses and partial results until the plugin exits
+func (p *plugin) readStdout(cmd *exec.Cmd, stdout io.Reader, exited chan struct{}) {
+	reader := bufio.NewReaderSize(stdout, 64<<10)
+	var readErr error
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			p.dispatch(line)
+		}
+		if err != nil {
+			if err != io.EOF {
+				readErr = err
+			}
+			break
+		}
+	}
+
+	waitErr := cmd.Wait()
+	p.mu.Lock()
+	switch {
+	case readErr != nil:
+		p.exitErr = readErr
+	case waitErr != nil:
+		p.exitErr = waitErr
+	default:
+		p.exitErr = errors.New("exited")
+	}
+	p.mu.Unlock()
+	close(exited)
+}
+
+// dispatch routes one message from the plugin
+func (p *plugin) dispatch(line []byte) {
+	var message struct {
+		ID     *int64          `json:"id"`
+		Method string          `json:"method"`
+		Params json.RawMessage `json:"params"`
+		Result json.RawMessage `json:"result"`
+		Error  *rpcError       `json:"error"`
+	}
+	if err := json.Unmarshal(line, &message); err != nil {
+		slog.Warn("AI plugin sent invalid JSON", "plugin", p.path, "line", truncate(string(line), 200))
+		return
+	}
+
+	if message.Method == "partial" {
+		var partial pluginPartial
+		if err := json.Unmarshal(message.Params, &partial); err != nil {
+			return
+		}
+		if call := p.lookup(partial.ID); call != nil {
+			select {
+			case call.partials <- partial.Text:
+			case <-call.gone:
+			}
+		}
+		return
+	}
+
+	if message.ID == nil || message.Method != "" {
+		return // other notifications and requests from the plugin are not used
+	}
+	if call := p.lookup(*message.ID); call != nil {
+		// A plugin answering twice must not block the reader
+		select {
+		case call.done <- rpcMessage{Result: message.Result, Error: message.Error}:
+		default:
+			slog.Warn("AI plugin sent a duplicate response", "plugin", p.path, "id", *message.ID)
+		}
+	}
+}
+
+func (p *plugin) lookup(id int64) *pluginCall {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.pending[id]
+}
+
+// readStderr logs what the plugin writes to stderr and keeps the last lines
+func (p *plugin) readStderr(stderr io.Reader) {
+	scanner := bufio.NewScanner(stderr)
+	for scanner.Scan() {
+		line := scanner.Text()
+		slog.Info("AI plugin stderr", "plugin", p.path, "line", line)
+		p.mu.Lock()
+		p.stderr = append(p.stderr, line)
+		if len(p.stderr) > 5 {
+			p.stderr = p.stderr[1:]
+		}
+		p.mu.Unlock()
+	}
+}
+
+// exitError describes why the plugin stopped, with its last stderr output
+func (p *plugin) exitError() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	message := fmt.Sprintf("%s %v", p.path, p.exitErr)
+	if len(p.stderr) > 0 {
+		message += ": " + strings.Join(p.stderr, " | ")
+	}
+	return fmt.Errorf("%w: %s", errPluginDown, message)
+}
+
+// describe names the plugin for messages
+func (p *plugin) describe(info pluginInfo) string {
+	if info.Name != "" {
+		return info.Name
+	}
+	return p.path
+}
+
+// stderrTail returns the last non-empty lines of a one-shot plugin's stderr
+func stderrTail(stderr []byte, lines int) string {
+	var kept []string
+	for _, line := range strings.Split(strings.TrimSpace(string(stderr)), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			kept = append(kept, line)
+		}
+	}
+	if len(kept) > lines {
+		kept = kept[len(kept)-lines:]
+	}
+	return strings.Join(kept, " | ")
+}
+
+func containsString(items []string, item string) bool {
+	for _, candidate := range items {
+		if candidate == item {
+			return true
+		}
+	}
+	return false
+}
+
+func truncate(text string, max int) string {
+	if len(text) <= max {
+		return text
+	}
+	return text[:max] + "..."
+}
@@ -0,0 +1,98 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writePlugin writes a shell script plugin. Request ids are predictable: the
+// handshake is 1 and the first request 2.
+func writePlugin(t *testing.T, body string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "plugin.sh")
+	script := "#!/bin/sh\n" +
+		"read line\n" +
+		`echo '{"jsonrpc":"2.0","id":1,"result":{"protocol":2,"name":"test","capabilities":["synopsis"],"streaming":true}}'` + "\n" +
+		body
+	if err := os.WriteFile(path, []byte(script), 0755); err != nil {
+		t.Fatalf("write plugin: %v", err)
+	}
+	return path
+}
+
+func TestPluginStreamsPartials(t *testing.T) {
+	path := writePlugin(t, `read line
+echo '{"jsonrpc":"2.0","method":"partial","params":{"id":2,"text":"hello "}}'
+echo '{"jsonrpc":"2.0","method":"partial","params":{"id":2,"text":"world"}}'
+echo '{"jsonrpc":"2.0","id":2,"result":{"text":"hello world"}}'
+echo '{"jsonrpc":"2.0","id":2,"result":{"text":"duplicate"}}'
+read line
+`)
+	defer ClosePlugins()
+
+	var chunks []string
+	result, err := sharedPlugin(path).call(context.Background(), "synopsis", nil, func(chunk string) {
+		chunks = append(chunks, chunk)
+	}, 5*time.Second)
+	if err != nil {
+		t.Fatalf("call: %v", err)
+	}
+	if result.Text != "hello world" {
+		t.Errorf("result = %q, want %q", result.Text, "hello world")
+	}
+	if got := strings.Join(chunks, ""); got != "hello world" {
+		t.Errorf("streamed = %q, want %q", got, "hello world")
+	}
+}
+
+func TestPluginTimeoutIsPerRequest(t *testing.T) {
+	path := writePlugin(t, `read line
+read line
+`)
+	defer ClosePlugins()
+
+	started := time.Now()
+	_, err := sharedPlugin(path).call(context.Background(), "synopsis", nil, nil, 200*time.Millisecond)
+	if !errors.Is(err, errPluginDown) {
+		t.Fatalf("call error = %v, want a plugin timeout", err)
+	}
+	if elapsed := time.Since(started); elapsed > 3*time.Second {
+		t.Errorf("timed out after %s, want the request's 200ms", elapsed)
+	}
+}
+
+func TestClosePluginsStopsProcess(t *testing.T) {
+	path := writePlugin(t, `read line
+echo '{"jsonrpc":"2.0","id":2,"result":{"text":"ok"}}'
+read line
+`)
+	p := sharedPlugin(path)
+	if _, err := p.call(context.Background(), "synopsis", nil, nil, 5*time.Second); err != nil {
+		t.Fatalf("call: %v", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		ClosePlugins()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(pluginCloseTimeout + 2*time.Second):
+		t.Fatal("ClosePlugins did not return")
+	}
+
+	p.mu.Lock()
+	exited := p.exited
+	p.mu.Unlock()
+	select {
+	case <-exited:
+	default:
+		t.Error("plugin still running after ClosePlugins")
+	}
+}
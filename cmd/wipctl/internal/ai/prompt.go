@@ -0,0 +1,64 @@
+package ai
+
+import (
+	"fmt"
+
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/prompts"
+)
+
+// sharedPrompts are the prompt templates in use; the embedded defaults until
+// UsePrompts adds override directories
+var sharedPrompts, _ = prompts.Load()
+
+// UsePrompts makes generators read prompt overrides from dirs, the first
+// directory holding a prompt winning over later ones and the defaults.
+// Overrides that do not parse or render are returned and the default is used.
+func UsePrompts(dirs ...string) []error {
+	set, problems := prompts.Load(dirs...)
+	problems = append(problems, set.Check(CheckPrompt)...)
+	sharedPrompts = set
+	return problems
+}
+
+// Prompt returns the effective prompt template of a kind
+func Prompt(kind string) (*prompts.Prompt, bool) {
+	return sharedPrompts.Get(kind)
+}
+
+// promptInputs are the template data of each kind of request
+var promptInputs = map[string]interface{}{
+	KindCommit:           CommitMsgInput{},
+	KindSynopsis:         SynopsisInput{},
+	KindPRReview:         PRReviewInput{},
+	KindPRDescription:    PRReviewInput{},
+	KindWorkspaceContext: WorkspaceContextInput{},
+	KindMerge:            MergeInput{},
+}
+
+// CheckPrompt renders a prompt with empty input, catching references to
+// fields its input struct does not have
+func CheckPrompt(prompt *prompts.Prompt) error {
+	input, ok := promptInputs[prompt.Kind]
+	if !ok {
+		return fmt.Errorf("no input for %s prompt", prompt.Kind)
+	}
+	_, _, err := prompt.Render(input)
+	return err
+}
+
+// renderPrompt builds the system and user prompts of a request from its input
+func renderPrompt(kind string, input interface{}) (string, string, error) {
+	prompt, ok := sharedPrompts.Get(kind)
+	if !ok {
+		return "", "", fmt.Errorf("no %s prompt", kind)
+	}
+	return prompt.Render(input)
+}
+
+// promptKey identifies the prompt behind a kind of request in cache keys
+func promptKey(kind string) string {
+	if prompt, ok := sharedPrompts.Get(kind); ok {
+		return prompt.Key()
+	}
+	return ""
+}
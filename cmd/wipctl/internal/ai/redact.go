@@ -0,0 +1,529 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/config"
+)
+
+// ErrPromptShown is returned instead of a response when --show-prompt printed
+// the request rather than sending it
+var ErrPromptShown = errors.New("prompt shown, not sent (--show-prompt)")
+
+// defaultMasks catch common credentials wherever they appear in prompt text
+var defaultMasks = []config.Mask{
+	{Pattern: `-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`, Replace: "[PRIVATE KEY]"},
+	{Pattern: `\bAKIA[0-9A-Z]{16}\b`},
+	{Pattern: `\bgh[pousr]_[A-Za-z0-9]{36,}`},
+	{Pattern: `\bsk-[A-Za-z0-9_-]{20,}`},
+	{Pattern: `\bxox[abprs]-[A-Za-z0-9-]{10,}`},
+	{Pattern: `(?i)\b(bearer)\s+[A-Za-z0-9._~+/-]{20,}=*`, Replace: "$1 [REDACTED]"},
+	{Pattern: `(?i)\b(password|passwd|secret|api[_-]?key|access[_-]?token|auth[_-]?token)(["']?\s*[:=]\s*["']?)[^\s"']{6,}`, Replace: "$1$2[REDACTED]"},
+}
+
+// genericBranches say nothing about the work and are never hashed
+var genericBranches = map[string]bool{"main": true, "master": true, "develop": true, "trunk": true, "HEAD": true}
+
+var (
+	sharedRedaction  *config.Redaction
+	sharedShowPrompt bool
+)
+
+// UseRedaction makes generators built from LoadConfigFromEnv apply rules to
+// every request. With showPrompt set requests are printed, not sent. An
+// invalid mask is reported here; generators then refuse to send anything.
+func UseRedaction(rules *config.Redaction, showPrompt bool) error {
+	sharedRedaction = rules
+	sharedShowPrompt = showPrompt
+	_, err := compileMasks(rules)
+	return err
+}
+
+type mask struct {
+	re      *regexp.Regexp
+	replace string
+}
+
+func compileMasks(rules *config.Redaction) ([]mask, error) {
+	var masks []mask
+	var problems []string
+	for _, rule := range append(append([]config.Mask{}, defaultMasks...), rules.MaskRules()...) {
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%q: %v", rule.Pattern, err))
+			continue
+		}
+		replace := rule.Replace
+		if replace == "" {
+			replace = "[REDACTED]"
+		}
+		masks = append(masks, mask{re: re, replace: replace})
+	}
+	if len(problems) > 0 {
+		return masks, fmt.Errorf("invalid redaction mask: %s", strings.Join(problems, "; "))
+	}
+	return masks, nil
+}
+
+// isRemote reports whether a provider sends requests off this machine.
+// Exec plugins are the user's own programs and count as local.
+func (c Config) isRemote() bool {
+	switch c.Provider {
+	case "exec", "none":
+		return false
+	case "ollama":
+		endpoint := c.Endpoint
+		if endpoint == "" {
+			return false
+		}
+		parsed, err := url.Parse(endpoint)
+		if err != nil {
+			return true
+		}
+		host := parsed.Hostname()
+		if host == "localhost" {
+			return false
+		}
+		ip := net.ParseIP(host)
+		return ip == nil || !ip.IsLoopback()
+	}
+	return true
+}
+
+// redactingGenerator applies the redaction rules to every request before
+// its provider builds a prompt from it, and restores hashed names in replies
+type redactingGenerator struct {
+	inner    Generator
+	provider string
+	rules    *config.Redaction
+	masks    []mask
+	invalid  error
+	remote   bool
+	preview  bool
+}
+
+func newRedactingGenerator(inner Generator, config Config) Generator {
+	masks, err := compileMasks(config.Redaction)
+	return &redactingGenerator{
+		inner:    inner,
+		provider: config.Provider,
+		rules:    config.Redaction,
+		masks:    masks,
+		invalid:  err,
+		remote:   config.isRemote(),
+		preview:  config.ShowPrompt,
+	}
+}
+
+// begin starts redacting one request, refusing it when the rules are broken
+// or the repository is denylisted for this provider
+func (g *redactingGenerator) begin(ctx context.Context, repo string) (context.Context, *redaction, error) {
+	if g.invalid != nil {
+		return ctx, nil, fmt.Errorf("not sent, %w", g.invalid)
+	}
+	if repo != "" && g.denied(repo) {
+		return ctx, nil, fmt.Errorf("repository %s is denylisted for remote AI providers", repo)
+	}
+	if g.preview {
+		ctx = context.WithValue(ctx, previewKey{}, g.provider)
+	}
+	return ctx, &redaction{generator: g, tokens: make(map[string]string)}, nil
+}
+
+func (g *redactingGenerator) denied(repo string) bool {
+	return g.remote && g.rules.Denied(repo)
+}
+
+func (g *redactingGenerator) CommitMessage(ctx context.Context, input CommitMsgInput) (string, error) {
+	ctx, r, err := g.begin(ctx, input.Repo)
+	if err != nil {
+		return "", err
+	}
+	response, err := g.inner.CommitMessage(ctx, r.commit(input))
+	return r.restore(response), err
+}
+
+func (g *redactingGenerator) Synopsis(ctx context.Context, input SynopsisInput) (string, error) {
+	return g.StreamSynopsis(ctx, input, nil)
+}
+
+func (g *redactingGenerator) StreamSynopsis(ctx context.Context, input SynopsisInput, onChunk StreamFunc) (string, error) {
+	ctx, r, err := g.begin(ctx, "")
+	if err != nil {
+		return "", err
+	}
+	// Redact first: the stream can only restore tokens already registered
+	redacted := r.synopsis(input)
+	onChunk, flush := r.stream(onChunk)
+	response, err := StreamSynopsis(ctx, g.inner, redacted, onChunk)
+	flush()
+	return r.restore(response), err
+}
+
+func (g *redactingGenerator) PRReview(ctx context.Context, input PRReviewInput) (string, error) {
+	ctx, r, err := g.begin(ctx, input.Repo)
+	if err != nil {
+		return "", err
+	}
+	response, err := g.inner.PRReview(ctx, r.prReview(input))
+	return r.restore(response), err
+}
+
+func (g *redactingGenerator) PRDescription(ctx context.Context, input PRReviewInput) (string, error) {
+	ctx, r, err := g.begin(ctx, input.Repo)
+	if err != nil {
+		return "", err
+	}
+	response, err := g.inner.PRDescription(ctx, r.prReview(input))
+	return r.restore(response), err
+}
+
+func (g *redactingGenerator) WorkspaceContext(ctx context.Context, input WorkspaceContextInput) (string, error) {
+	return g.StreamWorkspaceContext(ctx, input, nil)
+}
+
+func (g *redactingGenerator) StreamWorkspaceContext(ctx context.Context, input WorkspaceContextInput, onChunk StreamFunc) (string, error) {
+	ctx, r, err := g.begin(ctx, "")
+	if err != nil {
+		return "", err
+	}
+	redacted := r.workspaceContext(input)
+	onChunk, flush := r.stream(onChunk)
+	response, err := StreamWorkspaceContext(ctx, g.inner, redacted, onChunk)
+	flush()
+	return r.restore(response), err
+}
+
+func (g *redactingGenerator) ProposeMerge(ctx context.Context, input MergeInput) (string, error) {
+	ctx, r, err := g.begin(ctx, input.Repo)
+	if err != nil {
+		return "", err
+	}
+	response, err := g.inner.ProposeMerge(ctx, r.merge(input))
+	return r.restore(response), err
+}
+
+// redaction is the state of one request: the hash token chosen for each
+// path and name, so the same value always gets the same token and replies
+// can be mapped back
+type redaction struct {
+	generator *redactingGenerator
+	tokens    map[string]string // original -> token
+	ordered   []string          // originals, longest first
+}
+
+// hash returns a stable token such as "repo-1a2b3c4d" for value
+func (r *redaction) hash(kind, value string) string {
+	salt := ""
+	if r.generator.rules != nil {
+		salt = r.generator.rules.Salt
+	}
+	sum := sha256.Sum256([]byte(salt + "\x00" + kind + "\x00" + value))
+	token := kind + "-" + hex.EncodeToString(sum[:4])
+	if kind == "file" {
+		token += path.Ext(value)
+	}
+	return token
+}
+
+func (r *redaction) register(kind, value string) string {
+	if value == "" {
+		return value
+	}
+	if token, ok := r.tokens[value]; ok {
+		return token
+	}
+	token := r.hash(kind, value)
+	r.tokens[value] = token
+	r.ordered = append(r.ordered, value)
+	sort.SliceStable(r.ordered, func(i, j int) bool { return len(r.ordered[i]) > len(r.ordered[j]) })
+	return token
+}
+
+// path hashes a file path when path hashing is on
+func (r *redaction) path(value string) string {
+	if !r.generator.rules.PathHashing() {
+		return r.text(value)
+	}
+	return r.register("file", value)
+}
+
+// name hashes a repo, branch or host name when name hashing is on
+func (r *redaction) name(kind, value string) string {
+	if !r.generator.rules.NameHashing() || genericBranches[value] {
+		return r.text(value)
+	}
+	return r.register(kind, value)
+}
+
+// paths registers every path in "git diff --name-status" output
+func (r *redaction) paths(nameStatus string) {
+	if !r.generator.rules.PathHashing() {
+		return
+	}
+	for _, line := range strings.Split(nameStatus, "\n") {
+		fields := strings.Split(line, "\t")
+		for _, field := range fields[1:] {
+			r.register("file", strings.TrimSpace(field))
+		}
+	}
+}
+
+// diffPaths registers every path in a unified diff
+func (r *redaction) diffPaths(diff string) {
+	if !r.generator.rules.PathHashing() {
+		return
+	}
+	for _, file := range parseDiff(diff) {
+		r.register("file", file.path)
+		for _, line := range file.header {
+			for _, prefix := range []string{"rename from ", "rename to ", "copy from ", "copy to ", "--- a/"} {
+				if rest, ok := strings.CutPrefix(line, prefix); ok {
+					r.register("file", rest)
+				}
+			}
+		}
+	}
+}
+
+// text masks free text, then replaces every registered path and name in it
+func (r *redaction) text(value string) string {
+	for _, m := range r.generator.masks {
+		value = m.re.ReplaceAllString(value, m.replace)
+	}
+	for _, original := range r.ordered {
+		value = replaceWord(value, original, r.tokens[original])
+	}
+	return value
+}
+
+func (r *redaction) texts(values []string) []string {
+	if values == nil {
+		return nil
+	}
+	out := make([]string, len(values))
+	for i, value := range values {
+		out[i] = r.text(value)
+	}
+	return out
+}
+
+// diffStat redacts "git diff --stat" output, whose long paths git shortens
+// to ".../suffix"
+func (r *redaction) diffStat(stat string) string {
+	if !r.generator.rules.PathHashing() {
+		return r.text(stat)
+	}
+	lines := strings.Split(stat, "\n")
+	for i, line := range lines {
+		name, rest, found := strings.Cut(line, " | ")
+		suffix, shortened := strings.CutPrefix(strings.TrimSpace(name), ".../")
+		if !found || !shortened {
+			lines[i] = r.text(line)
+			continue
+		}
+		token := r.hash("file", suffix)
+		for _, original := range r.ordered {
+			if strings.HasSuffix(original, "/"+suffix) {
+				token = r.tokens[original]
+				break
+			}
+		}
+		lines[i] = " " + token + " | " + r.text(rest)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (r *redaction) commit(input CommitMsgInput) CommitMsgInput {
+	r.paths(input.NameStatus)
+	r.diffPaths(input.Diff)
+	if r.generator.rules.PathHashing() {
+		for _, file := range input.Untracked {
+			r.register("file", file)
+		}
+	}
+
+	out := input
+	out.Repo = r.name("repo", input.Repo)
+	out.Branch = r.name("branch", input.Branch)
+	out.Host = r.name("host", input.Host)
+	out.NameStatus = r.text(input.NameStatus)
+	out.DiffStat = r.diffStat(input.DiffStat)
+	out.Untracked = r.texts(input.Untracked)
+	out.PriorSubjects = r.texts(input.PriorSubjects)
+	out.Diff = r.text(input.Diff)
+	out.DiffOmitted = r.texts(input.DiffOmitted)
+	return out
+}
+
+func (r *redaction) synopsis(input SynopsisInput) SynopsisInput {
+	out := input
+	out.Repositories = nil
+	for _, repo := range input.Repositories {
+		if r.generator.denied(repo.Name) {
+			continue
+		}
+		repo.Name = r.name("repo", repo.Name)
+		repo.Branch = r.name("branch", repo.Branch)
+		repo.Status = r.text(repo.Status)
+		out.Repositories = append(out.Repositories, repo)
+	}
+	return out
+}
+
+func (r *redaction) prReview(input PRReviewInput) PRReviewInput {
+	r.paths(input.NameStatus)
+
+	out := input
+	out.Repo = r.name("repo", input.Repo)
+	out.Branch = r.name("branch", input.Branch)
+	out.NameStatus = r.text(input.NameStatus)
+	out.DiffStat = r.diffStat(input.DiffStat)
+	out.CommitMsgs = r.texts(input.CommitMsgs)
+	return out
+}
+
+func (r *redaction) workspaceContext(input WorkspaceContextInput) WorkspaceContextInput {
+	out := input
+	out.Repositories = nil
+	for _, repo := range input.Repositories {
+		if r.generator.denied(repo.Name) {
+			continue
+		}
+		r.paths(repo.Changes)
+		repo.Name = r.name("repo", repo.Name)
+		repo.Branch = r.name("branch", repo.Branch)
+		repo.Status = r.text(repo.Status)
+		repo.RecentWork = r.texts(repo.RecentWork)
+		repo.Changes = r.text(repo.Changes)
+		out.Repositories = append(out.Repositories, repo)
+	}
+	return out
+}
+
+func (r *redaction) merge(input MergeInput) MergeInput {
+	out := input
+	out.Repo = r.name("repo", input.Repo)
+	out.Path = r.path(input.Path)
+	out.OursLabel = r.name("branch", input.OursLabel)
+	out.TheirsLabel = r.name("branch", input.TheirsLabel)
+	out.Base = r.text(input.Base)
+	out.Ours = r.text(input.Ours)
+	out.Theirs = r.text(input.Theirs)
+	return out
+}
+
+// restore maps hash tokens in a reply back to the original paths and names
+func (r *redaction) restore(text string) string {
+	if r == nil || len(r.tokens) == 0 || text == "" {
+		return text
+	}
+	pairs := make([]string, 0, 2*len(r.tokens))
+	for original, token := range r.tokens {
+		pairs = append(pairs, token, original)
+	}
+	return strings.NewReplacer(pairs...).Replace(text)
+}
+
+// stream restores tokens in streamed text. Tokens contain no whitespace, so
+// only the text after the last whitespace is held back; flush emits it.
+func (r *redaction) stream(onChunk StreamFunc) (StreamFunc, func()) {
+	if onChunk == nil || len(r.tokens) == 0 {
+		return onChunk, func() {}
+	}
+	var pending string
+	restored := func(chunk string) {
+		pending += chunk
+		if i := strings.LastIndexAny(pending, " \t\n"); i >= 0 {
+			onChunk(r.restore(pending[:i+1]))
+			pending = pending[i+1:]
+		}
+	}
+	flush := func() {
+		if pending != "" {
+			onChunk(r.restore(pending))
+			pending = ""
+		}
+	}
+	return restored, flush
+}
+
+// replaceWord replaces old in text where it is not part of a longer word,
+// so hashing the repo "api" leaves "rapid" alone
+func replaceWord(text, old, replacement string) string {
+	if old == "" || !strings.Contains(text, old) {
+		return text
+	}
+	var sb strings.Builder
+	for {
+		i := strings.Index(text, old)
+		if i < 0 {
+			sb.WriteString(text)
+			return sb.String()
+		}
+		end := i + len(old)
+		if (i > 0 && isWordByte(text[i-1])) || (end < len(text) && isWordByte(text[end])) {
+			sb.WriteString(text[:i+1])
+			text = text[i+1:]
+			continue
+		}
+		sb.WriteString(text[:i])
+		sb.WriteString(replacement)
+		text = text[end:]
+	}
+}
+
+func isWordByte(b byte) bool {
+	return b == '_' || b == '-' || b == '.' ||
+		('a' <= b && b <= 'z') || ('A' <= b && b <= 'Z') || ('0' <= b && b <= '9')
+}
+
+type previewKey struct{}
+
+var previewMu sync.Mutex
+
+// previewing returns the provider whose requests are printed instead of sent
+func previewing(ctx context.Context) (string, bool) {
+	provider, ok := ctx.Value(previewKey{}).(string)
+	return provider, ok
+}
+
+// showPrompt prints a request exactly as it would be sent, minus the
+// credentials in its headers
+func showPrompt(provider, target string, body []byte) {
+	var pretty bytes.Buffer
+	if json.Indent(&pretty, body, "", "  ") != nil {
+		pretty.Reset()
+		pretty.Write(body)
+	}
+
+	previewMu.Lock()
+	defer previewMu.Unlock()
+	fmt.Fprintf(os.Stderr, "──── %s: %s ────\n%s\n\n", provider, target, pretty.String())
+}
+
+// sendRequest sends a provider request, or prints it when the request is
+// being previewed with --show-prompt
+func sendRequest(req *http.Request, body []byte) (*http.Response, error) {
+	if provider, ok := previewing(req.Context()); ok {
+		showPrompt(provider, req.Method+" "+req.URL.String(), body)
+		return nil, ErrPromptShown
+	}
+	client := &http.Client{}
+	return client.Do(req)
+}
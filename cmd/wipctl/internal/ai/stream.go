@@ -0,0 +1,400 @@
+package ai
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// streamIdleTimeout aborts a stream that has delivered nothing for this long.
+// There is no overall limit: a long briefing is fine as long as it keeps coming.
+const streamIdleTimeout = 60 * time.Second
+
+// errStreamIdle is the cause of a stream aborted by the idle watchdog
+var errStreamIdle = fmt.Errorf("no output from AI provider for %s", streamIdleTimeout)
+
+// errStreamDone ends a stream early once the provider has said it is finished
+var errStreamDone = errors.New("stream done")
+
+// maxStreamLine bounds one SSE/NDJSON line; deltas are small but error
+// payloads and final usage events can be longer than bufio's default
+const maxStreamLine = 1 << 20
+
+// StreamFunc receives generated text as it arrives
+type StreamFunc func(chunk string)
+
+// Streamer is implemented by generators that can deliver long-form output
+// incrementally: SSE for Claude and OpenAI, NDJSON for Ollama
+type Streamer interface {
+	StreamSynopsis(ctx context.Context, input SynopsisInput, onChunk StreamFunc) (string, error)
+	StreamWorkspaceContext(ctx context.Context, input WorkspaceContextInput, onChunk StreamFunc) (string, error)
+}
+
+// StreamSynopsis generates a synopsis, passing text to onChunk as it arrives
+// when the generator streams and in one piece when it does not
+func StreamSynopsis(ctx context.Context, g Generator, input SynopsisInput, onChunk StreamFunc) (string, error) {
+	if s, ok := g.(Streamer); ok {
+		return s.StreamSynopsis(ctx, input, onChunk)
+	}
+
+	text, err := g.Synopsis(ctx, input)
+	if err == nil && onChunk != nil {
+		onChunk(text)
+	}
+	return text, err
+}
+
+// StreamWorkspaceContext generates a workspace briefing, passing text to
+// onChunk as it arrives when the generator streams and in one piece when it
+// does not
+func StreamWorkspaceContext(ctx context.Context, g Generator, input WorkspaceContextInput, onChunk StreamFunc) (string, error) {
+	if s, ok := g.(Streamer); ok {
+		return s.StreamWorkspaceContext(ctx, input, onChunk)
+	}
+
+	text, err := g.WorkspaceContext(ctx, input)
+	if err == nil && onChunk != nil {
+		onChunk(text)
+	}
+	return text, err
+}
+
+// stream is an open streaming HTTP response guarded by the idle watchdog
+type stream struct {
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+	timer  *time.Timer
+	body   io.ReadCloser
+}
+
+// openStream POSTs body as JSON and returns the streaming response. The
+// request is cancelled with ctx or when no line arrives for streamIdleTimeout.
+func openStream(ctx context.Context, url string, headers map[string]string, body interface{}) (*stream, error) {
+	jsonData, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("marshal request: %w", err)
+	}
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	timer := time.AfterFunc(streamIdleTimeout, func() { cancel(errStreamIdle) })
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		timer.Stop()
+		cancel(nil)
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	s := &stream{ctx: ctx, cancel: cancel, timer: timer}
+
+	resp, err := sendRequest(req, jsonData)
+	if err != nil {
+		// Ask for the cause first; Close cancels the context itself
+		err = s.cause(err)
+		s.Close()
+		return nil, fmt.Errorf("http request: %w", err)
+	}
+	s.body = resp.Body
+
+	if resp.StatusCode != http.StatusOK {
+		err := newHTTPError(resp)
+		s.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// Close stops the watchdog and releases the connection
+func (s *stream) Close() {
+	s.timer.Stop()
+	s.cancel(nil)
+	if s.body != nil {
+		s.body.Close()
+	}
+}
+
+// cause reports why the stream was cut off (Ctrl-C, idle watchdog) in
+// preference to the read error that followed
+func (s *stream) cause(err error) error {
+	if cause := context.Cause(s.ctx); cause != nil {
+		return cause
+	}
+	return err
+}
+
+// lines calls fn for each non-empty line until the body ends or fn returns
+// errStreamDone
+func (s *stream) lines(fn func(line string) error) error {
+	scanner := bufio.NewScanner(s.body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxStreamLine)
+	for scanner.Scan() {
+		s.timer.Reset(streamIdleTimeout)
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" {
+			continue
+		}
+		if err := fn(line); err != nil {
+			if errors.Is(err, errStreamDone) {
+				return nil
+			}
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("read stream: %w", s.cause(err))
+	}
+	// A cancelled body can also end looking like a clean EOF
+	if err := context.Cause(s.ctx); err != nil {
+		return err
+	}
+	return nil
+}
+
+// sse calls fn with the payload of each server-sent event "data:" line
+func (s *stream) sse(fn func(data []byte) error) error {
+	return s.lines(func(line string) error {
+		data, ok := strings.CutPrefix(line, "data:")
+		if !ok {
+			return nil // event names, comments, ids and retry hints
+		}
+		return fn([]byte(strings.TrimSpace(data)))
+	})
+}
+
+// collect accumulates streamed text and forwards each piece to onChunk
+type collect struct {
+	text    strings.Builder
+	onChunk StreamFunc
+}
+
+func (c *collect) add(chunk string) {
+	if chunk == "" {
+		return
+	}
+	c.text.WriteString(chunk)
+	c.onChunk(chunk)
+}
+
+func (c *collect) result() (string, error) {
+	text := strings.TrimSpace(c.text.String())
+	if text == "" {
+		return "", fmt.Errorf("no content in response")
+	}
+	return text, nil
+}
+
+// streamClaudeRequest streams a Messages API response; a nil onChunk makes a
+// plain request
+func (g *ClaudeGenerator) streamClaudeRequest(ctx context.Context, systemPrompt, userPrompt string, onChunk StreamFunc) (string, error) {
+	if onChunk == nil {
+		return g.makeClaudeRequest(ctx, systemPrompt, userPrompt)
+	}
+
+	endpoint := g.endpoint
+	if endpoint == "" {
+		endpoint = "https://api.anthropic.com"
+	}
+
+	headers := map[string]string{"anthropic-version": "2023-06-01"}
+	if g.token != "" {
+		headers["x-api-key"] = g.token
+	}
+
+	started := time.Now()
+	s, err := openStream(ctx, endpoint+"/v1/messages", headers, map[string]interface{}{
+		"model":       g.model,
+		"max_tokens":  g.maxTokens,
+		"temperature": g.temperature,
+		"stream":      true,
+		"messages": []map[string]string{
+			{"role": "user", "content": systemPrompt + "\n\n" + userPrompt},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	defer s.Close()
+
+	out := &collect{onChunk: onChunk}
+	var model string
+	var usage claudeUsage
+	err = s.sse(func(data []byte) error {
+		var event struct {
+			Type    string `json:"type"`
+			Message struct {
+				Model string      `json:"model"`
+				Usage claudeUsage `json:"usage"`
+			} `json:"message"`
+			Delta struct {
+				Type string `json:"type"`
+				Text string `json:"text"`
+			} `json:"delta"`
+			Usage claudeUsage `json:"usage"`
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if err := json.Unmarshal(data, &event); err != nil {
+			return fmt.Errorf("decode event: %w", err)
+		}
+
+		switch event.Type {
+		case "message_start":
+			model, usage = event.Message.Model, event.Message.Usage
+		case "message_delta":
+			// Output tokens are cumulative over the message
+			usage.OutputTokens = event.Usage.OutputTokens
+		case "content_block_delta":
+			if event.Delta.Type == "text_delta" {
+				out.add(event.Delta.Text)
+			}
+		case "message_stop":
+			return errStreamDone
+		case "error":
+			return fmt.Errorf("stream error: %s", event.Error.Message)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	recordUsage(ctx, "claude", model, g.model, usage.InputTokens, usage.OutputTokens, started)
+	return out.result()
+}
+
+// streamRequest streams a chat completion; a nil onChunk makes a plain request
+func (g *OpenAIGenerator) streamRequest(ctx context.Context, systemPrompt, userPrompt string, onChunk StreamFunc) (string, error) {
+	if onChunk == nil {
+		return g.makeRequest(ctx, systemPrompt, userPrompt)
+	}
+
+	headers := map[string]string{}
+	if g.token != "" {
+		headers["Authorization"] = "Bearer " + g.token
+	}
+
+	started := time.Now()
+	s, err := openStream(ctx, g.endpoint+"/v1/chat/completions", headers, map[string]interface{}{
+		"model": g.model,
+		"messages": []map[string]string{
+			{"role": "system", "content": systemPrompt},
+			{"role": "user", "content": userPrompt},
+		},
+		"max_tokens":     g.maxTokens,
+		"temperature":    g.temperature,
+		"stream":         true,
+		"stream_options": map[string]bool{"include_usage": true},
+	})
+	if err != nil {
+		return "", err
+	}
+	defer s.Close()
+
+	out := &collect{onChunk: onChunk}
+	var model string
+	var usage openAIUsage
+	err = s.sse(func(data []byte) error {
+		if string(data) == "[DONE]" {
+			return errStreamDone
+		}
+
+		var event struct {
+			Model   string       `json:"model"`
+			Usage   *openAIUsage `json:"usage"`
+			Choices []struct {
+				Delta struct {
+					Content string `json:"content"`
+				} `json:"delta"`
+			} `json:"choices"`
+			Error *struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if err := json.Unmarshal(data, &event); err != nil {
+			return fmt.Errorf("decode event: %w", err)
+		}
+		if event.Error != nil {
+			return fmt.Errorf("stream error: %s", event.Error.Message)
+		}
+		if event.Model != "" {
+			model = event.Model
+		}
+		// Sent in a final chunk without choices when include_usage is set
+		if event.Usage != nil {
+			usage = *event.Usage
+		}
+		for _, choice := range event.Choices {
+			out.add(choice.Delta.Content)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	recordUsage(ctx, "openai", model, g.model, usage.PromptTokens, usage.CompletionTokens, started)
+	return out.result()
+}
+
+// streamOllamaRequest streams /api/generate as NDJSON; a nil onChunk makes a
+// plain request
+func (g *OllamaGenerator) streamOllamaRequest(ctx context.Context, systemPrompt, userPrompt string, onChunk StreamFunc) (string, error) {
+	if onChunk == nil {
+		return g.makeOllamaRequest(ctx, systemPrompt, userPrompt)
+	}
+
+	endpoint := g.endpoint
+	if endpoint == "" {
+		endpoint = "http://localhost:11434"
+	}
+
+	started := time.Now()
+	s, err := openStream(ctx, endpoint+"/api/generate", nil, map[string]interface{}{
+		"model":  g.model,
+		"prompt": systemPrompt + "\n\n" + userPrompt,
+		"stream": true,
+	})
+	if err != nil {
+		return "", err
+	}
+	defer s.Close()
+
+	out := &collect{onChunk: onChunk}
+	var usage ollamaUsage
+	err = s.lines(func(line string) error {
+		var event struct {
+			Response string `json:"response"`
+			Done     bool   `json:"done"`
+			Error    string `json:"error"`
+			ollamaUsage
+		}
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			return fmt.Errorf("decode event: %w", err)
+		}
+		if event.Error != "" {
+			return fmt.Errorf("stream error: %s", event.Error)
+		}
+		out.add(event.Response)
+		if event.Done {
+			usage = event.ollamaUsage
+			return errStreamDone
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	recordUsage(ctx, "ollama", "", g.model, usage.PromptEvalCount, usage.EvalCount, started)
+	return out.result()
+}
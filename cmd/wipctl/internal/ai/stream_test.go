@@ -0,0 +1,212 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// streamServer serves body at path, flushing after every line
+func streamServer(t *testing.T, path, body string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != path {
+			t.Errorf("request path = %q, want %q", r.URL.Path, path)
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, line := range strings.SplitAfter(body, "\n") {
+			fmt.Fprint(w, line)
+			w.(http.Flusher).Flush()
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// sse formats events as server-sent "data:" lines
+func sse(events ...string) string {
+	var sb strings.Builder
+	for _, event := range events {
+		sb.WriteString("data: " + event + "\n\n")
+	}
+	return sb.String()
+}
+
+// streamFrom runs one streaming request of the named provider against url
+func streamFrom(provider, url string, onChunk StreamFunc) (string, error) {
+	ctx := context.Background()
+	switch provider {
+	case "claude":
+		g := &ClaudeGenerator{endpoint: url, model: "claude-test", maxTokens: 100}
+		return g.streamClaudeRequest(ctx, "system", "user", onChunk)
+	case "openai":
+		g := &OpenAIGenerator{endpoint: url, model: "gpt-test", maxTokens: 100}
+		return g.streamRequest(ctx, "system", "user", onChunk)
+	default:
+		g := &OllamaGenerator{endpoint: url, model: "llama-test"}
+		return g.streamOllamaRequest(ctx, "system", "user", onChunk)
+	}
+}
+
+var streamPaths = map[string]string{
+	"claude": "/v1/messages",
+	"openai": "/v1/chat/completions",
+	"ollama": "/api/generate",
+}
+
+func TestStreamProviders(t *testing.T) {
+	long := strings.Repeat("x", 100*1024)
+
+	tests := []struct {
+		name     string
+		provider string
+		body     string
+		chunks   []string
+		want     string
+		err      string
+	}{
+		{
+			name:     "claude deltas",
+			provider: "claude",
+			body: "event: message_start\n" +
+				sse(`{"type":"message_start","message":{"model":"claude-test","usage":{"input_tokens":5}}}`) +
+				"event: content_block_delta\n" +
+				sse(`{"type":"content_block_delta","delta":{"type":"text_delta","text":"Hello"}}`,
+					`{"type":"content_block_delta","delta":{"type":"input_json_delta","partial_json":"{}"}}`,
+					`{"type":"content_block_delta","delta":{"type":"text_delta","text":" world"}}`,
+					`{"type":"message_delta","usage":{"output_tokens":2}}`,
+					`{"type":"message_stop"}`,
+					`{"type":"content_block_delta","delta":{"type":"text_delta","text":" after stop"}}`),
+			chunks: []string{"Hello", " world"},
+			want:   "Hello world",
+		},
+		{
+			name:     "claude error event",
+			provider: "claude",
+			body: sse(`{"type":"content_block_delta","delta":{"type":"text_delta","text":"Hel"}}`) +
+				"event: error\n" +
+				sse(`{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`),
+			chunks: []string{"Hel"},
+			err:    "stream error: Overloaded",
+		},
+		{
+			name:     "claude line over 64 KiB",
+			provider: "claude",
+			body: sse(`{"type":"content_block_delta","delta":{"type":"text_delta","text":"`+long+`"}}`,
+				`{"type":"message_stop"}`),
+			chunks: []string{long},
+			want:   long,
+		},
+		{
+			name:     "openai deltas until [DONE]",
+			provider: "openai",
+			body: sse(`{"model":"gpt-test","choices":[{"delta":{"role":"assistant"}}]}`,
+				`{"choices":[{"delta":{"content":"Hello"}}]}`,
+				`{"choices":[{"delta":{"content":" world"}}]}`,
+				`{"choices":[],"usage":{"prompt_tokens":5,"completion_tokens":2}}`,
+				`[DONE]`,
+				`{"choices":[{"delta":{"content":" after done"}}]}`),
+			chunks: []string{"Hello", " world"},
+			want:   "Hello world",
+		},
+		{
+			name:     "openai error event",
+			provider: "openai",
+			body:     sse(`{"error":{"message":"context length exceeded"}}`),
+			err:      "stream error: context length exceeded",
+		},
+		{
+			name:     "openai empty stream",
+			provider: "openai",
+			body:     sse(`[DONE]`),
+			err:      "no content in response",
+		},
+		{
+			name:     "ollama until done",
+			provider: "ollama",
+			body: `{"response":"Hello","done":false}` + "\n" +
+				`{"response":" world","done":false}` + "\n" +
+				`{"response":"","done":true,"prompt_eval_count":5,"eval_count":2}` + "\n" +
+				`{"response":" after done","done":false}` + "\n",
+			chunks: []string{"Hello", " world"},
+			want:   "Hello world",
+		},
+		{
+			name:     "ollama error line",
+			provider: "ollama",
+			body:     `{"error":"model 'llama-test' not found"}` + "\n",
+			err:      "stream error: model 'llama-test' not found",
+		},
+		{
+			name:     "ollama line over 64 KiB",
+			provider: "ollama",
+			body:     `{"response":"` + long + `","done":true}` + "\n",
+			chunks:   []string{long},
+			want:     long,
+		},
+		{
+			name:     "ollama line over the limit",
+			provider: "ollama",
+			body:     `{"response":"` + strings.Repeat("x", maxStreamLine) + `","done":true}` + "\n",
+			err:      "read stream: bufio.Scanner: token too long",
+		},
+		{
+			name:     "ollama malformed line",
+			provider: "ollama",
+			body:     "not json\n",
+			err:      "decode event",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := streamServer(t, streamPaths[tt.provider], tt.body)
+
+			var chunks []string
+			got, err := streamFrom(tt.provider, server.URL, func(chunk string) {
+				chunks = append(chunks, chunk)
+			})
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("error = %v, want %q", err, tt.err)
+				}
+			} else if err != nil {
+				t.Fatalf("stream: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("text = %.40q (%d bytes), want %.40q (%d bytes)", got, len(got), tt.want, len(tt.want))
+			}
+			if strings.Join(chunks, "|") != strings.Join(tt.chunks, "|") {
+				t.Errorf("chunks = %.80q, want %.80q", chunks, tt.chunks)
+			}
+		})
+	}
+}
+
+func TestStreamHTTPError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "7")
+		w.WriteHeader(http.StatusTooManyRequests)
+		fmt.Fprint(w, `{"error":{"type":"rate_limit_error","message":"slow down"}}`)
+	}))
+	t.Cleanup(server.Close)
+
+	called := false
+	_, err := streamFrom("claude", server.URL, func(string) { called = true })
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) {
+		t.Fatalf("error = %v, want *HTTPError", err)
+	}
+	if httpErr.Status != http.StatusTooManyRequests || httpErr.Message != "rate_limit_error: slow down" || httpErr.RetryAfter.Seconds() != 7 {
+		t.Errorf("error = %+v, want 429 rate_limit_error: slow down after 7s", httpErr)
+	}
+	if called {
+		t.Error("onChunk called for an error response")
+	}
+}
@@ -0,0 +1,57 @@
+package ai
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/aiusage"
+)
+
+// sharedPrices price the calls of every generator
+var sharedPrices = aiusage.DefaultPrices
+
+// sharedUsageLog and usageCommand record every call; nil records nothing
+var (
+	sharedUsageLog *aiusage.Log
+	usageCommand   string
+)
+
+// UseUsageLog appends every priced call to log, attributed to command
+func UseUsageLog(log *aiusage.Log, command string) {
+	sharedUsageLog = log
+	usageCommand = command
+}
+
+// UsePrices layers configured prices over the built-in price table
+func UsePrices(overrides map[string]aiusage.Price) {
+	sharedPrices = aiusage.DefaultPrices.With(overrides)
+}
+
+// recordUsage logs a completed provider call, appends it to the usage log
+// and adds it to the attribution in ctx. model is the one the provider reports, falling back to the
+// configured one.
+func recordUsage(ctx context.Context, provider, model, configured string, inputTokens, outputTokens int, started time.Time) {
+	if model == "" {
+		model = configured
+	}
+	usage := aiusage.Call(provider, model, inputTokens, outputTokens, time.Since(started), sharedPrices)
+	slog.Info("AI usage",
+		"provider", provider,
+		"model", model,
+		"input_tokens", inputTokens,
+		"output_tokens", outputTokens,
+		"latency", usage.Latency.String(),
+		"cost", usage.CostText())
+
+	if sharedUsageLog != nil {
+		record := aiusage.Record{Time: time.Now(), Command: usageCommand, Usage: usage}
+		if err := sharedUsageLog.Append(record); err != nil {
+			slog.Warn("Failed to record AI usage", "path", sharedUsageLog.Path(), "error", err)
+		}
+	}
+
+	if attribution := attributionFrom(ctx); attribution != nil {
+		attribution.Usage = append(attribution.Usage, usage)
+	}
+}
@@ -0,0 +1,345 @@
+// Package aicache is a content-addressed, on-disk cache of AI provider
+// responses, so re-running a command on an unchanged workspace does not pay
+// for the same completions twice.
+package aicache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DirName is the cache directory kept in the report directory
+const DirName = "ai-cache"
+
+// Defaults for the cache limits
+const (
+	DefaultTTL      = 7 * 24 * time.Hour
+	DefaultMaxBytes = 50 << 20
+)
+
+// pruneEvery is how many puts may pass between full prunes while the cache
+// stays within its size limit, so expired entries nobody reads still go
+const pruneEvery = 100
+
+// Entry is one cached response
+type Entry struct {
+	Kind     string    `json:"kind"`
+	Provider string    `json:"provider"`
+	Model    string    `json:"model,omitempty"`
+	Created  time.Time `json:"created"`
+	LastUsed time.Time `json:"last_used"`
+	Hits     int       `json:"hits"`
+	Response string    `json:"response"`
+}
+
+// Stats summarizes the cache contents
+type Stats struct {
+	Entries int
+	Bytes   int64
+	Expired int
+	Hits    int
+	Oldest  time.Time
+	Newest  time.Time
+	ByKind  map[string]int
+}
+
+// Cache stores one JSON file per response under <dir>/<2 hex>/<sha256>.json.
+// It is safe for concurrent use within one process; entries are written to a
+// unique temporary file and renamed into place, so processes sharing the
+// directory never see each other's partial writes.
+type Cache struct {
+	dir      string
+	ttl      time.Duration
+	maxBytes int64
+	mu       sync.Mutex
+
+	// size estimates the bytes on disk between prunes, -1 until the first
+	// one; puts counts the puts since the last prune
+	size int64
+	puts int
+}
+
+// Open returns the cache stored in reportDir; entries older than ttl are
+// ignored and the least recently used are evicted beyond maxBytes
+func Open(reportDir string, ttl time.Duration, maxBytes int64) *Cache {
+	return &Cache{dir: filepath.Join(reportDir, DirName), ttl: ttl, maxBytes: maxBytes, size: -1}
+}
+
+// Dir returns the cache directory
+func (c *Cache) Dir() string {
+	return c.dir
+}
+
+// TTL returns how long entries stay valid
+func (c *Cache) TTL() time.Duration {
+	return c.ttl
+}
+
+// MaxBytes returns the size limit
+func (c *Cache) MaxBytes() int64 {
+	return c.maxBytes
+}
+
+// Key hashes its parts (provider, model, prompt version, input...) into a
+// cache key. Parts are JSON encoded, so struct field order and map key order
+// are stable.
+func Key(parts ...interface{}) (string, error) {
+	hash := sha256.New()
+	encoder := json.NewEncoder(hash)
+	for _, part := range parts {
+		if err := encoder.Encode(part); err != nil {
+			return "", fmt.Errorf("hash cache key: %w", err)
+		}
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// Get returns the cached entry for key, if there is one that has not expired
+func (c *Cache) Get(key string) (Entry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	path := c.path(key)
+	entry, err := readEntry(path)
+	if err != nil {
+		return Entry{}, false
+	}
+	if c.expired(entry) {
+		os.Remove(path)
+		return Entry{}, false
+	}
+
+	// Recency drives eviction; a failed write only costs accuracy
+	entry.Hits++
+	entry.LastUsed = time.Now()
+	_, _ = writeEntry(path, entry)
+	return entry, true
+}
+
+// Put stores an entry under key. The TTL and size limits are enforced by a
+// full prune on the first put, whenever the estimated size passes the limit
+// and otherwise every pruneEvery puts, rather than walking the cache each time.
+func (c *Cache) Put(key string, entry Entry) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	entry.Created = now
+	entry.LastUsed = now
+	written, err := writeEntry(c.path(key), entry)
+	if err != nil {
+		return err
+	}
+
+	c.puts++
+	if c.size >= 0 {
+		// Replacing an entry counts twice; an overestimate only prunes early
+		c.size += written
+		if c.puts < pruneEvery && (c.maxBytes <= 0 || c.size <= c.maxBytes) {
+			return nil
+		}
+	}
+	_, err = c.prune()
+	return err
+}
+
+// Stats walks the cache and summarizes it
+func (c *Cache) Stats() (Stats, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := Stats{ByKind: make(map[string]int)}
+	err := c.walk(func(path string, info fs.FileInfo) {
+		entry, err := readEntry(path)
+		if err != nil {
+			return
+		}
+		stats.Entries++
+		stats.Bytes += info.Size()
+		stats.Hits += entry.Hits
+		stats.ByKind[entry.Kind]++
+		if c.expired(entry) {
+			stats.Expired++
+		}
+		if stats.Oldest.IsZero() || entry.Created.Before(stats.Oldest) {
+			stats.Oldest = entry.Created
+		}
+		if entry.Created.After(stats.Newest) {
+			stats.Newest = entry.Created
+		}
+	})
+	return stats, err
+}
+
+// Clear removes every cached entry and returns how many there were
+func (c *Cache) Clear() (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	removed := 0
+	err := c.walk(func(path string, info fs.FileInfo) {
+		if os.Remove(path) == nil {
+			removed++
+		}
+	})
+	if err != nil {
+		return removed, err
+	}
+	if err := os.RemoveAll(c.dir); err != nil {
+		return removed, err
+	}
+	c.size, c.puts = 0, 0
+	return removed, nil
+}
+
+// Prune removes expired entries, then the least recently used ones until the
+// cache fits in its size limit. It returns how many entries were removed.
+func (c *Cache) Prune() (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.prune()
+}
+
+func (c *Cache) prune() (int, error) {
+	type file struct {
+		path     string
+		size     int64
+		lastUsed time.Time
+	}
+
+	var files []file
+	var total int64
+	removed := 0
+	err := c.walk(func(path string, info fs.FileInfo) {
+		entry, err := readEntry(path)
+		if err != nil || c.expired(entry) {
+			if os.Remove(path) == nil {
+				removed++
+			}
+			return
+		}
+		files = append(files, file{path: path, size: info.Size(), lastUsed: entry.LastUsed})
+		total += info.Size()
+	})
+	if err != nil {
+		return removed, err
+	}
+
+	c.puts = 0
+	c.size = total
+	if c.maxBytes <= 0 || total <= c.maxBytes {
+		return removed, nil
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].lastUsed.Before(files[j].lastUsed)
+	})
+	for _, f := range files {
+		if total <= c.maxBytes {
+			break
+		}
+		if os.Remove(f.path) == nil {
+			total -= f.size
+			removed++
+		}
+	}
+	c.size = total
+	return removed, nil
+}
+
+func (c *Cache) expired(entry Entry) bool {
+	return c.ttl > 0 && time.Since(entry.Created) > c.ttl
+}
+
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.dir, key[:2], key+".json")
+}
+
+// walk calls fn for every entry file; a missing cache directory is empty
+func (c *Cache) walk(fn func(path string, info fs.FileInfo)) error {
+	err := filepath.WalkDir(c.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || filepath.Ext(path) != ".json" {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		fn(path, info)
+		return nil
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func readEntry(path string) (Entry, error) {
+	var entry Entry
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return entry, err
+	}
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return entry, fmt.Errorf("parse cache entry %s: %w", path, err)
+	}
+	return entry, nil
+}
+
+// writeEntry atomically replaces the entry at path and returns its size
+func writeEntry(path string, entry Entry) (int64, error) {
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return 0, fmt.Errorf("marshal cache entry: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return 0, err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return 0, err
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), 0644)
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return 0, err
+	}
+	return int64(len(data)), nil
+}
+
+// ParseDuration is time.ParseDuration with a "d" suffix for days, e.g. "7d"
+func ParseDuration(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.ParseFloat(days, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", value)
+		}
+		return time.Duration(n * float64(24*time.Hour)), nil
+	}
+	return time.ParseDuration(value)
+}
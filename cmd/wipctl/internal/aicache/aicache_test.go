@@ -0,0 +1,195 @@
+package aicache
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func mustKey(t *testing.T, parts ...interface{}) string {
+	t.Helper()
+	key, err := Key(parts...)
+	if err != nil {
+		t.Fatalf("Key: %v", err)
+	}
+	return key
+}
+
+func TestKey(t *testing.T) {
+	a := mustKey(t, "openai", "gpt-4o", map[string]int{"x": 1, "y": 2})
+	b := mustKey(t, "openai", "gpt-4o", map[string]int{"y": 2, "x": 1})
+	if a != b {
+		t.Error("map key order changed the cache key")
+	}
+	if c := mustKey(t, "openai", "gpt-4o-mini", map[string]int{"x": 1, "y": 2}); c == a {
+		t.Error("different model produced the same cache key")
+	}
+	if len(a) != 64 {
+		t.Errorf("key length = %d, want a sha256 hex digest", len(a))
+	}
+}
+
+func TestGetPut(t *testing.T) {
+	cache := Open(t.TempDir(), time.Hour, 0)
+	key := mustKey(t, "commit", "input")
+
+	if _, ok := cache.Get(key); ok {
+		t.Fatal("Get on an empty cache hit")
+	}
+	if err := cache.Put(key, Entry{Kind: "commit", Provider: "openai", Response: "feat: x"}); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	for i := 1; i <= 2; i++ {
+		entry, ok := cache.Get(key)
+		if !ok {
+			t.Fatalf("Get %d missed", i)
+		}
+		if entry.Response != "feat: x" || entry.Hits != i {
+			t.Errorf("Get %d = %q with %d hits, want %q with %d", i, entry.Response, entry.Hits, "feat: x", i)
+		}
+	}
+}
+
+func TestTTL(t *testing.T) {
+	tests := []struct {
+		name    string
+		ttl     time.Duration
+		age     time.Duration
+		wantHit bool
+	}{
+		{name: "fresh", ttl: time.Hour, age: time.Minute, wantHit: true},
+		{name: "expired", ttl: time.Hour, age: 2 * time.Hour},
+		{name: "no ttl never expires", ttl: 0, age: 24 * 365 * time.Hour, wantHit: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := Open(t.TempDir(), tt.ttl, 0)
+			key := mustKey(t, tt.name)
+			created := time.Now().Add(-tt.age)
+			if _, err := writeEntry(cache.path(key), Entry{Kind: "commit", Created: created, LastUsed: created, Response: "r"}); err != nil {
+				t.Fatalf("writeEntry: %v", err)
+			}
+
+			if _, ok := cache.Get(key); ok != tt.wantHit {
+				t.Errorf("Get hit = %v, want %v", ok, tt.wantHit)
+			}
+			stats, err := cache.Stats()
+			if err != nil {
+				t.Fatalf("Stats: %v", err)
+			}
+			if want := map[bool]int{true: 1, false: 0}[tt.wantHit]; stats.Entries != want {
+				t.Errorf("entries after Get = %d, want %d (expired entries are removed)", stats.Entries, want)
+			}
+		})
+	}
+}
+
+func TestPruneEvictsLeastRecentlyUsed(t *testing.T) {
+	dir := t.TempDir()
+	unlimited := Open(dir, time.Hour, 0)
+
+	now := time.Now()
+	response := strings.Repeat("x", 1000)
+	ages := map[string]time.Duration{"oldest": 3 * time.Minute, "middle": 2 * time.Minute, "newest": time.Minute}
+	for name, age := range ages {
+		entry := Entry{Kind: name, Created: now, LastUsed: now.Add(-age), Response: response}
+		if _, err := writeEntry(unlimited.path(mustKey(t, name)), entry); err != nil {
+			t.Fatalf("writeEntry: %v", err)
+		}
+	}
+
+	stats, err := unlimited.Stats()
+	if err != nil {
+		t.Fatalf("Stats: %v", err)
+	}
+	perEntry := stats.Bytes / int64(stats.Entries)
+
+	// Room for two entries: the least recently used one has to go
+	limited := Open(dir, time.Hour, 2*perEntry+perEntry/2)
+	removed, err := limited.Prune()
+	if err != nil {
+		t.Fatalf("Prune: %v", err)
+	}
+	if removed != 1 {
+		t.Errorf("Prune removed %d entries, want 1", removed)
+	}
+
+	stats, err = limited.Stats()
+	if err != nil {
+		t.Fatalf("Stats: %v", err)
+	}
+	if stats.ByKind["oldest"] != 0 || stats.ByKind["middle"] != 1 || stats.ByKind["newest"] != 1 {
+		t.Errorf("entries by kind after prune = %v, want middle and newest kept", stats.ByKind)
+	}
+}
+
+func TestPutKeepsSizeLimit(t *testing.T) {
+	dir := t.TempDir()
+	response := strings.Repeat("x", 1000)
+	limit := int64(5000)
+	cache := Open(dir, time.Hour, limit)
+
+	for i := 0; i < 20; i++ {
+		if err := cache.Put(mustKey(t, i), Entry{Kind: "commit", Response: response}); err != nil {
+			t.Fatalf("Put %d: %v", i, err)
+		}
+	}
+
+	stats, err := cache.Stats()
+	if err != nil {
+		t.Fatalf("Stats: %v", err)
+	}
+	if stats.Bytes > limit || stats.Entries == 0 {
+		t.Errorf("cache holds %d entries in %d bytes, want some within %d", stats.Entries, stats.Bytes, limit)
+	}
+
+	temps, _ := filepath.Glob(filepath.Join(dir, DirName, "*", "*.tmp"))
+	if len(temps) != 0 {
+		t.Errorf("temporary files left behind: %v", temps)
+	}
+}
+
+func TestClear(t *testing.T) {
+	cache := Open(t.TempDir(), time.Hour, 0)
+	for _, name := range []string{"a", "b"} {
+		if err := cache.Put(mustKey(t, name), Entry{Kind: name}); err != nil {
+			t.Fatalf("Put: %v", err)
+		}
+	}
+	removed, err := cache.Clear()
+	if err != nil || removed != 2 {
+		t.Errorf("Clear = %d, %v, want 2 entries removed", removed, err)
+	}
+	if stats, _ := cache.Stats(); stats.Entries != 0 {
+		t.Errorf("entries after Clear = %d, want 0", stats.Entries)
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{input: "7d", want: 7 * 24 * time.Hour},
+		{input: "1.5d", want: 36 * time.Hour},
+		{input: "12h", want: 12 * time.Hour},
+		{input: " 30m ", want: 30 * time.Minute},
+		{input: "xd", wantErr: true},
+		{input: "soon", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseDuration(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseDuration(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseDuration(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+// Package aiusage accounts for AI provider calls: tokens, latency and an
+// estimated cost from a price table. Every call is appended to a usage log,
+// so spending can be totalled over any period.
+package aiusage
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Price is what a model costs in USD per million tokens
+type Price struct {
+	Input  float64 `json:"input"`
+	Output float64 `json:"output"`
+}
+
+// Prices maps "provider/model", "model" or "provider" to a price, tried in
+// that order. A model key also prices the dated snapshots it prefixes, so
+// "gpt-4o-mini" covers "gpt-4o-mini-2024-07-18".
+type Prices map[string]Price
+
+// DefaultPrices are list prices of common models; local providers are free
+var DefaultPrices = Prices{
+	"claude-3-haiku-20240307":    {Input: 0.25, Output: 1.25},
+	"claude-3-5-haiku-20241022":  {Input: 0.80, Output: 4},
+	"claude-3-5-sonnet-20241022": {Input: 3, Output: 15},
+	"claude-3-7-sonnet-20250219": {Input: 3, Output: 15},
+	"claude-sonnet-4-20250514":   {Input: 3, Output: 15},
+	"claude-opus-4-20250514":     {Input: 15, Output: 75},
+	"gpt-3.5-turbo":              {Input: 0.50, Output: 1.50},
+	"gpt-4o":                     {Input: 2.50, Output: 10},
+	"gpt-4o-mini":                {Input: 0.15, Output: 0.60},
+	"gpt-4.1":                    {Input: 2, Output: 8},
+	"gpt-4.1-mini":               {Input: 0.40, Output: 1.60},
+	"ollama":                     {},
+	"exec":                       {},
+}
+
+// With returns the prices with overrides layered on top
+func (p Prices) With(overrides map[string]Price) Prices {
+	merged := make(Prices, len(p)+len(overrides))
+	for key, price := range p {
+		merged[key] = price
+	}
+	for key, price := range overrides {
+		merged[key] = price
+	}
+	return merged
+}
+
+// Lookup finds the price of a model
+func (p Prices) Lookup(provider, model string) (Price, bool) {
+	if model != "" {
+		for _, key := range []string{provider + "/" + model, model} {
+			if price, ok := p[key]; ok {
+				return price, true
+			}
+		}
+
+		// The longest key the model starts with, e.g. a snapshot of a listed model
+		best := ""
+		for key := range p {
+			if strings.HasPrefix(model, key+"-") && len(key) > len(best) {
+				best = key
+			}
+		}
+		if best != "" {
+			return p[best], true
+		}
+	}
+
+	price, ok := p[provider]
+	return price, ok
+}
+
+// Usage is what one or more calls to a provider and model used
+type Usage struct {
+	Provider     string        `json:"provider"`
+	Model        string        `json:"model,omitempty"`
+	Calls        int           `json:"calls"`
+	InputTokens  int           `json:"input_tokens"`
+	OutputTokens int           `json:"output_tokens"`
+	Latency      time.Duration `json:"latency_ns"`         // total wall time of the calls
+	Cost         float64       `json:"cost"`               // estimated USD
+	Unpriced     int           `json:"unpriced,omitempty"` // calls with no price in the table, not in Cost
+}
+
+// Call builds the usage of a single call, priced from prices
+func Call(provider, model string, inputTokens, outputTokens int, latency time.Duration, prices Prices) Usage {
+	usage := Usage{
+		Provider:     provider,
+		Model:        model,
+		Calls:        1,
+		InputTokens:  inputTokens,
+		OutputTokens: outputTokens,
+		Latency:      latency,
+	}
+	if price, ok := prices.Lookup(provider, model); ok {
+		usage.Cost = (float64(inputTokens)*price.Input + float64(outputTokens)*price.Output) / 1e6
+	} else {
+		usage.Unpriced = 1
+	}
+	return usage
+}
+
+// Add sums two usages, keeping the provider and model they share
+func (u Usage) Add(other Usage) Usage {
+	if u.Calls == 0 {
+		return other
+	}
+	if u.Provider != other.Provider {
+		u.Provider = ""
+	}
+	if u.Model != other.Model {
+		u.Model = ""
+	}
+	u.Calls += other.Calls
+	u.InputTokens += other.InputTokens
+	u.OutputTokens += other.OutputTokens
+	u.Latency += other.Latency
+	u.Cost += other.Cost
+	u.Unpriced += other.Unpriced
+	return u
+}
+
+// Name is "provider/model", or just the provider when the model is unknown
+func (u Usage) Name() string {
+	if u.Model == "" {
+		return u.Provider
+	}
+	return u.Provider + "/" + u.Model
+}
+
+// Summary describes the numbers, e.g.
+// "2 calls, 1200 in / 80 out tokens, 1.42s, $0.000348"
+func (u Usage) Summary() string {
+	calls := "calls"
+	if u.Calls == 1 {
+		calls = "call"
+	}
+	return fmt.Sprintf("%d %s, %d in / %d out tokens, %s, %s",
+		u.Calls, calls, u.InputTokens, u.OutputTokens, FormatLatency(u.Latency), u.CostText())
+}
+
+// FormatLatency rounds to milliseconds below a second and to 10ms above
+func FormatLatency(latency time.Duration) string {
+	if latency < time.Second {
+		return latency.Round(time.Millisecond).String()
+	}
+	return latency.Round(10 * time.Millisecond).String()
+}
+
+// String is the report line format
+func (u Usage) String() string {
+	return u.Name() + " " + u.Summary()
+}
+
+// CostText renders the estimated cost, flagging calls that could not be priced
+func (u Usage) CostText() string {
+	switch {
+	case u.Unpriced == u.Calls:
+		return "cost unknown"
+	case u.Unpriced > 0:
+		return fmt.Sprintf("%s + %d unpriced", FormatCost(u.Cost), u.Unpriced)
+	}
+	return FormatCost(u.Cost)
+}
+
+// FormatCost renders USD with enough digits for single cheap calls
+func FormatCost(cost float64) string {
+	if cost != 0 && cost < 0.01 {
+		return fmt.Sprintf("$%.6f", cost)
+	}
+	return fmt.Sprintf("$%.4f", cost)
+}
+
+// Merge combines usages of the same provider and model, sorted by name
+func Merge(usages []Usage) []Usage {
+	byName := make(map[string]Usage)
+	for _, usage := range usages {
+		name := usage.Name()
+		byName[name] = byName[name].Add(usage)
+	}
+
+	merged := make([]Usage, 0, len(byName))
+	for _, usage := range byName {
+		merged = append(merged, usage)
+	}
+	sort.Slice(merged, func(i, j int) bool { return merged[i].Name() < merged[j].Name() })
+	return merged
+}
+
+// Total sums usages regardless of provider and model
+func Total(usages []Usage) Usage {
+	var total Usage
+	for _, usage := range usages {
+		total = total.Add(usage)
+	}
+	return total
+}
+
+// Label marks the per-entry usage lines of reports
+const Label = "AI usage:"
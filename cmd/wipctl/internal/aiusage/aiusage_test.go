@@ -0,0 +1,110 @@
+package aiusage
+
+import (
+	"math"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestLogRoundTrip(t *testing.T) {
+	log := OpenLog(t.TempDir())
+	now := time.Now()
+
+	records := []Record{
+		{Time: now.Add(-48 * time.Hour), Command: "push", Usage: Usage{Provider: "openai", Model: "gpt-4o", Calls: 1, Cost: 1}},
+		{Time: now.Add(-time.Hour), Command: "review", Usage: Usage{Provider: "openai", Model: "gpt-4o-mini", Calls: 1, InputTokens: 400, OutputTokens: 30, Latency: 812 * time.Millisecond, Cost: 0.0000781234}},
+		{Time: now, Command: "status", Usage: Usage{Provider: "claude", Model: "claude-next", Calls: 1, InputTokens: 10, OutputTokens: 5, Latency: 40 * time.Millisecond, Unpriced: 1}},
+	}
+	for _, record := range records {
+		if err := log.Append(record); err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+	}
+
+	got, err := log.Since(now.Add(-24 * time.Hour))
+	if err != nil {
+		t.Fatalf("Since: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("Since returned %d records, want the 2 from the last day", len(got))
+	}
+	for i, want := range records[1:] {
+		if !got[i].Time.Equal(want.Time) || got[i].Command != want.Command || got[i].Usage != want.Usage {
+			t.Errorf("record %d = %+v, want %+v", i, got[i], want)
+		}
+	}
+}
+
+func TestLogSkipsBrokenLines(t *testing.T) {
+	log := OpenLog(t.TempDir())
+	if err := log.Append(Record{Time: time.Now(), Usage: Usage{Provider: "exec", Calls: 1}}); err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+	file, err := os.OpenFile(log.Path(), os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatalf("open log: %v", err)
+	}
+	file.WriteString(`{"time":"2024-01-01T00:00:00Z","provider":"ex` + "\n")
+	file.Close()
+
+	got, err := log.Since(time.Time{})
+	if err != nil || len(got) != 1 {
+		t.Errorf("Since = %d records, %v, want the 1 complete record", len(got), err)
+	}
+}
+
+func TestMissingLog(t *testing.T) {
+	got, err := OpenLog(t.TempDir()).Since(time.Time{})
+	if err != nil || len(got) != 0 {
+		t.Errorf("Since on a missing log = %v, %v, want no records", got, err)
+	}
+}
+
+func TestCall(t *testing.T) {
+	prices := DefaultPrices.With(map[string]Price{"acme/rocket": {Input: 1, Output: 2}})
+
+	tests := []struct {
+		name     string
+		provider string
+		model    string
+		cost     float64
+		unpriced int
+	}{
+		{name: "listed model", provider: "openai", model: "gpt-4o", cost: (1e6*2.5 + 1e6*10) / 1e6},
+		{name: "dated snapshot", provider: "openai", model: "gpt-4o-mini-2024-07-18", cost: (1e6*0.15 + 1e6*0.60) / 1e6},
+		{name: "provider/model override", provider: "acme", model: "rocket", cost: 3},
+		{name: "free provider", provider: "ollama", model: "llama3"},
+		{name: "unknown", provider: "acme", model: "glider", unpriced: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			usage := Call(tt.provider, tt.model, 1e6, 1e6, time.Second, prices)
+			if math.Abs(usage.Cost-tt.cost) > 1e-9 || usage.Unpriced != tt.unpriced {
+				t.Errorf("Call = cost %v unpriced %d, want cost %v unpriced %d", usage.Cost, usage.Unpriced, tt.cost, tt.unpriced)
+			}
+		})
+	}
+}
+
+func TestMergeAndTotal(t *testing.T) {
+	usages := []Usage{
+		{Provider: "openai", Model: "gpt-4o", Calls: 1, InputTokens: 10, Cost: 0.1},
+		{Provider: "ollama", Model: "llama3", Calls: 2, InputTokens: 5},
+		{Provider: "openai", Model: "gpt-4o", Calls: 1, InputTokens: 20, Cost: 0.2},
+	}
+
+	merged := Merge(usages)
+	if len(merged) != 2 || merged[0].Name() != "ollama/llama3" || merged[1].Name() != "openai/gpt-4o" {
+		t.Fatalf("Merge = %+v, want ollama/llama3 then openai/gpt-4o", merged)
+	}
+	if merged[1].Calls != 2 || merged[1].InputTokens != 30 {
+		t.Errorf("merged openai = %+v, want 2 calls and 30 input tokens", merged[1])
+	}
+
+	total := Total(usages)
+	if total.Calls != 4 || total.InputTokens != 35 || total.Provider != "" {
+		t.Errorf("Total = %+v, want 4 calls, 35 input tokens and no provider", total)
+	}
+}
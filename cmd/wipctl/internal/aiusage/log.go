@@ -0,0 +1,91 @@
+package aiusage
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// LogFileName is the usage log kept in the report directory
+const LogFileName = "ai-usage.jsonl"
+
+// Record is one priced provider call in the usage log
+type Record struct {
+	Time    time.Time `json:"time"`
+	Command string    `json:"command,omitempty"` // wipctl subcommand that made the call
+	Usage
+}
+
+// Log is an append-only file of usage records, one JSON object per line.
+// Every call is recorded as it completes, whether or not the command saves a
+// report, and lines are short enough that appends from several processes do
+// not interleave.
+type Log struct {
+	path string
+}
+
+// OpenLog returns the usage log kept in dir; the file is created on the
+// first Append
+func OpenLog(dir string) *Log {
+	return &Log{path: filepath.Join(dir, LogFileName)}
+}
+
+// Path is the log file's location
+func (l *Log) Path() string {
+	return l.path
+}
+
+// Append adds a record to the log
+func (l *Log) Append(record Record) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("encode usage record: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(l.path), 0755); err != nil {
+		return fmt.Errorf("create usage log directory: %w", err)
+	}
+
+	file, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("open usage log: %w", err)
+	}
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		file.Close()
+		return fmt.Errorf("write usage log: %w", err)
+	}
+	return file.Close()
+}
+
+// Since reads the records made at or after cutoff. Lines that do not parse,
+// such as one cut short by a crash, are skipped.
+func (l *Log) Since(cutoff time.Time) ([]Record, error) {
+	file, err := os.Open(l.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("open usage log: %w", err)
+	}
+	defer file.Close()
+
+	var records []Record
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		var record Record
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			slog.Warn("Skipping unreadable usage record", "path", l.path, "line", line, "error", err)
+			continue
+		}
+		if !record.Time.Before(cutoff) {
+			records = append(records, record)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read usage log: %w", err)
+	}
+	return records, nil
+}
@@ -0,0 +1,89 @@
+package bundle
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// IndexFileName is the manifest written next to the bundles
+const IndexFileName = "wipctl-bundles.json"
+
+// RemoteName is the pseudo-remote imported bundle refs are stored under
+const RemoteName = "wip-bundle"
+
+// Branch is a WIP branch captured in a bundle
+type Branch struct {
+	Name   string `json:"name"`
+	Commit string `json:"commit"`
+}
+
+// Entry describes one repository's bundle
+type Entry struct {
+	Repo     string   `json:"repo"`
+	RelPath  string   `json:"rel_path"`
+	File     string   `json:"file"`
+	SHA256   string   `json:"sha256"`
+	Thin     bool     `json:"thin,omitempty"`
+	Branches []Branch `json:"branches"`
+}
+
+// Index is the manifest of an exported bundle directory
+type Index struct {
+	Created   time.Time `json:"created"`
+	Host      string    `json:"host"`
+	Workspace string    `json:"workspace"`
+	Repos     []Entry   `json:"repos"`
+}
+
+// FileName returns a flat bundle file name for a workspace-relative repo path
+func FileName(relPath string) string {
+	name := strings.NewReplacer("/", "__", `\`, "__").Replace(filepath.ToSlash(relPath))
+	if name == "" || name == "." {
+		name = "repo"
+	}
+	return name + ".bundle"
+}
+
+// WriteIndex saves the manifest into dir
+func WriteIndex(dir string, index Index) error {
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal bundle index: %w", err)
+	}
+	return os.WriteFile(filepath.Join(dir, IndexFileName), data, 0644)
+}
+
+// ReadIndex loads the manifest from dir
+func ReadIndex(dir string) (*Index, error) {
+	data, err := os.ReadFile(filepath.Join(dir, IndexFileName))
+	if err != nil {
+		return nil, err
+	}
+	var index Index
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("parse bundle index: %w", err)
+	}
+	return &index, nil
+}
+
+// FileSHA256 returns the hex SHA-256 of a file
+func FileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
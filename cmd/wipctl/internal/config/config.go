@@ -0,0 +1,520 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/aiusage"
+)
+
+const fileName = "config.json"
+
+// Config holds user and workspace level wipctl settings.
+// The user file is loaded first and the workspace file is layered on top,
+// so workspace values win for any key they set.
+type Config struct {
+	Hooks     map[string][]Hook `json:"hooks,omitempty"`
+	WipRemote *WipRemote        `json:"wip_remote,omitempty"`
+	Signing   *Signing          `json:"signing,omitempty"`
+	Naming    *Naming           `json:"naming,omitempty"`
+	Gates     *Gates            `json:"gates,omitempty"`
+	Redaction *Redaction        `json:"redaction,omitempty"`
+
+	// AIPrices override the built-in price table used to estimate AI costs,
+	// in USD per million tokens, keyed by "provider/model", model or provider
+	AIPrices map[string]aiusage.Price `json:"ai_prices,omitempty"`
+
+	// ProtectedBranches are never committed to or pushed by wipctl, only
+	// WIP refs are. Unset means DefaultProtectedBranches; [] protects nothing.
+	ProtectedBranches []string `json:"protected_branches,omitempty"`
+}
+
+// Prices returns the configured AI price overrides (nil-safe)
+func (c *Config) Prices() map[string]aiusage.Price {
+	if c == nil {
+		return nil
+	}
+	return c.AIPrices
+}
+
+// DefaultProtectedBranches applies when protected_branches is not configured
+var DefaultProtectedBranches = []string{"main", "master", "release/*"}
+
+// Protected returns the protected branch patterns
+func (c *Config) Protected() []string {
+	if c == nil || c.ProtectedBranches == nil {
+		return DefaultProtectedBranches
+	}
+	return c.ProtectedBranches
+}
+
+// IsProtected reports whether branch matches a protected pattern
+// (path.Match globs, so release/* matches release/1.2 but not release/1/x)
+func (c *Config) IsProtected(branch string) bool {
+	for _, pattern := range c.Protected() {
+		if pattern == branch {
+			return true
+		}
+		if matched, err := path.Match(pattern, branch); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+// Quality gate policies: what a failing check does to the commit
+const (
+	GateBlock    = "block"    // skip the repository
+	GateWarn     = "warn"     // commit, but warn in the output and report
+	GateAnnotate = "annotate" // commit, noting the failure in the message
+)
+
+// Gates configures quality checks run before checkpoint and push commit
+type Gates struct {
+	Enabled bool                 `json:"enabled,omitempty"`
+	Policy  string               `json:"policy,omitempty"`  // block|warn|annotate, default warn
+	Timeout string               `json:"timeout,omitempty"` // per command, Go duration, default 5m
+	Types   map[string][]string  `json:"types,omitempty"`   // commands per detected repo type ("go", "node")
+	Repos   map[string]RepoGates `json:"repos,omitempty"`   // per-repository overrides, keyed by repo name
+}
+
+// RepoGates overrides the quality gates of one repository
+type RepoGates struct {
+	Commands []string `json:"commands,omitempty"` // replaces the commands of the detected types
+	Policy   string   `json:"policy,omitempty"`
+	Skip     bool     `json:"skip,omitempty"`
+}
+
+// IsEnabled reports whether gates run without --gates
+func (g *Gates) IsEnabled() bool {
+	return g != nil && g.Enabled
+}
+
+// PolicyFor returns the failure policy for a repository
+func (g *Gates) PolicyFor(repo string) string {
+	if g == nil {
+		return GateWarn
+	}
+	policy := g.Policy
+	if override := g.Repos[repo].Policy; override != "" {
+		policy = override
+	}
+	switch policy {
+	case GateBlock, GateAnnotate:
+		return policy
+	}
+	return GateWarn
+}
+
+// TypeCommands returns the configured commands for a repo type, if overridden
+func (g *Gates) TypeCommands(repoType string) ([]string, bool) {
+	if g == nil {
+		return nil, false
+	}
+	commands, ok := g.Types[repoType]
+	return commands, ok
+}
+
+// RepoOverride returns the per-repository settings, if any
+func (g *Gates) RepoOverride(repo string) (RepoGates, bool) {
+	if g == nil {
+		return RepoGates{}, false
+	}
+	override, ok := g.Repos[repo]
+	return override, ok
+}
+
+// CommandTimeout returns the per-command timeout, or fallback when unset or invalid
+func (g *Gates) CommandTimeout(fallback time.Duration) time.Duration {
+	if g == nil || g.Timeout == "" {
+		return fallback
+	}
+	if parsed, err := time.ParseDuration(g.Timeout); err == nil && parsed > 0 {
+		return parsed
+	}
+	return fallback
+}
+
+// Redaction filters what wipctl sends to AI providers. Built-in masks for
+// common secrets always apply; these rules add to them.
+type Redaction struct {
+	Masks     []Mask   `json:"masks,omitempty"`      // regexes replaced in all prompt text
+	HashPaths bool     `json:"hash_paths,omitempty"` // send file paths as stable hashes
+	HashNames bool     `json:"hash_names,omitempty"` // send repo, branch and host names as stable hashes
+	Denylist  []string `json:"denylist,omitempty"`   // repo name globs never sent to remote providers
+	Salt      string   `json:"salt,omitempty"`       // mixed into hashes so common paths cannot be looked up
+}
+
+// Mask replaces every match of Pattern (Go regexp syntax)
+type Mask struct {
+	Pattern string `json:"pattern"`
+	Replace string `json:"replace,omitempty"` // default "[REDACTED]"; may use $1 etc.
+}
+
+// MaskRules returns the configured masks (nil-safe)
+func (r *Redaction) MaskRules() []Mask {
+	if r == nil {
+		return nil
+	}
+	return r.Masks
+}
+
+// PathHashing reports whether file paths are replaced by hashes
+func (r *Redaction) PathHashing() bool {
+	return r != nil && r.HashPaths
+}
+
+// NameHashing reports whether repo, branch and host names are replaced by hashes
+func (r *Redaction) NameHashing() bool {
+	return r != nil && r.HashNames
+}
+
+// Denied reports whether a repository must not reach remote providers
+// (path.Match globs against the repo name)
+func (r *Redaction) Denied(repo string) bool {
+	if r == nil {
+		return false
+	}
+	for _, pattern := range r.Denylist {
+		if pattern == repo {
+			return true
+		}
+		if matched, err := path.Match(pattern, repo); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+// Naming controls how WIP branches are named
+type Naming struct {
+	Template  string `json:"template,omitempty"`   // e.g. wip/{user}/{host}/{feature}/{ts}
+	Rolling   string `json:"rolling,omitempty"`    // --rolling ref, e.g. wip/{host}/{branch}
+	HostAlias string `json:"host_alias,omitempty"` // stable {host} instead of the machine hostname
+	User      string `json:"user,omitempty"`       // {user}, default the OS user name
+}
+
+// BranchTemplate returns the configured template ("" means the default)
+func (n *Naming) BranchTemplate() string {
+	if n == nil {
+		return ""
+	}
+	return n.Template
+}
+
+// RollingTemplate returns the configured rolling template ("" means the default)
+func (n *Naming) RollingTemplate() string {
+	if n == nil {
+		return ""
+	}
+	return n.Rolling
+}
+
+// Alias returns the configured host alias, if any
+func (n *Naming) Alias() string {
+	if n == nil {
+		return ""
+	}
+	return n.HostAlias
+}
+
+// UserName returns the configured {user} value, if any
+func (n *Naming) UserName() string {
+	if n == nil {
+		return ""
+	}
+	return n.User
+}
+
+// Signature verification modes for pulled WIP refs
+const (
+	VerifyOff     = "off"
+	VerifyWarn    = "warn"
+	VerifyRequire = "require"
+)
+
+// Signing configures signing of WIP commits and verification of pulled ones
+type Signing struct {
+	Format  string       `json:"format,omitempty"`  // "ssh" or "gpg"; empty disables signing
+	Key     string       `json:"key,omitempty"`     // SSH public key path or GPG key id
+	Verify  string       `json:"verify,omitempty"`  // off|warn|require, default warn when keys are trusted
+	Trusted []TrustedKey `json:"trusted,omitempty"` // keys whose WIP refs pull accepts
+}
+
+// TrustedKey binds a host name to an SSH public key or a GPG fingerprint
+type TrustedKey struct {
+	Host string `json:"host"`
+	Key  string `json:"key"`
+}
+
+// gpgFingerprintPattern is a full v4 GPG fingerprint once spaces are removed
+var gpgFingerprintPattern = regexp.MustCompile(`^[0-9A-F]{40}$`)
+
+// IsSSHKey reports whether a trusted key is an SSH public key rather than a GPG fingerprint
+func IsSSHKey(key string) bool {
+	for _, prefix := range []string{"ssh-", "ecdsa-", "sk-"} {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Fingerprint returns a GPG key's fingerprint without spaces, upper-cased
+func (k TrustedKey) Fingerprint() string {
+	return strings.ToUpper(strings.Join(strings.Fields(k.Key), ""))
+}
+
+// Validate rejects GPG keys given as short or long key ids: only the full
+// fingerprint identifies a key, an id suffix is easy to collide
+func (k TrustedKey) Validate() error {
+	if IsSSHKey(k.Key) || gpgFingerprintPattern.MatchString(k.Fingerprint()) {
+		return nil
+	}
+	return fmt.Errorf("trusted key for %s must be an SSH public key or a full 40-hex-digit GPG fingerprint, got %q", k.Host, k.Key)
+}
+
+// Enabled reports whether new WIP commits should be signed
+func (s *Signing) Enabled() bool {
+	return s != nil && s.Format != ""
+}
+
+// KeyPath returns the signing key with a leading ~ expanded
+func (s *Signing) KeyPath() string {
+	if s == nil {
+		return ""
+	}
+	return expandHome(s.Key)
+}
+
+// VerifyMode returns how pull treats unsigned or untrusted WIP refs
+func (s *Signing) VerifyMode() string {
+	if s == nil {
+		return VerifyOff
+	}
+	switch s.Verify {
+	case VerifyOff, VerifyWarn, VerifyRequire:
+		return s.Verify
+	}
+	if len(s.Trusted) > 0 {
+		return VerifyWarn
+	}
+	return VerifyOff
+}
+
+// TrustedKeys returns the trusted keyring (nil-safe)
+func (s *Signing) TrustedKeys() []TrustedKey {
+	if s == nil {
+		return nil
+	}
+	return s.Trusted
+}
+
+// WipRemote routes WIP branches to a dedicated remote instead of origin
+type WipRemote struct {
+	Name string `json:"name,omitempty"` // remote name, default "wip"
+	Root string `json:"root,omitempty"` // directory or URL prefix holding <path>.git mirrors
+}
+
+// RemoteName returns the git remote name used for WIP branches
+func (w *WipRemote) RemoteName() string {
+	if w == nil {
+		return "origin"
+	}
+	if w.Name == "" {
+		return "wip"
+	}
+	return w.Name
+}
+
+// IsLocal reports whether Root is a filesystem path where bare repos can be created
+func (w *WipRemote) IsLocal() bool {
+	if w == nil || w.Root == "" {
+		return false
+	}
+	if strings.Contains(w.Root, "://") {
+		return strings.HasPrefix(w.Root, "file://")
+	}
+	// scp-like syntax: host:path
+	if i := strings.Index(w.Root, ":"); i > 0 && !strings.ContainsAny(w.Root[:i], `/\`) {
+		return false
+	}
+	return true
+}
+
+// URLFor returns the mirror location for a repository, or "" without a root.
+// repoPath is slash-separated and relative to the workspace, so repositories
+// sharing a base name in different directories get different mirrors.
+func (w *WipRemote) URLFor(repoPath string) string {
+	if w == nil || w.Root == "" {
+		return ""
+	}
+	if !w.IsLocal() {
+		return strings.TrimSuffix(w.Root, "/") + "/" + repoPath + ".git"
+	}
+
+	root := expandHome(strings.TrimPrefix(w.Root, "file://"))
+	if abs, err := filepath.Abs(root); err == nil {
+		root = abs
+	}
+	return filepath.Join(root, filepath.FromSlash(repoPath)+".git")
+}
+
+// Hook is a shell command run around a wipctl operation
+type Hook struct {
+	Run     string   `json:"run"`
+	Ops     []string `json:"ops,omitempty"`     // limit repo hooks to these operations
+	Timeout string   `json:"timeout,omitempty"` // Go duration, default 5m
+}
+
+// UnmarshalJSON accepts either a plain command string or a hook object
+func (h *Hook) UnmarshalJSON(data []byte) error {
+	var run string
+	if err := json.Unmarshal(data, &run); err == nil {
+		h.Run = run
+		return nil
+	}
+
+	type hook Hook
+	var parsed hook
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		return err
+	}
+	*h = Hook(parsed)
+	return nil
+}
+
+// AppliesTo reports whether the hook should run for the given operation
+func (h Hook) AppliesTo(op string) bool {
+	if len(h.Ops) == 0 {
+		return true
+	}
+	for _, candidate := range h.Ops {
+		if strings.EqualFold(candidate, op) {
+			return true
+		}
+	}
+	return false
+}
+
+func expandHome(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, path[1:])
+		}
+	}
+	return path
+}
+
+// UserPath returns the per-user config file location
+func UserPath() string {
+	if dir := os.Getenv("WIPCTL_CONFIG_DIR"); dir != "" {
+		return filepath.Join(dir, fileName)
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "wipctl", fileName)
+}
+
+// WorkspacePath returns the workspace config file inside the report directory
+func WorkspacePath(reportDir string) string {
+	return filepath.Join(reportDir, fileName)
+}
+
+// Load reads the user config and overlays the workspace config
+func Load(reportDir string) (*Config, error) {
+	cfg := &Config{}
+
+	for _, path := range []string{UserPath(), WorkspacePath(reportDir)} {
+		if path == "" {
+			continue
+		}
+		if err := loadInto(cfg, path); err != nil {
+			return cfg, err
+		}
+	}
+
+	return cfg, nil
+}
+
+// LoadFile reads a single config file, returning an empty config if it is missing
+func LoadFile(path string) (*Config, error) {
+	cfg := &Config{}
+	return cfg, loadInto(cfg, path)
+}
+
+// Save writes a config file, creating its directory
+func Save(path string, cfg *Config) error {
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal config: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("create config directory: %w", err)
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+func loadInto(cfg *Config, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("read config %s: %w", path, err)
+	}
+
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return fmt.Errorf("parse config %s: %w", path, err)
+	}
+	for _, key := range cfg.Signing.TrustedKeys() {
+		if err := key.Validate(); err != nil {
+			return fmt.Errorf("config %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// Template returns an example starting point for a new config file
+func Template() string {
+	return `{
+  "hooks": {
+    "pre-checkpoint": [],
+    "post-push": [],
+    "pre-repo": [{"run": "gofmt -l -w .", "ops": ["checkpoint", "push"]}],
+    "post-repo": []
+  },
+  "protected_branches": ["main", "master", "release/*"],
+  "naming": {"template": "wip/{host}/{feature}/{ts}", "host_alias": "laptop"},
+  "wip_remote": {"name": "wip", "root": "~/Sync/wip-mirrors"},
+  "signing": {
+    "format": "ssh",
+    "key": "~/.ssh/id_ed25519.pub",
+    "verify": "warn",
+    "trusted": []
+  },
+  "gates": {
+    "enabled": false,
+    "policy": "warn",
+    "types": {"go": ["go build ./...", "go vet ./..."], "node": ["npm test"]},
+    "repos": {"legacy-service": {"skip": true}}
+  },
+  "redaction": {
+    "masks": [{"pattern": "ACME-[0-9]+", "replace": "TICKET"}],
+    "hash_paths": false,
+    "hash_names": false,
+    "denylist": ["secret-*"]
+  },
+  "ai_prices": {"gpt-4o-mini": {"input": 0.15, "output": 0.6}, "ollama": {"input": 0, "output": 0}}
+}
+`
+}
@@ -0,0 +1,162 @@
+// Package gates runs per-repository quality checks (build, vet, tests)
+// before wipctl commits a checkpoint.
+package gates
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/config"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/gitexec"
+)
+
+// DefaultTimeout bounds a single gate command
+const DefaultTimeout = 5 * time.Minute
+
+// maxOutputLines is how much of a failing command's output is kept
+const maxOutputLines = 20
+
+// npmNoTestScript is the placeholder "npm init" writes for the test script
+const npmNoTestScript = `echo "Error: no test specified" && exit 1`
+
+// defaultCommands are the checks run for each detected repo type
+var defaultCommands = map[string][]string{
+	"go":   {"go build ./...", "go vet ./..."},
+	"node": {"npm test"},
+}
+
+// Result is the outcome of one gate command
+type Result struct {
+	Command  string
+	Output   string
+	Duration time.Duration
+	Err      error
+}
+
+// Passed reports whether the command succeeded
+func (r Result) Passed() bool {
+	return r.Err == nil
+}
+
+// Detect returns the repo types found at the root of repoPath
+func Detect(repoPath string) []string {
+	var types []string
+	if fileExists(filepath.Join(repoPath, "go.mod")) {
+		types = append(types, "go")
+	}
+	if hasNpmTests(filepath.Join(repoPath, "package.json")) {
+		types = append(types, "node")
+	}
+	return types
+}
+
+// Commands returns the gate commands for a repository: its config override
+// if any, otherwise the commands for each detected type
+func Commands(repoPath, repoName string, cfg *config.Gates) []string {
+	if override, ok := cfg.RepoOverride(repoName); ok {
+		if override.Skip {
+			return nil
+		}
+		if len(override.Commands) > 0 {
+			return override.Commands
+		}
+	}
+
+	var commands []string
+	for _, repoType := range Detect(repoPath) {
+		if configured, ok := cfg.TypeCommands(repoType); ok {
+			commands = append(commands, configured...)
+			continue
+		}
+		commands = append(commands, defaultCommands[repoType]...)
+	}
+	return commands
+}
+
+// Run executes every command in the repository, in order, so the report
+// shows all failures rather than just the first
+func Run(ctx context.Context, repoPath string, commands []string, timeout time.Duration) []Result {
+	results := make([]Result, 0, len(commands))
+	for _, command := range commands {
+		if gitexec.IsDryRun(ctx) {
+			fmt.Printf("[DRY RUN] Would run quality gate: %s (in %s)\n", command, repoPath)
+			results = append(results, Result{Command: command})
+			continue
+		}
+		results = append(results, runOne(ctx, repoPath, command, timeout))
+	}
+	return results
+}
+
+// Failed returns the commands that did not pass
+func Failed(results []Result) []string {
+	var failed []string
+	for _, result := range results {
+		if !result.Passed() {
+			failed = append(failed, result.Command)
+		}
+	}
+	return failed
+}
+
+func runOne(ctx context.Context, repoPath, command string, timeout time.Duration) Result {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var output bytes.Buffer
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Dir = repoPath
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	// Children of a timed-out command may hold the output open; do not wait for them
+	cmd.WaitDelay = time.Second
+
+	slog.Debug("Running quality gate", "repo", repoPath, "command", command)
+	started := time.Now()
+	err := cmd.Run()
+	result := Result{Command: command, Duration: time.Since(started), Err: err}
+	if ctx.Err() == context.DeadlineExceeded {
+		result.Err = fmt.Errorf("timed out after %s", timeout)
+	}
+	if err != nil {
+		result.Output = tail(strings.TrimSpace(output.String()), maxOutputLines)
+	}
+	return result
+}
+
+func tail(output string, lines int) string {
+	all := strings.Split(output, "\n")
+	if len(all) <= lines {
+		return output
+	}
+	return "...\n" + strings.Join(all[len(all)-lines:], "\n")
+}
+
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
+
+// hasNpmTests reports whether package.json defines a real test script
+func hasNpmTests(path string) bool {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	var pkg struct {
+		Scripts map[string]string `json:"scripts"`
+	}
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return false
+	}
+	test := strings.TrimSpace(pkg.Scripts["test"])
+	return test != "" && test != npmNoTestScript
+}
@@ -0,0 +1,95 @@
+package gates
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/config"
+)
+
+func TestCommands(t *testing.T) {
+	goRepo := t.TempDir()
+	writeFile(t, filepath.Join(goRepo, "go.mod"), "module example\n")
+	writeFile(t, filepath.Join(goRepo, "package.json"), `{"scripts":{"test":"echo \"Error: no test specified\" && exit 1"}}`)
+	nodeRepo := t.TempDir()
+	writeFile(t, filepath.Join(nodeRepo, "package.json"), `{"scripts":{"test":"jest"}}`)
+
+	cfg := &config.Gates{
+		Types: map[string][]string{"node": {"npm run lint", "npm test"}},
+		Repos: map[string]config.RepoGates{
+			"skipped":  {Skip: true},
+			"custom":   {Commands: []string{"make check"}},
+			"policied": {Policy: config.GateBlock},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		repoPath string
+		repoName string
+		cfg      *config.Gates
+		want     []string
+	}{
+		{name: "go defaults, npm placeholder ignored", repoPath: goRepo, repoName: "api", want: []string{"go build ./...", "go vet ./..."}},
+		{name: "type commands configured", repoPath: nodeRepo, repoName: "web", cfg: cfg, want: []string{"npm run lint", "npm test"}},
+		{name: "repo skipped", repoPath: goRepo, repoName: "skipped", cfg: cfg},
+		{name: "repo commands replace detection", repoPath: nodeRepo, repoName: "custom", cfg: cfg, want: []string{"make check"}},
+		{name: "policy-only override keeps detection", repoPath: goRepo, repoName: "policied", cfg: cfg, want: []string{"go build ./...", "go vet ./..."}},
+		{name: "nothing detected", repoPath: t.TempDir(), repoName: "docs", cfg: cfg},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Commands(tt.repoPath, tt.repoName, tt.cfg)
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("Commands = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunReportsEveryFailure(t *testing.T) {
+	commands := []string{"echo first; exit 1", "true", "echo second >&2; exit 2", "sleep 5"}
+	results := Run(context.Background(), t.TempDir(), commands, 500*time.Millisecond)
+
+	if len(results) != len(commands) {
+		t.Fatalf("len(results) = %d, want %d", len(results), len(commands))
+	}
+	if got := Failed(results); strings.Join(got, "|") != "echo first; exit 1|echo second >&2; exit 2|sleep 5" {
+		t.Errorf("Failed = %q, want every failing command in order", got)
+	}
+	if results[0].Output != "first" || results[2].Output != "second" {
+		t.Errorf("outputs = %q, %q, want the failing commands' output", results[0].Output, results[2].Output)
+	}
+	if results[1].Output != "" {
+		t.Errorf("passing command output = %q, want none kept", results[1].Output)
+	}
+	if err := results[3].Err; err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("slow command error = %v, want a timeout", err)
+	}
+}
+
+func TestTailKeepsLastLines(t *testing.T) {
+	var lines []string
+	for i := 0; i < 30; i++ {
+		lines = append(lines, strings.Repeat("x", i+1))
+	}
+	got := tail(strings.Join(lines, "\n"), maxOutputLines)
+	if !strings.HasPrefix(got, "...\n") || !strings.HasSuffix(got, lines[29]) {
+		t.Errorf("tail = %q, want an ellipsis then the last lines", got)
+	}
+	if n := len(strings.Split(got, "\n")); n != maxOutputLines+1 {
+		t.Errorf("tail kept %d lines, want %d plus the ellipsis", n-1, maxOutputLines)
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+}
@@ -2,8 +2,13 @@ package gitexec
 
 import (
 	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
@@ -36,6 +41,33 @@ var junkRegex = regexp.MustCompile(`(?i)(node_modules|\.venv|venv|dist|build|\.t
 type contextKey string
 const DryRunKey contextKey = "dry-run"
 
+// SigningKey is the context key for WIP commit signing settings
+const SigningKey contextKey = "signing"
+
+// Signing selects how WIP commits are signed ("ssh" or "gpg" format)
+type Signing struct {
+	Format string
+	Key    string
+}
+
+// WithSigning returns a context whose commits are signed with s
+func WithSigning(ctx context.Context, s Signing) context.Context {
+	return context.WithValue(ctx, SigningKey, s)
+}
+
+// signingArgs returns the -c overrides for signing plus a " -S" marker when enabled
+func signingArgs(ctx context.Context) ([]string, string) {
+	s, ok := ctx.Value(SigningKey).(Signing)
+	if !ok || s.Format == "" {
+		return nil, ""
+	}
+	args := []string{"-c", "gpg.format=" + s.Format}
+	if s.Key != "" {
+		args = append(args, "-c", "user.signingkey="+s.Key)
+	}
+	return args, " -S"
+}
+
 // IsDryRun checks if the context indicates dry-run mode
 func IsDryRun(ctx context.Context) bool {
 	if val := ctx.Value(DryRunKey); val != nil {
@@ -163,7 +195,16 @@ func Fetch(ctx context.Context, repoPath string) error {
 		fmt.Printf("[DRY RUN] Would fetch: git fetch --prune --quiet (in %s)\n", repoPath)
 		return nil
 	}
-	return runGit(ctx, repoPath, "fetch", "--prune", "--quiet")
+	return runGitCombined(ctx, repoPath, "fetch", "--prune", "--quiet")
+}
+
+// FetchRemote fetches a single named remote
+func FetchRemote(ctx context.Context, repoPath, remote string) error {
+	if IsDryRun(ctx) {
+		fmt.Printf("[DRY RUN] Would fetch: git fetch --prune --quiet %s (in %s)\n", remote, repoPath)
+		return nil
+	}
+	return runGitCombined(ctx, repoPath, "fetch", "--prune", "--quiet", remote)
 }
 
 func AddAll(ctx context.Context, repoPath string) error {
@@ -175,11 +216,16 @@ func AddAll(ctx context.Context, repoPath string) error {
 }
 
 func CommitAllowEmpty(ctx context.Context, repoPath, message string) error {
+	signArgs, signFlag := signingArgs(ctx)
 	if IsDryRun(ctx) {
-		fmt.Printf("[DRY RUN] Would commit: git commit --allow-empty -m \"%s\" (in %s)\n", message, repoPath)
+		fmt.Printf("[DRY RUN] Would commit: git commit --allow-empty%s -m \"%s\" (in %s)\n", signFlag, message, repoPath)
 		return nil
 	}
-	return runGit(ctx, repoPath, "commit", "--allow-empty", "-m", message)
+	args := append(signArgs, "commit", "--allow-empty")
+	if signFlag != "" {
+		args = append(args, "-S")
+	}
+	return runGit(ctx, repoPath, append(args, "-m", message)...)
 }
 
 func SwitchCreate(ctx context.Context, repoPath, branch string) error {
@@ -190,20 +236,43 @@ func SwitchCreate(ctx context.Context, repoPath, branch string) error {
 	return runGit(ctx, repoPath, "switch", "-C", branch)
 }
 
+// SwitchTrack creates (or resets) a local branch at a remote-tracking ref and tracks it
+func SwitchTrack(ctx context.Context, repoPath, branch, remoteRef string) error {
+	if IsDryRun(ctx) {
+		fmt.Printf("[DRY RUN] Would switch to tracking branch: git switch -C %s --track %s (in %s)\n", branch, remoteRef, repoPath)
+		return nil
+	}
+	return runGit(ctx, repoPath, "switch", "-C", branch, "--track", remoteRef)
+}
+
+// SwitchCreateAt creates (or resets) a local branch at startPoint without tracking
+func SwitchCreateAt(ctx context.Context, repoPath, branch, startPoint string) error {
+	if IsDryRun(ctx) {
+		fmt.Printf("[DRY RUN] Would create/switch branch: git switch -C %s %s (in %s)\n", branch, startPoint, repoPath)
+		return nil
+	}
+	return runGit(ctx, repoPath, "switch", "-C", branch, startPoint)
+}
+
 func Switch(ctx context.Context, repoPath, branch string) error {
 	if IsDryRun(ctx) {
 		fmt.Printf("[DRY RUN] Would switch branch: git switch %s (in %s)\n", branch, repoPath)
 		return nil
 	}
-	return runGit(ctx, repoPath, "switch", branch)
+	return runGitCombined(ctx, repoPath, "switch", branch)
 }
 
 func PushUpstream(ctx context.Context, repoPath, branch string) error {
+	return PushUpstreamTo(ctx, repoPath, "origin", branch)
+}
+
+// PushUpstreamTo pushes a branch to the given remote and sets it as upstream
+func PushUpstreamTo(ctx context.Context, repoPath, remote, branch string) error {
 	if IsDryRun(ctx) {
-		fmt.Printf("[DRY RUN] Would push with upstream: git push -u origin %s (in %s)\n", branch, repoPath)
+		fmt.Printf("[DRY RUN] Would push with upstream: git push -u %s %s (in %s)\n", remote, branch, repoPath)
 		return nil
 	}
-	return runGit(ctx, repoPath, "push", "-u", "origin", branch)
+	return runGitCombined(ctx, repoPath, "push", "-u", remote, branch)
 }
 
 func Push(ctx context.Context, repoPath, branch string) error {
@@ -211,7 +280,59 @@ func Push(ctx context.Context, repoPath, branch string) error {
 		fmt.Printf("[DRY RUN] Would push: git push origin %s (in %s)\n", branch, repoPath)
 		return nil
 	}
-	return runGit(ctx, repoPath, "push", "origin", branch)
+	return runGitCombined(ctx, repoPath, "push", "origin", branch)
+}
+
+// PushWithLease pushes branch to remote with --force-with-lease, expecting the
+// remote ref to be at expected ("" means it must not exist yet)
+func PushWithLease(ctx context.Context, repoPath, remote, branch, expected string) error {
+	lease := fmt.Sprintf("--force-with-lease=refs/heads/%s:%s", branch, expected)
+	if IsDryRun(ctx) {
+		fmt.Printf("[DRY RUN] Would push: git push -u %s %s %s (in %s)\n", lease, remote, branch, repoPath)
+		return nil
+	}
+	return runGitCombined(ctx, repoPath, "push", "-u", lease, remote, branch)
+}
+
+// PushDelete deletes branch on remote, expecting it to still be at expected
+func PushDelete(ctx context.Context, repoPath, remote, branch, expected string) error {
+	lease := fmt.Sprintf("--force-with-lease=refs/heads/%s:%s", branch, expected)
+	if IsDryRun(ctx) {
+		fmt.Printf("[DRY RUN] Would delete remote branch: git push %s %s :refs/heads/%s (in %s)\n", lease, remote, branch, repoPath)
+		return nil
+	}
+	return runGitCombined(ctx, repoPath, "push", lease, remote, ":refs/heads/"+branch)
+}
+
+// networkErrorMarkers are git/ssh/curl messages for an unreachable remote,
+// as opposed to a rejected push or a missing repository
+var networkErrorMarkers = []string{
+	"could not resolve host",
+	"could not resolve hostname",
+	"temporary failure in name resolution",
+	"network is unreachable",
+	"no route to host",
+	"connection refused",
+	"connection timed out",
+	"operation timed out",
+	"connection reset",
+	"failed to connect to",
+	"could not connect to server",
+}
+
+// IsNetworkError reports whether a fetch/push error (with git's output folded
+// in) means the remote could not be reached
+func IsNetworkError(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	for _, marker := range networkErrorMarkers {
+		if strings.Contains(msg, marker) {
+			return true
+		}
+	}
+	return false
 }
 
 func RemoteHasBranch(ctx context.Context, repoPath, branch string) (bool, error) {
@@ -222,20 +343,98 @@ func RemoteHasBranch(ctx context.Context, repoPath, branch string) (bool, error)
 	return strings.TrimSpace(out) != "", nil
 }
 
-func Stash(ctx context.Context, repoPath, message string) error {
+// StashEntry is one entry of the stash list
+type StashEntry struct {
+	Ref     string // stash@{n}; only valid until the stack changes
+	Commit  string
+	Message string
+}
+
+// ErrStashNotFound is returned when a stash commit is no longer in the stash list
+var ErrStashNotFound = errors.New("stash entry not found")
+
+// StashPush stashes tracked and untracked changes and returns the new stash
+// commit, or "" when there was nothing to stash
+func StashPush(ctx context.Context, repoPath, message string) (string, error) {
 	if IsDryRun(ctx) {
 		fmt.Printf("[DRY RUN] Would stash: git stash push -u -m \"%s\" (in %s)\n", message, repoPath)
+		return "", nil
+	}
+
+	before := ResolveRef(ctx, repoPath, "refs/stash")
+	if err := runGitCombined(ctx, repoPath, "stash", "push", "-u", "-m", message); err != nil {
+		return "", err
+	}
+	after := ResolveRef(ctx, repoPath, "refs/stash")
+	if after == before {
+		return "", nil
+	}
+	return after, nil
+}
+
+// StashList returns the stash entries, newest first
+func StashList(ctx context.Context, repoPath string) ([]StashEntry, error) {
+	out, err := runGitOutput(ctx, repoPath, "stash", "list", "--format=%gd%x00%H%x00%gs")
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []StashEntry
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.SplitN(line, "\x00", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		entries = append(entries, StashEntry{Ref: fields[0], Commit: fields[1], Message: fields[2]})
+	}
+	return entries, nil
+}
+
+// StashRef returns the current stash@{n} of a stash commit
+func StashRef(ctx context.Context, repoPath, commit string) (string, error) {
+	entries, err := StashList(ctx, repoPath)
+	if err != nil {
+		return "", err
+	}
+	for _, entry := range entries {
+		if entry.Commit == commit {
+			return entry.Ref, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s", ErrStashNotFound, commit)
+}
+
+// StashApply applies a stash commit without removing it from the stash list
+func StashApply(ctx context.Context, repoPath, commit string) error {
+	if IsDryRun(ctx) {
+		fmt.Printf("[DRY RUN] Would apply stash: git stash apply %s (in %s)\n", commit, repoPath)
 		return nil
 	}
-	return runGit(ctx, repoPath, "stash", "push", "-u", "-m", message)
+	return runGitCombined(ctx, repoPath, "stash", "apply", commit)
 }
 
-func StashPop(ctx context.Context, repoPath string) error {
+// StashPop applies a stash commit and drops it from the stash list, wherever
+// it now sits on the stack. Like git, the entry is kept when applying conflicts.
+func StashPop(ctx context.Context, repoPath, commit string) error {
+	ref, err := StashRef(ctx, repoPath, commit)
+	if err != nil {
+		return err
+	}
 	if IsDryRun(ctx) {
-		fmt.Printf("[DRY RUN] Would pop stash: git stash pop (in %s)\n", repoPath)
+		fmt.Printf("[DRY RUN] Would pop stash: git stash pop %s (in %s)\n", ref, repoPath)
 		return nil
 	}
-	return runGit(ctx, repoPath, "stash", "pop")
+	return runGitCombined(ctx, repoPath, "stash", "pop", ref)
+}
+
+// StashDropCommit drops a stash commit from the stash list, wherever it now
+// sits on the stack
+func StashDropCommit(ctx context.Context, repoPath, commit string) error {
+	ref, err := StashRef(ctx, repoPath, commit)
+	if err != nil {
+		return err
+	}
+	return StashDrop(ctx, repoPath, ref)
 }
 
 func HasConflicts(ctx context.Context, repoPath string) (bool, []string, error) {
@@ -248,55 +447,285 @@ func HasConflicts(ctx context.Context, repoPath string) (bool, []string, error)
 	return len(conflicted) > 0, conflicted, nil
 }
 
-func LatestRemoteWIP(ctx context.Context, repoPath string) (string, error) {
-	out, err := runGitOutput(ctx, repoPath,
-		"for-each-ref",
-		"--format=%(committerdate:iso8601) %(refname)",
-		"refs/remotes/origin/wip/")
-	if err != nil {
-		return "", err
+// ConflictStage returns a conflicted file's content at an index stage
+// (1 = common ancestor, 2 = ours, 3 = theirs)
+func ConflictStage(ctx context.Context, repoPath, file string, stage int) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", "show", fmt.Sprintf(":%d:%s", stage, file))
+	cmd.Dir = repoPath
+	out, err := cmd.Output()
+	return string(out), err
+}
+
+// CheckoutConflictSide resolves a conflicted file by taking one side ("ours" or "theirs")
+func CheckoutConflictSide(ctx context.Context, repoPath, file, side string) error {
+	if IsDryRun(ctx) {
+		fmt.Printf("[DRY RUN] Would resolve: git checkout --%s -- %s (in %s)\n", side, file, repoPath)
+		return nil
 	}
+	return runGit(ctx, repoPath, "checkout", "--"+side, "--", file)
+}
 
-	lines := strings.Split(strings.TrimSpace(out), "\n")
-	if len(lines) == 0 || lines[0] == "" {
-		return "", fmt.Errorf("no WIP branches found")
+// RemovePath stages the deletion of a file
+func RemovePath(ctx context.Context, repoPath, file string) error {
+	if IsDryRun(ctx) {
+		fmt.Printf("[DRY RUN] Would remove: git rm -q -- %s (in %s)\n", file, repoPath)
+		return nil
 	}
+	return runGit(ctx, repoPath, "rm", "-q", "--", file)
+}
 
-	var latest string
-	var latestTime time.Time
+// AddPaths stages specific files
+func AddPaths(ctx context.Context, repoPath string, paths ...string) error {
+	if IsDryRun(ctx) {
+		fmt.Printf("[DRY RUN] Would add: git add -- %s (in %s)\n", strings.Join(paths, " "), repoPath)
+		return nil
+	}
+	return runGit(ctx, repoPath, append([]string{"add", "--"}, paths...)...)
+}
 
-	for _, line := range lines {
-		parts := strings.SplitN(line, " ", 2)
-		if len(parts) != 2 {
+// InProgressOperation reports which multi-step operation is paused in the repo:
+// "rebase", "merge", "cherry-pick" or "" when none is
+func InProgressOperation(ctx context.Context, repoPath string) string {
+	markers := []struct {
+		path string
+		op   string
+	}{
+		{"rebase-merge", "rebase"},
+		{"rebase-apply", "rebase"},
+		{"MERGE_HEAD", "merge"},
+		{"CHERRY_PICK_HEAD", "cherry-pick"},
+	}
+
+	for _, marker := range markers {
+		gitPath, err := runGitOutput(ctx, repoPath, "rev-parse", "--git-path", marker.path)
+		if err != nil {
+			continue
+		}
+		if !filepath.IsAbs(gitPath) {
+			gitPath = filepath.Join(repoPath, gitPath)
+		}
+		if _, err := os.Stat(gitPath); err == nil {
+			return marker.op
+		}
+	}
+	return ""
+}
+
+// ContinueOperation resumes a paused rebase, merge or cherry-pick without opening an editor
+func ContinueOperation(ctx context.Context, repoPath, op string) error {
+	var args []string
+	switch op {
+	case "rebase":
+		args = []string{"rebase", "--continue"}
+	case "merge":
+		args = []string{"commit", "--no-edit"}
+	case "cherry-pick":
+		args = []string{"cherry-pick", "--continue"}
+	default:
+		return fmt.Errorf("unknown operation %q", op)
+	}
+
+	if IsDryRun(ctx) {
+		fmt.Printf("[DRY RUN] Would continue: git %s (in %s)\n", strings.Join(args, " "), repoPath)
+		return nil
+	}
+
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = repoPath
+	cmd.Env = append(os.Environ(), "GIT_EDITOR=true")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// StashDrop drops a stash entry (e.g. "stash@{0}")
+func StashDrop(ctx context.Context, repoPath, ref string) error {
+	if IsDryRun(ctx) {
+		fmt.Printf("[DRY RUN] Would drop stash: git stash drop %s (in %s)\n", ref, repoPath)
+		return nil
+	}
+	return runGit(ctx, repoPath, "stash", "drop", "-q", ref)
+}
+
+// ResetIndex unstages everything while keeping worktree changes
+func ResetIndex(ctx context.Context, repoPath string) error {
+	if IsDryRun(ctx) {
+		fmt.Printf("[DRY RUN] Would unstage: git reset -q (in %s)\n", repoPath)
+		return nil
+	}
+	return runGit(ctx, repoPath, "reset", "-q")
+}
+
+// Ref is a git ref with its target commit and commit time
+type Ref struct {
+	Name   string // full refname, e.g. refs/remotes/origin/wip/host/20250101-120000
+	Commit string
+	Time   time.Time
+}
+
+// ListRefs lists refs under the given for-each-ref patterns (e.g. "refs/remotes/origin/wip/")
+func ListRefs(ctx context.Context, repoPath string, patterns ...string) ([]Ref, error) {
+	args := append([]string{"for-each-ref", "--format=%(committerdate:unix) %(objectname) %(refname)"}, patterns...)
+	out, err := runGitOutput(ctx, repoPath, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var refs []Ref
+	for _, line := range strings.Split(out, "\n") {
+		parts := strings.SplitN(line, " ", 3)
+		if len(parts) != 3 {
 			continue
 		}
 
-		t, err := time.Parse("2006-01-02 15:04:05 -0700", parts[0])
+		seconds, err := strconv.ParseInt(parts[0], 10, 64)
 		if err != nil {
 			continue
 		}
+		refs = append(refs, Ref{Name: parts[2], Commit: parts[1], Time: time.Unix(seconds, 0)})
+	}
+	return refs, nil
+}
 
-		if latest == "" || t.After(latestTime) {
-			latest = parts[1]
-			latestTime = t
+func TrimOrigin(remoteRef string) string {
+	return TrimRemote(remoteRef, "origin")
+}
+
+// TrimRemote strips refs/remotes/<remote>/ from a remote-tracking ref
+func TrimRemote(remoteRef, remote string) string {
+	return strings.TrimPrefix(remoteRef, "refs/remotes/"+remote+"/")
+}
+
+// RemoteURL returns the configured URL of a remote
+func RemoteURL(ctx context.Context, repoPath, remote string) (string, error) {
+	return runGitOutput(ctx, repoPath, "remote", "get-url", remote)
+}
+
+// AddRemote registers a new remote
+func AddRemote(ctx context.Context, repoPath, remote, url string) error {
+	if IsDryRun(ctx) {
+		fmt.Printf("[DRY RUN] Would add remote: git remote add %s %s (in %s)\n", remote, url, repoPath)
+		return nil
+	}
+	return runGit(ctx, repoPath, "remote", "add", remote, url)
+}
+
+// InitBare creates an empty bare repository at path
+func InitBare(ctx context.Context, path string) error {
+	if IsDryRun(ctx) {
+		fmt.Printf("[DRY RUN] Would create bare repo: git init --bare %s\n", path)
+		return nil
+	}
+	if err := os.MkdirAll(path, 0755); err != nil {
+		return err
+	}
+	return runGit(ctx, path, "init", "--quiet", "--bare")
+}
+
+// Signature describes the signature on a commit as reported by git log %G?
+type Signature struct {
+	Status      string // G good, U good but unknown validity, N none, B bad, E cannot check, X/Y/R expired/revoked
+	Signer      string
+	Key         string
+	Fingerprint string // fingerprint of the signing key, often a subkey
+	Primary     string // fingerprint of the signing key's primary key
+}
+
+// CommitSignature inspects the signature of ref. allowedSignersFile is used to
+// resolve SSH signers; it may be empty when only GPG keys are trusted.
+func CommitSignature(ctx context.Context, repoPath, ref, allowedSignersFile string) (Signature, error) {
+	var args []string
+	if allowedSignersFile != "" {
+		args = append(args, "-c", "gpg.ssh.allowedSignersFile="+allowedSignersFile)
+	}
+	args = append(args, "log", "-1", "--format=%G?%x00%GS%x00%GK%x00%GF%x00%GP", ref)
+
+	out, err := runGitOutput(ctx, repoPath, args...)
+	if err != nil {
+		return Signature{}, err
+	}
+
+	parts := strings.Split(out, "\x00")
+	for len(parts) < 5 {
+		parts = append(parts, "")
+	}
+	return Signature{Status: parts[0], Signer: parts[1], Key: parts[2], Fingerprint: parts[3], Primary: parts[4]}, nil
+}
+
+// ErrEmptyBundle is returned when a thin bundle would contain no commits
+var ErrEmptyBundle = errors.New("nothing to bundle beyond the excluded remote")
+
+// BundleCreate writes a git bundle of the given branches. When excludeRemote is
+// set, commits already reachable from that remote are left out (a thin bundle).
+func BundleCreate(ctx context.Context, repoPath, file string, branches []string, excludeRemote string) error {
+	args := []string{"bundle", "create", "--quiet", file}
+	for _, branch := range branches {
+		args = append(args, "refs/heads/"+branch)
+	}
+	if excludeRemote != "" {
+		args = append(args, "--not", "--remotes="+excludeRemote)
+	}
+
+	if IsDryRun(ctx) {
+		fmt.Printf("[DRY RUN] Would create bundle: git %s (in %s)\n", strings.Join(args, " "), repoPath)
+		return nil
+	}
+	if err := runGitCombined(ctx, repoPath, args...); err != nil {
+		if strings.Contains(err.Error(), "empty bundle") {
+			return ErrEmptyBundle
 		}
+		return err
 	}
+	return nil
+}
 
-	if latest == "" {
-		return "", fmt.Errorf("no valid WIP branches found")
+// BundleHeads lists the branch names a bundle actually contains
+func BundleHeads(ctx context.Context, repoPath, file string) ([]string, error) {
+	out, err := runGitOutput(ctx, repoPath, "bundle", "list-heads", file)
+	if err != nil {
+		return nil, err
 	}
 
-	return latest, nil
+	var heads []string
+	for _, line := range strings.Split(out, "\n") {
+		parts := strings.Fields(line)
+		if len(parts) == 2 {
+			heads = append(heads, strings.TrimPrefix(parts[1], "refs/heads/"))
+		}
+	}
+	return heads, nil
 }
 
-func TrimOrigin(remoteRef string) string {
-	return strings.TrimPrefix(remoteRef, "refs/remotes/origin/")
+// BundleVerify checks that a bundle is valid and its prerequisites exist in the repo
+func BundleVerify(ctx context.Context, repoPath, file string) error {
+	return runGitCombined(ctx, repoPath, "bundle", "verify", "--quiet", file)
+}
+
+// FetchBundle fetches the heads under each branch prefix (e.g. "wip/") of a
+// bundle into refs/remotes/<remote>/
+func FetchBundle(ctx context.Context, repoPath, file, remote string, prefixes []string) error {
+	args := []string{"fetch", "--quiet", file}
+	for _, prefix := range prefixes {
+		args = append(args, fmt.Sprintf("+refs/heads/%s*:refs/remotes/%s/%s*", prefix, remote, prefix))
+	}
+
+	if IsDryRun(ctx) {
+		fmt.Printf("[DRY RUN] Would fetch bundle: git %s (in %s)\n", strings.Join(args, " "), repoPath)
+		return nil
+	}
+	return runGitCombined(ctx, repoPath, args...)
 }
 
 func DiffNameStatusCached(ctx context.Context, repoPath string) (string, error) {
 	return runGitOutput(ctx, repoPath, "diff", "--cached", "--name-status")
 }
 
+// DiffCached returns the staged changes as a unified diff
+func DiffCached(ctx context.Context, repoPath string) (string, error) {
+	return runGitOutput(ctx, repoPath, "diff", "--cached", "--no-color", "--no-ext-diff", "--find-renames")
+}
+
 func DiffStatCached(ctx context.Context, repoPath string) (string, error) {
 	return runGitOutput(ctx, repoPath, "diff", "--cached", "--stat")
 }
@@ -321,6 +750,51 @@ func ListUntracked(ctx context.Context, repoPath string) ([]string, error) {
 	return getUntrackedFiles(ctx, repoPath)
 }
 
+// DirtyCount returns the number of changed and untracked paths
+func DirtyCount(ctx context.Context, repoPath string) (int, error) {
+	return getDirtyCount(ctx, repoPath)
+}
+
+// WorktreeSignature fingerprints the current worktree state (HEAD, porcelain
+// status and the modification times of every changed path) so callers can
+// tell whether anything was touched between two polls.
+func WorktreeSignature(ctx context.Context, repoPath string) (string, error) {
+	head, _ := runGitOutput(ctx, repoPath, "rev-parse", "HEAD")
+
+	cmd := exec.CommandContext(ctx, "git", "status", "--porcelain", "-z", "--untracked-files=all")
+	cmd.Dir = repoPath
+	raw, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	out := string(raw)
+
+	h := sha1.New()
+	fmt.Fprintf(h, "%s\x00%s\x00", head, out)
+
+	fields := strings.Split(out, "\x00")
+	for i := 0; i < len(fields); i++ {
+		entry := fields[i]
+		if len(entry) < 4 {
+			continue
+		}
+		// A rename or copy is followed by a field holding just its source path
+		if strings.ContainsAny(entry[:2], "RC") {
+			i++
+		}
+		if info, err := os.Stat(filepath.Join(repoPath, entry[3:])); err == nil {
+			fmt.Fprintf(h, "%s\x00%d\x00%d\x00", entry[3:], info.ModTime().UnixNano(), info.Size())
+		}
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// CurrentBranch returns the checked out branch name (HEAD when detached)
+func CurrentBranch(ctx context.Context, repoPath string) (string, error) {
+	return getCurrentBranch(ctx, repoPath)
+}
+
 func getCurrentBranch(ctx context.Context, repoPath string) (string, error) {
 	return runGitOutput(ctx, repoPath, "rev-parse", "--abbrev-ref", "HEAD")
 }
@@ -341,7 +815,10 @@ func getDirtyCount(ctx context.Context, repoPath string) (int, error) {
 	if err != nil {
 		return 0, err
 	}
-	return len(strings.Split(strings.TrimSpace(out), "\n")), nil
+	if out == "" {
+		return 0, nil
+	}
+	return len(strings.Split(out, "\n")), nil
 }
 
 func getUntrackedFiles(ctx context.Context, repoPath string) ([]string, error) {
@@ -380,6 +857,16 @@ func runGit(ctx context.Context, repoPath string, args ...string) error {
 	return cmd.Run()
 }
 
+// runGitCombined runs git and folds its output into the error on failure
+func runGitCombined(ctx context.Context, repoPath string, args ...string) error {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = repoPath
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
 func runGitOutput(ctx context.Context, repoPath string, args ...string) (string, error) {
 	cmd := exec.CommandContext(ctx, "git", args...)
 	cmd.Dir = repoPath
@@ -397,8 +884,14 @@ func getDiffStats(ctx context.Context, repoPath string) (linesAdded, linesRemove
 		return 0, 0, 0, err
 	}
 
+	linesAdded, linesRemoved, filesChanged = parseNumstat(out)
+	return linesAdded, linesRemoved, filesChanged, nil
+}
+
+// parseNumstat totals the output of git diff --numstat
+func parseNumstat(out string) (linesAdded, linesRemoved, filesChanged int) {
 	if strings.TrimSpace(out) == "" {
-		return 0, 0, 0, nil
+		return 0, 0, 0
 	}
 
 	lines := strings.Split(strings.TrimSpace(out), "\n")
@@ -420,7 +913,7 @@ func getDiffStats(ctx context.Context, repoPath string) (linesAdded, linesRemove
 		}
 	}
 
-	return linesAdded, linesRemoved, filesChanged, nil
+	return linesAdded, linesRemoved, filesChanged
 }
 
 // getCommitCount gets total commits in the current branch
@@ -460,6 +953,343 @@ func getRepoSize(ctx context.Context, repoPath string) (string, error) {
 	return size, nil
 }
 
+// ResolveRef returns the commit a revision points to, or "" when it does not exist
+func ResolveRef(ctx context.Context, repoPath, rev string) string {
+	out, err := runGitOutput(ctx, repoPath, "rev-parse", "--verify", "--quiet", rev+"^{commit}")
+	if err != nil {
+		return ""
+	}
+	return out
+}
+
+// TreeOf returns the tree id of a commit
+func TreeOf(ctx context.Context, repoPath, rev string) (string, error) {
+	return runGitOutput(ctx, repoPath, "rev-parse", rev+"^{tree}")
+}
+
+// IsAncestor reports whether ancestor is reachable from rev
+func IsAncestor(ctx context.Context, repoPath, ancestor, rev string) bool {
+	return runGit(ctx, repoPath, "merge-base", "--is-ancestor", ancestor, rev) == nil
+}
+
+// CommitTrailer returns the value of a trailer (e.g. "Wip-Base") on a commit
+func CommitTrailer(ctx context.Context, repoPath, rev, key string) (string, error) {
+	return runGitOutput(ctx, repoPath, "log", "-1", fmt.Sprintf("--format=%%(trailers:key=%s,valueonly)", key), rev)
+}
+
+// WriteTree writes the current index as a tree object
+func WriteTree(ctx context.Context, repoPath string) (string, error) {
+	if IsDryRun(ctx) {
+		fmt.Printf("[DRY RUN] Would write tree: git write-tree (in %s)\n", repoPath)
+		return "", nil
+	}
+	return runGitOutput(ctx, repoPath, "write-tree")
+}
+
+// CommitTree creates a commit object for tree with the given parents, signed
+// when signing is enabled on the context
+func CommitTree(ctx context.Context, repoPath, tree, message string, parents ...string) (string, error) {
+	signArgs, signFlag := signingArgs(ctx)
+	args := append(signArgs, "commit-tree", tree)
+	for _, parent := range parents {
+		args = append(args, "-p", parent)
+	}
+	if signFlag != "" {
+		args = append(args, "-S")
+	}
+
+	if IsDryRun(ctx) {
+		fmt.Printf("[DRY RUN] Would create commit: git commit-tree %s%s (parents %v) (in %s)\n", tree, signFlag, parents, repoPath)
+		return "", nil
+	}
+
+	cmd := exec.CommandContext(ctx, "git", append(args, "-m", message)...)
+	cmd.Dir = repoPath
+	out, err := cmd.Output()
+	return strings.TrimSpace(string(out)), err
+}
+
+// StashCreate records the uncommitted tracked changes as a stash commit
+// without touching the worktree, index or stash list. It returns "" when
+// there is nothing to stash.
+func StashCreate(ctx context.Context, repoPath string) (string, error) {
+	return runGitOutput(ctx, repoPath, "stash", "create")
+}
+
+// PathExists reports whether path exists in the tree of rev
+func PathExists(ctx context.Context, repoPath, rev, path string) bool {
+	return runGit(ctx, repoPath, "cat-file", "-e", rev+":"+path) == nil
+}
+
+// probeIdentity lets MergeTree write throwaway commits without a configured user
+var probeIdentity = []string{"-c", "user.name=wipctl", "-c", "user.email=wipctl@localhost"}
+
+// MergeTree merges ours and theirs in memory with git merge-tree --write-tree
+// and returns the conflicted paths; nothing in the worktree or index changes.
+// A non-empty base forces the merge base (a commit or tree), the way
+// "git stash pop" uses the stash's parent; this is emulated with throwaway
+// parentless commits since merge-tree only gained --merge-base in git 2.40.
+func MergeTree(ctx context.Context, repoPath, base, ours, theirs string) ([]string, error) {
+	if base != "" {
+		probe, err := runGitOutput(ctx, repoPath, append(probeIdentity, "commit-tree", base+"^{tree}", "-m", "wipctl merge probe base")...)
+		if err != nil {
+			return nil, fmt.Errorf("probe base commit: %w", err)
+		}
+		if ours, err = runGitOutput(ctx, repoPath, append(probeIdentity, "commit-tree", ours+"^{tree}", "-p", probe, "-m", "wipctl merge probe")...); err != nil {
+			return nil, fmt.Errorf("probe commit: %w", err)
+		}
+		if theirs, err = runGitOutput(ctx, repoPath, append(probeIdentity, "commit-tree", theirs+"^{tree}", "-p", probe, "-m", "wipctl merge probe")...); err != nil {
+			return nil, fmt.Errorf("probe commit: %w", err)
+		}
+	}
+
+	cmd := exec.CommandContext(ctx, "git", "merge-tree", "--write-tree", "--name-only", "--no-messages", ours, theirs)
+	cmd.Dir = repoPath
+	out, err := cmd.Output()
+
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		return nil, nil
+	case errors.As(err, &exitErr) && exitErr.ExitCode() == 1:
+		// Exit status 1 means conflicts: the tree id, then one path per line
+		lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+		var conflicts []string
+		for _, line := range lines[1:] {
+			if line = strings.TrimSpace(line); line != "" {
+				conflicts = append(conflicts, line)
+			}
+		}
+		return conflicts, nil
+	default:
+		return nil, fmt.Errorf("git merge-tree: %w", err)
+	}
+}
+
+// UpdateRef points ref at newValue, failing if it no longer points at oldValue
+// ("" means the ref must not exist)
+func UpdateRef(ctx context.Context, repoPath, ref, newValue, oldValue string) error {
+	if IsDryRun(ctx) {
+		fmt.Printf("[DRY RUN] Would update ref: git update-ref %s %s %s (in %s)\n", ref, newValue, oldValue, repoPath)
+		return nil
+	}
+	return runGitCombined(ctx, repoPath, "update-ref", "-m", "wipctl rolling checkpoint", ref, newValue, oldValue)
+}
+
+// DefaultBranch detects a repository's default branch the way clean_branches
+// does: <remote>/HEAD, then <remote>/main or <remote>/master, falling back to
+// the checked out branch
+func DefaultBranch(ctx context.Context, repoPath, remote string) string {
+	if ref, err := runGitOutput(ctx, repoPath, "symbolic-ref", "-q", "refs/remotes/"+remote+"/HEAD"); err == nil && ref != "" {
+		return strings.TrimPrefix(ref, "refs/remotes/"+remote+"/")
+	}
+	for _, candidate := range []string{"main", "master"} {
+		if ResolveRef(ctx, repoPath, "refs/remotes/"+remote+"/"+candidate) != "" {
+			return candidate
+		}
+	}
+	branch, _ := getCurrentBranch(ctx, repoPath)
+	return branch
+}
+
+// MergeFastForward fast-forwards the checked out branch to rev
+func MergeFastForward(ctx context.Context, repoPath, rev string) error {
+	if IsDryRun(ctx) {
+		fmt.Printf("[DRY RUN] Would fast-forward: git merge --ff-only %s (in %s)\n", rev, repoPath)
+		return nil
+	}
+	return runGitCombined(ctx, repoPath, "merge", "--ff-only", "-q", rev)
+}
+
+// FastForwardBranch moves a branch that is not checked out to newValue,
+// failing if it no longer points at oldValue
+func FastForwardBranch(ctx context.Context, repoPath, branch, newValue, oldValue string) error {
+	if IsDryRun(ctx) {
+		fmt.Printf("[DRY RUN] Would fast-forward branch: git update-ref refs/heads/%s %s %s (in %s)\n", branch, newValue, oldValue, repoPath)
+		return nil
+	}
+	return runGitCombined(ctx, repoPath, "update-ref", "-m", "wipctl sync: fast-forward", "refs/heads/"+branch, newValue, oldValue)
+}
+
+// MergeBase returns the best common ancestor of two commits
+func MergeBase(ctx context.Context, repoPath, a, b string) (string, error) {
+	return runGitOutput(ctx, repoPath, "merge-base", a, b)
+}
+
+// DiffNames lists the paths that differ between two commits, renames
+// counting as a deletion and an addition
+func DiffNames(ctx context.Context, repoPath, from, to string) ([]string, error) {
+	out, err := runGitOutput(ctx, repoPath, "diff", "--name-only", "--no-renames", "-z", from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	for _, path := range strings.Split(out, "\x00") {
+		if path != "" {
+			paths = append(paths, path)
+		}
+	}
+	return paths, nil
+}
+
+// DiffNameStatus lists the changes between two commits, limited to paths when given
+func DiffNameStatus(ctx context.Context, repoPath, from, to string, paths ...string) (string, error) {
+	return runGitOutput(ctx, repoPath, rangeDiffArgs([]string{"diff", "--name-status", "--find-renames"}, from, to, paths)...)
+}
+
+// DiffStat summarizes the changes between two commits, limited to paths when given
+func DiffStat(ctx context.Context, repoPath, from, to string, paths ...string) (string, error) {
+	return runGitOutput(ctx, repoPath, rangeDiffArgs([]string{"diff", "--stat"}, from, to, paths)...)
+}
+
+// Diff returns the changes between two commits as a unified diff, limited to paths when given
+func Diff(ctx context.Context, repoPath, from, to string, paths ...string) (string, error) {
+	return runGitOutput(ctx, repoPath, rangeDiffArgs([]string{"diff", "--no-color", "--no-ext-diff", "--find-renames"}, from, to, paths)...)
+}
+
+// DiffLineCounts returns the lines added and removed and the files changed
+// between two commits
+func DiffLineCounts(ctx context.Context, repoPath, from, to string) (linesAdded, linesRemoved, filesChanged int, err error) {
+	out, err := runGitOutput(ctx, repoPath, "diff", "--numstat", from, to)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	linesAdded, linesRemoved, filesChanged = parseNumstat(out)
+	return linesAdded, linesRemoved, filesChanged, nil
+}
+
+func rangeDiffArgs(args []string, from, to string, paths []string) []string {
+	args = append(args, from, to)
+	if len(paths) > 0 {
+		args = append(append(args, "--"), paths...)
+	}
+	return args
+}
+
+// LogSubjectsRange returns the subjects of the commits in from..to, oldest
+// first, leaving out merges
+func LogSubjectsRange(ctx context.Context, repoPath, from, to string) ([]string, error) {
+	out, err := runGitOutput(ctx, repoPath, "log", "--reverse", "--no-merges", "--pretty=format:%s", from+".."+to)
+	if err != nil {
+		return nil, err
+	}
+
+	var subjects []string
+	for _, line := range strings.Split(out, "\n") {
+		if line != "" {
+			subjects = append(subjects, line)
+		}
+	}
+	return subjects, nil
+}
+
+// OverlayTree writes the tree of base with paths taken from source, a path
+// missing in source being removed. It works in a temporary index, so the
+// repository's index and worktree are left alone.
+func OverlayTree(ctx context.Context, repoPath, base, source string, paths []string) (string, error) {
+	if IsDryRun(ctx) {
+		fmt.Printf("[DRY RUN] Would write tree: %s with %d paths from %s (in %s)\n", base, len(paths), source, repoPath)
+		return "", nil
+	}
+
+	dir, err := os.MkdirTemp("", "wipctl-index-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(dir)
+	env := append(os.Environ(), "GIT_INDEX_FILE="+filepath.Join(dir, "index"))
+
+	gitWithIndex := func(stdin string, args ...string) (string, error) {
+		cmd := exec.CommandContext(ctx, "git", args...)
+		cmd.Dir = repoPath
+		cmd.Env = env
+		cmd.Stdin = strings.NewReader(stdin)
+		out, err := cmd.Output()
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			err = fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return strings.TrimSpace(string(out)), err
+	}
+
+	if _, err := gitWithIndex("", "read-tree", base); err != nil {
+		return "", err
+	}
+
+	entries, err := runGitOutput(ctx, repoPath, "ls-tree", "-r", "-z", "--full-tree", source)
+	if err != nil {
+		return "", fmt.Errorf("git ls-tree: %w", err)
+	}
+	inSource := make(map[string]string)
+	for _, entry := range strings.Split(entries, "\x00") {
+		if info, path, ok := strings.Cut(entry, "\t"); ok {
+			inSource[path] = info
+		}
+	}
+
+	// Removals first, so a file that became a directory (or back) is replaced cleanly
+	var sb strings.Builder
+	for _, path := range paths {
+		sb.WriteString("0 0000000000000000000000000000000000000000\t" + path + "\x00")
+	}
+	for _, path := range paths {
+		if info, ok := inSource[path]; ok {
+			mode, rest, _ := strings.Cut(info, " ")
+			_, object, _ := strings.Cut(rest, " ")
+			sb.WriteString(mode + " " + object + "\t" + path + "\x00")
+		}
+	}
+	if _, err := gitWithIndex(sb.String(), "update-index", "-z", "--index-info"); err != nil {
+		return "", err
+	}
+
+	return gitWithIndex("", "write-tree")
+}
+
+// CreateBranch creates branch at commit, failing if it already exists
+func CreateBranch(ctx context.Context, repoPath, branch, commit string) error {
+	if IsDryRun(ctx) {
+		fmt.Printf("[DRY RUN] Would create branch: git branch %s %s (in %s)\n", branch, commit, repoPath)
+		return nil
+	}
+	return runGitCombined(ctx, repoPath, "branch", branch, commit)
+}
+
+// DeleteRef removes ref, failing if it no longer points at oldValue
+func DeleteRef(ctx context.Context, repoPath, ref, oldValue string) error {
+	if IsDryRun(ctx) {
+		fmt.Printf("[DRY RUN] Would delete ref: git update-ref -d %s %s (in %s)\n", ref, oldValue, repoPath)
+		return nil
+	}
+	return runGitCombined(ctx, repoPath, "update-ref", "-d", ref, oldValue)
+}
+
+// ConfigValue returns a repository config value, or "" when it is unset
+func ConfigValue(ctx context.Context, repoPath, key string) string {
+	out, err := runGitOutput(ctx, repoPath, "config", "--local", "--get", key)
+	if err != nil {
+		return ""
+	}
+	return out
+}
+
+// SetConfig sets a repository config value; an empty value unsets it
+func SetConfig(ctx context.Context, repoPath, key, value string) error {
+	if IsDryRun(ctx) {
+		fmt.Printf("[DRY RUN] Would set config: git config --local %s %q (in %s)\n", key, value, repoPath)
+		return nil
+	}
+	if value == "" {
+		err := runGitCombined(ctx, repoPath, "config", "--local", "--unset", key)
+		if ConfigValue(ctx, repoPath, key) == "" {
+			return nil
+		}
+		return err
+	}
+	return runGitCombined(ctx, repoPath, "config", "--local", key, value)
+}
+
 func GetLastCommitHash(ctx context.Context, repoPath string) (string, error) {
 	output, err := runGitOutput(ctx, repoPath, "rev-parse", "HEAD")
 	if err != nil {
@@ -0,0 +1,170 @@
+package gitexec
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func git(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GIT_CONFIG_GLOBAL="+os.DevNull,
+		"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+		"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+}
+
+func TestWorktreeSignatureSkipsRenameSource(t *testing.T) {
+	dir := t.TempDir()
+	git(t, dir, "init", "-q")
+	for _, name := range []string{"abcdef", "def"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	git(t, dir, "add", ".")
+	git(t, dir, "commit", "-q", "-m", "init")
+	git(t, dir, "mv", "abcdef", "moved")
+
+	ctx := context.Background()
+	before, err := WorktreeSignature(ctx, dir)
+	if err != nil {
+		t.Fatalf("WorktreeSignature: %v", err)
+	}
+
+	// "def" is clean; it only matches the rename source "abcdef" cut after
+	// three bytes, as if the source field were a status entry
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(filepath.Join(dir, "def"), later, later); err != nil {
+		t.Fatal(err)
+	}
+	after, err := WorktreeSignature(ctx, dir)
+	if err != nil {
+		t.Fatalf("WorktreeSignature: %v", err)
+	}
+	if before != after {
+		t.Error("touching a clean file changed the signature")
+	}
+
+	if err := os.Chtimes(filepath.Join(dir, "moved"), later, later); err != nil {
+		t.Fatal(err)
+	}
+	if renamed, _ := WorktreeSignature(ctx, dir); renamed == after {
+		t.Error("touching the renamed file did not change the signature")
+	}
+}
+
+// writeFiles writes each path under dir with its content. Paths with empty
+// content are removed first, so a file can replace a directory.
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		if content == "" {
+			path := filepath.Join(dir, filepath.FromSlash(name))
+			if err := os.RemoveAll(path); err != nil {
+				t.Fatal(err)
+			}
+			os.Remove(filepath.Dir(path)) // only if now empty
+		}
+	}
+	for name, content := range files {
+		if content == "" {
+			continue
+		}
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestOverlayTree(t *testing.T) {
+	dir := t.TempDir()
+	git(t, dir, "init", "-q", "-b", "main")
+	writeFiles(t, dir, map[string]string{
+		"keep.txt":   "keep\n",
+		"src/old.go": "package src\n",
+		"lib":        "lib was a file\n",
+		"docs/a.md":  "docs was a directory\n",
+		"run.sh":     "echo hi\n",
+	})
+	git(t, dir, "add", "-A")
+	git(t, dir, "commit", "-q", "-m", "base")
+	git(t, dir, "branch", "base")
+
+	// A rename across directories, a file and a directory swapping places
+	// and a mode change
+	writeFiles(t, dir, map[string]string{
+		"src/old.go":  "",
+		"pkg/new.go":  "package src\n",
+		"lib":         "",
+		"lib/util.go": "package lib\n",
+		"docs/a.md":   "",
+		"docs":        "docs is a file\n",
+		"keep.txt":    "keep, edited\n",
+	})
+	git(t, dir, "add", "-A")
+	git(t, dir, "update-index", "--chmod=+x", "run.sh")
+	git(t, dir, "commit", "-q", "-m", "tip")
+
+	ctx := context.Background()
+	want, err := TreeOf(ctx, dir, "main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	paths, err := DiffNames(ctx, dir, "base", "main")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// One group per top-level entry, applied in both orders
+	groups := map[string][]string{}
+	var order []string
+	for _, path := range paths {
+		top, _, _ := strings.Cut(path, "/")
+		if groups[top] == nil {
+			order = append(order, top)
+		}
+		groups[top] = append(groups[top], path)
+	}
+	for _, reverse := range []bool{false, true} {
+		tree, _ := TreeOf(ctx, dir, "base")
+		for i := range order {
+			top := order[i]
+			if reverse {
+				top = order[len(order)-1-i]
+			}
+			if tree, err = OverlayTree(ctx, dir, tree, "main", groups[top]); err != nil {
+				t.Fatalf("overlay %s (reverse %v): %v", top, reverse, err)
+			}
+		}
+		if tree != want {
+			t.Errorf("overlaid tree (reverse %v) = %s, want the tip's %s", reverse, tree, want)
+		}
+	}
+
+	// Paths outside the group keep the base's version
+	tree, err := OverlayTree(ctx, dir, "base", "main", []string{"keep.txt"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	names, _ := DiffNames(ctx, dir, "base", tree)
+	if strings.Join(names, ",") != "keep.txt" {
+		t.Errorf("overlay of keep.txt changed %v", names)
+	}
+
+	// The repository's own index is left alone
+	git(t, dir, "diff", "--cached", "--quiet")
+}
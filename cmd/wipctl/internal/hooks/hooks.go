@@ -0,0 +1,164 @@
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/config"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/gitexec"
+)
+
+const defaultTimeout = 5 * time.Minute
+
+// Hook names for per-repository hooks; operation hooks are "pre-<op>"/"post-<op>"
+const (
+	PreRepo  = "pre-repo"
+	PostRepo = "post-repo"
+)
+
+// Event is the payload passed to hooks as env vars and JSON on stdin
+type Event struct {
+	Hook      string `json:"hook"`
+	Operation string `json:"operation"`
+	Workspace string `json:"workspace"`
+	Repo      string `json:"repo,omitempty"`
+	RepoPath  string `json:"repo_path,omitempty"`
+	Branch    string `json:"branch,omitempty"`
+	WipBranch string `json:"wip_branch,omitempty"`
+	Outcome   string `json:"outcome,omitempty"`
+	DryRun    bool   `json:"dry_run"`
+}
+
+// Result captures a single hook command execution
+type Result struct {
+	Hook    string
+	Command string
+	Output  string
+	Err     error
+}
+
+// Runner executes configured hooks
+type Runner struct {
+	hooks map[string][]config.Hook
+}
+
+// NewRunner creates a hook runner from the configured hook table
+func NewRunner(hooks map[string][]config.Hook) *Runner {
+	return &Runner{hooks: hooks}
+}
+
+// Pre returns the hook name that runs before an operation
+func Pre(op string) string {
+	return "pre-" + op
+}
+
+// Post returns the hook name that runs after an operation
+func Post(op string) string {
+	return "post-" + op
+}
+
+// Has reports whether any hook is configured for the name and operation
+func (r *Runner) Has(name, op string) bool {
+	if r == nil {
+		return false
+	}
+	for _, hook := range r.hooks[name] {
+		if hook.AppliesTo(op) {
+			return true
+		}
+	}
+	return false
+}
+
+// Run executes every hook registered under name, in order, stopping at the
+// first failure. Hooks run in the repo directory when the event names one,
+// otherwise in the workspace directory.
+func (r *Runner) Run(ctx context.Context, name string, event Event) ([]Result, error) {
+	if r == nil {
+		return nil, nil
+	}
+
+	event.Hook = name
+	event.DryRun = gitexec.IsDryRun(ctx)
+
+	var results []Result
+	for _, hook := range r.hooks[name] {
+		if !hook.AppliesTo(event.Operation) || strings.TrimSpace(hook.Run) == "" {
+			continue
+		}
+
+		result := r.runOne(ctx, hook, event)
+		results = append(results, result)
+		if result.Err != nil {
+			return results, fmt.Errorf("%s hook %q failed: %w", name, hook.Run, result.Err)
+		}
+	}
+
+	return results, nil
+}
+
+func (r *Runner) runOne(ctx context.Context, hook config.Hook, event Event) Result {
+	result := Result{Hook: event.Hook, Command: hook.Run}
+
+	if event.DryRun {
+		fmt.Printf("[DRY RUN] Would run %s hook: %s (in %s)\n", event.Hook, hook.Run, hookDir(event))
+		return result
+	}
+
+	timeout := defaultTimeout
+	if hook.Timeout != "" {
+		if parsed, err := time.ParseDuration(hook.Timeout); err == nil {
+			timeout = parsed
+		}
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		result.Err = fmt.Errorf("marshal hook event: %w", err)
+		return result
+	}
+
+	var output bytes.Buffer
+	cmd := exec.CommandContext(ctx, "sh", "-c", hook.Run)
+	cmd.Dir = hookDir(event)
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	// Children of a timed-out hook may hold the output open; do not wait for them
+	cmd.WaitDelay = time.Second
+	cmd.Env = append(os.Environ(),
+		"WIPCTL_HOOK="+event.Hook,
+		"WIPCTL_OP="+event.Operation,
+		"WIPCTL_WORKSPACE="+event.Workspace,
+		"WIPCTL_REPO="+event.Repo,
+		"WIPCTL_REPO_PATH="+event.RepoPath,
+		"WIPCTL_BRANCH="+event.Branch,
+		"WIPCTL_WIP_BRANCH="+event.WipBranch,
+		"WIPCTL_OUTCOME="+event.Outcome,
+	)
+
+	slog.Debug("Running hook", "hook", event.Hook, "command", hook.Run, "dir", cmd.Dir)
+	result.Err = cmd.Run()
+	result.Output = strings.TrimSpace(output.String())
+	if ctx.Err() == context.DeadlineExceeded {
+		result.Err = fmt.Errorf("timed out after %s", timeout)
+	}
+
+	return result
+}
+
+func hookDir(event Event) string {
+	if event.RepoPath != "" {
+		return event.RepoPath
+	}
+	return event.Workspace
+}
@@ -0,0 +1,116 @@
+package hooks
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/config"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/gitexec"
+)
+
+func TestRunPassesEventAsEnvAndStdin(t *testing.T) {
+	dir := t.TempDir()
+	runner := NewRunner(map[string][]config.Hook{
+		PostRepo: {{Run: `printf '%s|%s|%s|%s' "$WIPCTL_HOOK" "$WIPCTL_OP" "$WIPCTL_WIP_BRANCH" "$WIPCTL_OUTCOME" > env.txt; cat > event.json; pwd > pwd.txt`}},
+	})
+
+	event := Event{Operation: "push", Workspace: "/ws", Repo: "api", RepoPath: dir, Branch: "main", WipBranch: "wip/laptop/x", Outcome: "success"}
+	results, err := runner.Run(context.Background(), PostRepo, event)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("Run returned %d results, want 1", len(results))
+	}
+
+	env, _ := os.ReadFile(filepath.Join(dir, "env.txt"))
+	if want := "post-repo|push|wip/laptop/x|success"; string(env) != want {
+		t.Errorf("hook env = %q, want %q", env, want)
+	}
+
+	var got Event
+	data, _ := os.ReadFile(filepath.Join(dir, "event.json"))
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("stdin is not an event: %v (%q)", err, data)
+	}
+	event.Hook = PostRepo
+	if got != event {
+		t.Errorf("stdin event = %+v, want %+v", got, event)
+	}
+
+	pwd, _ := os.ReadFile(filepath.Join(dir, "pwd.txt"))
+	if resolved, _ := filepath.EvalSymlinks(dir); strings.TrimSpace(string(pwd)) != resolved && strings.TrimSpace(string(pwd)) != dir {
+		t.Errorf("hook ran in %q, want the repo directory %q", pwd, dir)
+	}
+}
+
+func TestRunStopsAtFirstFailure(t *testing.T) {
+	dir := t.TempDir()
+	runner := NewRunner(map[string][]config.Hook{
+		Pre("push"): {
+			{Run: "echo first >> ran.txt"},
+			{Run: "echo broken; exit 3"},
+			{Run: "echo third >> ran.txt"},
+		},
+	})
+
+	results, err := runner.Run(context.Background(), Pre("push"), Event{Operation: "push", Workspace: dir})
+	if err == nil || !strings.Contains(err.Error(), "exit status 3") {
+		t.Fatalf("Run error = %v, want the failing hook's exit status", err)
+	}
+	if len(results) != 2 || results[1].Output != "broken" {
+		t.Errorf("results = %+v, want the first two hooks with the failure's output", results)
+	}
+	if ran, _ := os.ReadFile(filepath.Join(dir, "ran.txt")); string(ran) != "first\n" {
+		t.Errorf("hooks that ran = %q, want only the first", ran)
+	}
+}
+
+func TestRunFiltersOpsAndHonorsDryRun(t *testing.T) {
+	dir := t.TempDir()
+	runner := NewRunner(map[string][]config.Hook{
+		PreRepo: {
+			{Run: "touch checkpoint-only", Ops: []string{"checkpoint"}},
+			{Run: "touch every-op"},
+		},
+	})
+
+	if _, err := runner.Run(context.Background(), PreRepo, Event{Operation: "push", RepoPath: dir}); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "checkpoint-only")); err == nil {
+		t.Error("hook limited to checkpoint ran for push")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "every-op")); err != nil {
+		t.Error("unrestricted hook did not run")
+	}
+
+	os.Remove(filepath.Join(dir, "every-op"))
+	ctx := context.WithValue(context.Background(), gitexec.DryRunKey, true)
+	if _, err := runner.Run(ctx, PreRepo, Event{Operation: "push", RepoPath: dir}); err != nil {
+		t.Fatalf("Run in dry run: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "every-op")); err == nil {
+		t.Error("hook ran in dry run")
+	}
+}
+
+func TestRunTimesOutSlowHook(t *testing.T) {
+	runner := NewRunner(map[string][]config.Hook{
+		PreRepo: {{Run: "sleep 5", Timeout: "200ms"}},
+	})
+
+	started := time.Now()
+	results, err := runner.Run(context.Background(), PreRepo, Event{Operation: "push", RepoPath: t.TempDir()})
+	if err == nil || len(results) != 1 || !strings.Contains(results[0].Err.Error(), "timed out") {
+		t.Fatalf("Run = %+v, %v, want a timed-out hook", results, err)
+	}
+	if elapsed := time.Since(started); elapsed > 3*time.Second {
+		t.Errorf("Run returned after %s, want it shortly after the 200ms timeout", elapsed)
+	}
+}
@@ -0,0 +1,132 @@
+// Package msgedit formats a batch of proposed commit messages as one
+// editable file, in the spirit of a rebase todo list, and parses it back.
+package msgedit
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// FileName is the edit file written to the report directory
+const FileName = "WIP_EDITMSG"
+
+// ErrAborted is returned by Parse when every repo block was deleted
+var ErrAborted = errors.New("message file is empty")
+
+// A repository block starts with a marker line "# --- repo <key> ---". It is
+// shaped like a comment so an ordinary message line, such as "repo layout
+// changed", can never be mistaken for one.
+const (
+	markerPrefix = "# --- repo "
+	markerSuffix = " ---"
+)
+
+// Item is one repository's proposed commit
+type Item struct {
+	Key      string // unique repository id written on the marker line
+	Branch   string
+	DiffStat string
+	Message  string
+}
+
+// Format renders items as an edit file. Each block is a marker line, the
+// branch and diffstat as comments, then the proposed message.
+func Format(title string, items []Item) string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("# %s: %d repositories\n", title, len(items)))
+	sb.WriteString("#\n")
+	sb.WriteString("# Edit the commit message below each \"# --- repo\" line.\n")
+	sb.WriteString("# Delete a repo block, or empty its message, to skip that repository.\n")
+	sb.WriteString("# These '#' lines and the ones right under each repo line are ignored;\n")
+	sb.WriteString("# '#' lines inside a message, such as Markdown headings, are kept.\n")
+	sb.WriteString("# Save and quit to continue; delete everything to abort.\n")
+
+	for _, item := range items {
+		sb.WriteString("\n")
+		sb.WriteString(markerPrefix + item.Key + markerSuffix + "\n")
+		if item.Branch != "" {
+			sb.WriteString("# branch: " + item.Branch + "\n")
+		}
+		for _, line := range strings.Split(strings.TrimRight(item.DiffStat, "\n"), "\n") {
+			if strings.TrimSpace(line) != "" {
+				sb.WriteString("#  " + line + "\n")
+			}
+		}
+		sb.WriteString(strings.TrimSpace(item.Message) + "\n")
+	}
+
+	return sb.String()
+}
+
+// Parse reads an edited file back into key → message. Repositories whose
+// block was removed or whose message is empty are left out; a file with no
+// blocks left is ErrAborted. Keys not in known, duplicate blocks and text
+// outside a block are errors.
+//
+// Only comments where Format writes them are dropped: the header before the
+// first block and the run of '#' lines directly under a marker. Any other
+// line, '#' or not, belongs to the message.
+func Parse(content string, known []string) (map[string]string, error) {
+	valid := make(map[string]bool, len(known))
+	for _, key := range known {
+		valid[key] = true
+	}
+
+	messages := make(map[string]string)
+	seen := make(map[string]bool)
+	var current string
+	var lines []string
+
+	flush := func() {
+		if current == "" {
+			return
+		}
+		if message := strings.TrimSpace(strings.Join(lines, "\n")); message != "" {
+			messages[current] = message
+		}
+	}
+
+	for i, line := range strings.Split(content, "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		if key, ok := parseMarker(line); ok {
+			flush()
+			if !valid[key] {
+				return nil, fmt.Errorf("line %d: unknown repository %q", i+1, key)
+			}
+			if seen[key] {
+				return nil, fmt.Errorf("line %d: repository %q listed twice", i+1, key)
+			}
+			seen[key] = true
+			current, lines = key, nil
+			continue
+		}
+		if current == "" {
+			if strings.TrimSpace(line) != "" && !strings.HasPrefix(line, "#") {
+				return nil, fmt.Errorf("line %d: text outside a repo block", i+1)
+			}
+			continue
+		}
+		if len(lines) == 0 && strings.HasPrefix(line, "#") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	flush()
+
+	if len(seen) == 0 {
+		return nil, ErrAborted
+	}
+	return messages, nil
+}
+
+// parseMarker returns the key of a "# --- repo <key> ---" line
+func parseMarker(line string) (string, bool) {
+	if len(line) < len(markerPrefix)+len(markerSuffix) ||
+		!strings.HasPrefix(line, markerPrefix) || !strings.HasSuffix(line, markerSuffix) {
+		return "", false
+	}
+	key := strings.TrimSpace(line[len(markerPrefix) : len(line)-len(markerSuffix)])
+	return key, key != ""
+}
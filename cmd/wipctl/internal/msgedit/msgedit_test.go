@@ -0,0 +1,132 @@
+package msgedit
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestFormatParseRoundTrip(t *testing.T) {
+	items := []Item{
+		{Key: "api", Branch: "main", DiffStat: " main.go | 2 +-\n 1 file changed", Message: "feat(api): add login\n\n# Why\n\nWith a body."},
+		{Key: "web", Branch: "dev", Message: "fix: typo"},
+	}
+
+	content := Format("Push", items)
+	got, err := Parse(content, []string{"api", "web"})
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	for _, item := range items {
+		if got[item.Key] != item.Message {
+			t.Errorf("message for %s = %q, want %q", item.Key, got[item.Key], item.Message)
+		}
+	}
+}
+
+func TestParseAbortedIsSentinel(t *testing.T) {
+	if _, err := Parse("  \n", []string{"api"}); !errors.Is(err, ErrAborted) {
+		t.Errorf("Parse of an emptied file error = %v, want ErrAborted", err)
+	}
+}
+
+func TestParse(t *testing.T) {
+	known := []string{"api", "web"}
+
+	tests := []struct {
+		name    string
+		content string
+		want    map[string]string
+		wantErr string
+	}{
+		{
+			name:    "comments ignored",
+			content: "# header\n# --- repo api ---\n# branch: main\nfix: one\n",
+			want:    map[string]string{"api": "fix: one"},
+		},
+		{
+			name:    "empty message skips repo",
+			content: "# --- repo api ---\n\n# --- repo web ---\nfix: two\n",
+			want:    map[string]string{"web": "fix: two"},
+		},
+		{
+			name:    "removed block skips repo",
+			content: "# --- repo web ---\nfix: two\n",
+			want:    map[string]string{"web": "fix: two"},
+		},
+		{
+			name:    "trailing whitespace trimmed",
+			content: "# --- repo api ---\nfix: one  \n\nbody\t\n",
+			want:    map[string]string{"api": "fix: one\n\nbody"},
+		},
+		{
+			name:    "markdown heading in body kept",
+			content: "# --- repo api ---\n# branch: main\nfeat: login\n\n## Notes\n\n# Details\n",
+			want:    map[string]string{"api": "feat: login\n\n## Notes\n\n# Details"},
+		},
+		{
+			name:    "repo sentence is not a block",
+			content: "# --- repo api ---\nrefactor: split packages\n\nrepo layout changed\nrepo web moved too\n",
+			want:    map[string]string{"api": "refactor: split packages\n\nrepo layout changed\nrepo web moved too"},
+		},
+		{
+			name:    "old repo line is text outside a block",
+			content: "repo api\nfix: one\n",
+			wantErr: "outside a repo block",
+		},
+		{
+			name:    "marker without a key",
+			content: "# --- repo ---\nfix: one\n",
+			wantErr: "outside a repo block",
+		},
+		{
+			name:    "empty file aborts",
+			content: "",
+			wantErr: ErrAborted.Error(),
+		},
+		{
+			name:    "only comments left aborts",
+			content: "# wipctl push: 2 repositories\n#\n\n",
+			wantErr: ErrAborted.Error(),
+		},
+		{
+			name:    "unknown repository",
+			content: "# --- repo nope ---\nfix: one\n",
+			wantErr: "unknown repository",
+		},
+		{
+			name:    "duplicate block",
+			content: "# --- repo api ---\nfix: one\n# --- repo api ---\nfix: two\n",
+			wantErr: "listed twice",
+		},
+		{
+			name:    "text outside a block",
+			content: "stray text\n# --- repo api ---\nfix: one\n",
+			wantErr: "outside a repo block",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.content, known)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Parse error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Parse = %v, want %v", got, tt.want)
+			}
+			for key, message := range tt.want {
+				if got[key] != message {
+					t.Errorf("message for %s = %q, want %q", key, got[key], message)
+				}
+			}
+		})
+	}
+}
@@ -2,7 +2,10 @@ package operations
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
+	"os/signal"
 
 	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/ai"
 	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/gitexec"
@@ -17,6 +20,7 @@ type StatusHandler struct {
 	withAI      bool
 	aiIntegration *ai.Integration
 	collector   *status.Collector
+	pending     map[string]int
 }
 
 // NewStatusHandler creates a new status operation handler
@@ -33,6 +37,11 @@ func NewStatusHandler(withAI bool, concurrency int) *StatusHandler {
 	}
 }
 
+// SetPendingPushes sets the number of offline-queued pushes per repository name
+func (h *StatusHandler) SetPendingPushes(pending map[string]int) {
+	h.pending = pending
+}
+
 // ProcessRepo implements RepoHandler interface for status display
 func (h *StatusHandler) ProcessRepo(ctx context.Context, repo workspace.Repo) report.ReportEntry {
 	// Status operation doesn't use individual repo processing
@@ -61,14 +70,16 @@ func (h *StatusHandler) ProcessWorkspaceStatus(ctx context.Context, repos []work
 
 // displayStatusTable renders the repository status table
 func (h *StatusHandler) displayStatusTable(results map[string]*gitexec.RepoStatus) {
-	ui.InitTable("Repository", "Branch", "Status", "Files", "Lines", "Commits", "Ahead", "Behind", "Size")
+	ui.InitTable("Repository", "Branch", "Status", "Files", "Lines", "Commits", "Ahead", "Behind", "Pending", "Size")
 
 	for repoName, status := range results {
 		if status.Error != "" {
 			ui.AddTableRow(
 				ui.CyberText(repoName, "repo"),
 				ui.StatusCell("error"),
-				"-", "-", "-", "-", "-", "-", "-",
+				"-", "-", "-", "-", "-", "-",
+				ui.SynthwaveNumber(h.pending[repoName], "pending"),
+				"-",
 			)
 			ui.Error(repoName + ": " + status.Error)
 			continue
@@ -79,7 +90,9 @@ func (h *StatusHandler) displayStatusTable(results map[string]*gitexec.RepoStatu
 				ui.CyberText(repoName, "repo"),
 				ui.CyberText(status.Branch, "branch"),
 				ui.StatusCell("no-origin"),
-				"-", "-", "-", "-", "-", "-",
+				"-", "-", "-", "-", "-",
+				ui.SynthwaveNumber(h.pending[repoName], "pending"),
+				"-",
 			)
 			continue
 		}
@@ -89,7 +102,9 @@ func (h *StatusHandler) displayStatusTable(results map[string]*gitexec.RepoStatu
 				ui.CyberText(repoName, "repo"),
 				ui.CyberText(status.Branch, "branch"),
 				ui.StatusCell("in-progress"),
-				"-", "-", "-", "-", "-", "-",
+				"-", "-", "-", "-", "-",
+				ui.SynthwaveNumber(h.pending[repoName], "pending"),
+				"-",
 			)
 			continue
 		}
@@ -109,27 +124,43 @@ func (h *StatusHandler) displayStatusTable(results map[string]*gitexec.RepoStatu
 			ui.SynthwaveNumber(status.Commits, "commits"),
 			ui.SynthwaveNumber(status.Ahead, "ahead"),
 			ui.SynthwaveNumber(status.Behind, "behind"),
+			ui.SynthwaveNumber(h.pending[repoName], "pending"),
 			ui.CyberText(status.RepoSize, "size"),
 		)
 	}
 
 	ui.RenderTable()
+
+	queued := 0
+	for _, n := range h.pending {
+		queued += n
+	}
+	if queued > 0 {
+		ui.Warning(fmt.Sprintf("%d pushes queued while offline - run 'wipctl flush' to retry", queued))
+	}
 	ui.Info("System operational - All repositories scanned")
 }
 
-// displayAISynopsis generates and displays AI-powered synopsis
+// displayAISynopsis generates and displays AI-powered synopsis, printing it
+// as it streams in; Ctrl-C stops the synopsis without killing the status output
 func (h *StatusHandler) displayAISynopsis(ctx context.Context, results map[string]*gitexec.RepoStatus) {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+
 	ui.Info("🤖 Generating AI workspace synopsis...")
 
-	synopsis, err := h.aiIntegration.GenerateSynopsis(ctx, results)
-	if err != nil {
+	printer := ui.NewStreamPrinter(func() {
+		ui.Success("🧠 AI Workspace Intelligence")
+		ui.Info("─────────────────────────────")
+	})
+	_, err := h.aiIntegration.StreamSynopsis(ctx, results, printer.Write)
+	printer.Finish()
+
+	if errors.Is(err, context.Canceled) {
+		ui.Warning("AI synopsis cancelled")
+	} else if err != nil && !errors.Is(err, ai.ErrPromptShown) {
 		ui.Warning("AI synopsis failed: " + err.Error())
-		return
 	}
-
-	ui.Success("🧠 AI Workspace Intelligence")
-	ui.Info("─────────────────────────────")
-	println(synopsis)
 }
 
 // formatLineChanges formats line addition/removal display
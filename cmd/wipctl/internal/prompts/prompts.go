@@ -0,0 +1,192 @@
+// Package prompts holds the text/template prompts sent to AI providers.
+// Defaults are embedded in the binary; a file with the same name in a
+// workspace or user prompt directory replaces one, so teams can enforce
+// their own commit style.
+package prompts
+
+import (
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+//go:embed defaults/*.tmpl
+var defaults embed.FS
+
+// DirName is the prompt directory inside the report and user config directories
+const DirName = "prompts"
+
+// BuiltIn is the Source of a prompt that comes from the binary
+const BuiltIn = "built-in"
+
+// Kinds lists the prompts, one per kind of AI request
+var Kinds = []string{"commit", "synopsis", "pr-review", "pr-description", "workspace-context", "merge"}
+
+var versionRegex = regexp.MustCompile(`^\{\{/\*\s*version:\s*(\d+)`)
+
+// funcs are available to every template in addition to the text/template builtins
+var funcs = template.FuncMap{
+	"join": func(items []string, sep string) string { return strings.Join(items, sep) },
+	"first": func(n int, items []string) []string {
+		if len(items) > n {
+			return items[:n]
+		}
+		return items
+	},
+	"trim":  strings.TrimSpace,
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+}
+
+// Prompt is one template file defining a "system" and a "user" template
+type Prompt struct {
+	Kind    string
+	Version int    // from the leading {{/* version: N */}} comment; 0 if missing
+	Source  string // file the prompt was read from, or BuiltIn
+	Text    string
+	tmpl    *template.Template
+}
+
+// Key identifies the prompt text for cache keys: its version and a hash of
+// the text, so edited overrides do not match responses to the old prompt
+func (p *Prompt) Key() string {
+	sum := sha256.Sum256([]byte(p.Text))
+	return fmt.Sprintf("v%d-%s", p.Version, hex.EncodeToString(sum[:4]))
+}
+
+// Render executes the system and user templates with data, one of the ai
+// package's input structs
+func (p *Prompt) Render(data interface{}) (string, string, error) {
+	var system, user strings.Builder
+	if err := p.tmpl.ExecuteTemplate(&system, "system", data); err != nil {
+		return "", "", fmt.Errorf("render %s prompt (%s): %w", p.Kind, p.Source, err)
+	}
+	if err := p.tmpl.ExecuteTemplate(&user, "user", data); err != nil {
+		return "", "", fmt.Errorf("render %s prompt (%s): %w", p.Kind, p.Source, err)
+	}
+	return strings.TrimSpace(system.String()), strings.TrimSpace(user.String()), nil
+}
+
+// Set is the effective prompt of every kind
+type Set struct {
+	prompts map[string]*Prompt
+}
+
+// Load reads each prompt from the first of dirs that has <kind>.tmpl and
+// falls back to the embedded default. An override that does not parse is
+// reported and the default is used instead.
+func Load(dirs ...string) (*Set, []error) {
+	set := &Set{prompts: make(map[string]*Prompt)}
+	var problems []error
+
+	for _, kind := range Kinds {
+		text, err := Default(kind)
+		if err != nil {
+			problems = append(problems, err)
+			continue
+		}
+		builtIn, err := parse(kind, BuiltIn, text)
+		if err != nil {
+			// The embedded defaults are part of the build; this is a bug
+			panic(err)
+		}
+		set.prompts[kind] = builtIn
+
+		for _, dir := range dirs {
+			if dir == "" {
+				continue
+			}
+			path := Path(dir, kind)
+			data, err := os.ReadFile(path)
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				problems = append(problems, fmt.Errorf("read prompt %s: %w", path, err))
+				break
+			}
+			override, err := parse(kind, path, string(data))
+			if err != nil {
+				problems = append(problems, err)
+				break
+			}
+			set.prompts[kind] = override
+			break
+		}
+	}
+	return set, problems
+}
+
+// Check runs check on every override and puts the default back for those
+// that fail it, returning the failures
+func (s *Set) Check(check func(*Prompt) error) []error {
+	var problems []error
+	for kind, prompt := range s.prompts {
+		if prompt.Source == BuiltIn {
+			continue
+		}
+		if err := check(prompt); err != nil {
+			problems = append(problems, err)
+			text, _ := Default(kind)
+			s.prompts[kind], _ = parse(kind, BuiltIn, text)
+		}
+	}
+	return problems
+}
+
+// Get returns the effective prompt of a kind
+func (s *Set) Get(kind string) (*Prompt, bool) {
+	prompt, ok := s.prompts[kind]
+	return prompt, ok
+}
+
+// Default returns the embedded text of a prompt
+func Default(kind string) (string, error) {
+	data, err := defaults.ReadFile("defaults/" + kind + ".tmpl")
+	if err != nil {
+		return "", fmt.Errorf("unknown prompt %q", kind)
+	}
+	return string(data), nil
+}
+
+// Read parses the prompt override of a kind at path
+func Read(kind, path string) (*Prompt, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read prompt %s: %w", path, err)
+	}
+	return parse(kind, path, string(data))
+}
+
+// Path returns where a prompt override lives in dir
+func Path(dir, kind string) string {
+	return filepath.Join(dir, kind+".tmpl")
+}
+
+// parse compiles a prompt, which must define both templates
+func parse(kind, source, text string) (*Prompt, error) {
+	tmpl, err := template.New(kind).Funcs(funcs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("parse prompt %s: %w", source, err)
+	}
+	for _, name := range []string{"system", "user"} {
+		if tmpl.Lookup(name) == nil {
+			return nil, fmt.Errorf("prompt %s does not define {{define %q}}", source, name)
+		}
+	}
+
+	version := 0
+	if match := versionRegex.FindStringSubmatch(text); match != nil {
+		version, _ = strconv.Atoi(match[1])
+	}
+	return &Prompt{Kind: kind, Version: version, Source: source, Text: text, tmpl: tmpl}, nil
+}
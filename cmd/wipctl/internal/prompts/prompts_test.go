@@ -0,0 +1,160 @@
+package prompts
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+// override is a commit prompt whose user template prints body
+func override(version, body string) string {
+	return "{{/* version: " + version + " */}}\n" +
+		`{{define "system"}}team rules{{end}}` + "\n" +
+		`{{define "user"}}` + body + `{{end}}` + "\n"
+}
+
+// writePrompt writes text as the commit prompt override in dir
+func writePrompt(t *testing.T, dir, text string) {
+	t.Helper()
+	if err := os.WriteFile(Path(dir, "commit"), []byte(text), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// render renders the commit prompt of set with a repo name
+func render(t *testing.T, set *Set) (string, string) {
+	t.Helper()
+	prompt, ok := set.Get("commit")
+	if !ok {
+		t.Fatal("no commit prompt")
+	}
+	system, user, err := prompt.Render(struct{ Repo string }{Repo: "api"})
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	return system, user
+}
+
+func TestLoadDefaults(t *testing.T) {
+	set, problems := Load()
+	if len(problems) > 0 {
+		t.Fatalf("problems = %v", problems)
+	}
+	for _, kind := range Kinds {
+		prompt, ok := set.Get(kind)
+		if !ok {
+			t.Errorf("no %s prompt", kind)
+			continue
+		}
+		if prompt.Source != BuiltIn || prompt.Version == 0 {
+			t.Errorf("%s prompt = %s v%d, want a versioned built-in", kind, prompt.Source, prompt.Version)
+		}
+	}
+}
+
+func TestLoadFirstDirectoryWins(t *testing.T) {
+	workspaceDir, userDir, emptyDir := t.TempDir(), t.TempDir(), t.TempDir()
+	writePrompt(t, workspaceDir, override("7", "workspace {{.Repo}}"))
+	writePrompt(t, userDir, override("3", "user {{.Repo}}"))
+
+	set, problems := Load("", emptyDir, workspaceDir, userDir)
+	if len(problems) > 0 {
+		t.Fatalf("problems = %v", problems)
+	}
+	prompt, _ := set.Get("commit")
+	if prompt.Source != Path(workspaceDir, "commit") || prompt.Version != 7 {
+		t.Errorf("commit prompt = %s v%d, want the workspace override v7", prompt.Source, prompt.Version)
+	}
+	if system, user := render(t, set); system != "team rules" || user != "workspace api" {
+		t.Errorf("rendered = %q / %q, want the workspace override", system, user)
+	}
+	if synopsis, _ := set.Get("synopsis"); synopsis.Source != BuiltIn {
+		t.Errorf("synopsis source = %s, want built-in without an override", synopsis.Source)
+	}
+
+	set, _ = Load(userDir, workspaceDir)
+	if _, user := render(t, set); user != "user api" {
+		t.Errorf("rendered user prompt = %q, want the first directory's", user)
+	}
+}
+
+func TestLoadFallsBackToBuiltIn(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		problem string
+	}{
+		{name: "parse failure", text: override("1", "{{.Repo"), problem: "parse prompt"},
+		{name: "missing user", text: `{{define "system"}}team rules{{end}}`, problem: `does not define {{define "user"}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			broken, later := t.TempDir(), t.TempDir()
+			writePrompt(t, broken, tt.text)
+			writePrompt(t, later, override("3", "later {{.Repo}}"))
+
+			set, problems := Load(broken, later)
+			if len(problems) != 1 || !strings.Contains(problems[0].Error(), tt.problem) {
+				t.Fatalf("problems = %v, want one %q", problems, tt.problem)
+			}
+			// A broken override is not skipped over in favor of a later one
+			if prompt, _ := set.Get("commit"); prompt.Source != BuiltIn {
+				t.Errorf("commit source = %s, want built-in", prompt.Source)
+			}
+		})
+	}
+}
+
+func TestCheckRestoresDefault(t *testing.T) {
+	dir := t.TempDir()
+	writePrompt(t, dir, override("2", "{{.Missing}}"))
+
+	set, problems := Load(dir)
+	if len(problems) > 0 {
+		t.Fatalf("problems = %v", problems)
+	}
+	checked := 0
+	problems = set.Check(func(prompt *Prompt) error {
+		checked++
+		_, _, err := prompt.Render(struct{ Repo string }{})
+		return err
+	})
+	if checked != 1 {
+		t.Errorf("checked %d prompts, want only the override", checked)
+	}
+	if len(problems) != 1 || !strings.Contains(problems[0].Error(), "Missing") {
+		t.Fatalf("problems = %v, want the missing field reported", problems)
+	}
+	prompt, _ := set.Get("commit")
+	if prompt.Source != BuiltIn {
+		t.Errorf("commit source = %s, want the default restored", prompt.Source)
+	}
+	builtIn, _ := Default("commit")
+	if prompt.Text != builtIn {
+		t.Error("commit text is not the default")
+	}
+}
+
+func TestKeyChangesWithText(t *testing.T) {
+	dir := t.TempDir()
+	writePrompt(t, dir, override("2", "first {{.Repo}}"))
+	first, err := Read("commit", Path(dir, "commit"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, _ := Read("commit", Path(dir, "commit"))
+	if first.Key() != again.Key() {
+		t.Errorf("keys of the same text differ: %s, %s", first.Key(), again.Key())
+	}
+	if !strings.HasPrefix(first.Key(), "v2-") {
+		t.Errorf("key = %s, want the version prefix", first.Key())
+	}
+
+	// Edited without bumping the version
+	writePrompt(t, dir, override("2", "second {{.Repo}}"))
+	edited, _ := Read("commit", Path(dir, "commit"))
+	if edited.Key() == first.Key() {
+		t.Errorf("key %s unchanged after editing the override", edited.Key())
+	}
+}
@@ -0,0 +1,223 @@
+// Package queue persists WIP pushes that failed because the remote was
+// unreachable, so they can be retried in order once the network is back.
+package queue
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// FileName is the queue file kept in the report directory
+const FileName = "push-queue.json"
+
+// MaxFailures is how many times a push may fail for reasons other than an
+// unreachable remote before it is dropped, so one broken entry cannot hold
+// back its repository's queue forever
+const MaxFailures = 5
+
+// Push modes, mirroring the git invocation that originally failed
+const (
+	ModeUpstream = "upstream" // git push -u <remote> <branch>
+	ModePlain    = "plain"    // git push origin <branch>
+	ModeLease    = "lease"    // git push -u --force-with-lease=<branch>:<lease> <remote> <branch>
+)
+
+// Entry is one queued push
+type Entry struct {
+	Repo      string    `json:"repo"`
+	Path      string    `json:"path"`
+	Remote    string    `json:"remote"`
+	Branch    string    `json:"branch"`
+	Commit    string    `json:"commit,omitempty"`
+	Mode      string    `json:"mode"`
+	Lease     string    `json:"lease,omitempty"`
+	Queued    time.Time `json:"queued"`
+	Attempts  int       `json:"attempts"`
+	Failures  int       `json:"failures,omitempty"`
+	LastError string    `json:"last_error,omitempty"`
+}
+
+// Key identifies the ref an entry pushes; newer entries for the same key
+// replace older ones since the push always sends the current branch tip
+func (e Entry) Key() string {
+	return e.Path + "\x00" + e.Remote + "\x00" + e.Branch
+}
+
+// Queue is the on-disk push queue. It is safe for concurrent use, including
+// by several wipctl processes sharing a report directory: every
+// read-modify-write holds an exclusive lock on a sibling ".lock" file.
+type Queue struct {
+	path  string
+	mu    sync.Mutex
+	added int
+}
+
+// Open returns the queue stored in dir; the file is created on first Add
+func Open(dir string) *Queue {
+	return &Queue{path: filepath.Join(dir, FileName)}
+}
+
+// Path returns the queue file path
+func (q *Queue) Path() string {
+	return q.path
+}
+
+// Added returns how many pushes were queued through this handle
+func (q *Queue) Added() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.added
+}
+
+// Entries returns the queued pushes, oldest first
+func (q *Queue) Entries() ([]Entry, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.load()
+}
+
+// Add appends a push to the queue, replacing an earlier entry for the same
+// repository, remote and branch in place so ordering is preserved
+func (q *Queue) Add(entry Entry) error {
+	unlock, err := q.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	entries, err := q.load()
+	if err != nil {
+		return err
+	}
+	if entry.Queued.IsZero() {
+		entry.Queued = time.Now()
+	}
+
+	replaced := false
+	for i, existing := range entries {
+		if existing.Key() == entry.Key() {
+			entry.Queued = existing.Queued
+			entry.Attempts = existing.Attempts
+			entry.Failures = existing.Failures
+			if entry.Mode == ModeLease {
+				entry.Lease = existing.Lease
+			}
+			entries[i] = entry
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		entries = append(entries, entry)
+	}
+
+	q.added++
+	return q.save(entries)
+}
+
+// Remove drops any queued push for the given repository, remote and branch,
+// e.g. after a later push of the same ref went through
+func (q *Queue) Remove(path, remote, branch string) error {
+	return q.Update(func(entries []Entry) []Entry {
+		key := Entry{Path: path, Remote: remote, Branch: branch}.Key()
+		kept := entries[:0]
+		for _, entry := range entries {
+			if entry.Key() != key {
+				kept = append(kept, entry)
+			}
+		}
+		return kept
+	})
+}
+
+// Update replaces the queue with fn's result while holding the lock. fn
+// should not do slow work such as pushing: other processes wait on it.
+func (q *Queue) Update(fn func([]Entry) []Entry) error {
+	unlock, err := q.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	entries, err := q.load()
+	if err != nil {
+		return err
+	}
+	return q.save(fn(entries))
+}
+
+// Pending counts queued pushes per repository path
+func (q *Queue) Pending() (map[string]int, error) {
+	entries, err := q.Entries()
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int)
+	for _, entry := range entries {
+		counts[entry.Path]++
+	}
+	return counts, nil
+}
+
+// lock takes the in-process mutex and the cross-process file lock
+func (q *Queue) lock() (func(), error) {
+	q.mu.Lock()
+	if err := os.MkdirAll(filepath.Dir(q.path), 0755); err != nil {
+		q.mu.Unlock()
+		return nil, err
+	}
+	file, err := os.OpenFile(q.path+".lock", os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		q.mu.Unlock()
+		return nil, fmt.Errorf("open push queue lock: %w", err)
+	}
+	if err := lockFile(file); err != nil {
+		file.Close()
+		q.mu.Unlock()
+		return nil, fmt.Errorf("lock push queue: %w", err)
+	}
+	return func() {
+		unlockFile(file)
+		file.Close()
+		q.mu.Unlock()
+	}, nil
+}
+
+func (q *Queue) load() ([]Entry, error) {
+	data, err := os.ReadFile(q.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var entries []Entry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("parse push queue %s: %w", q.path, err)
+	}
+	return entries, nil
+}
+
+func (q *Queue) save(entries []Entry) error {
+	if len(entries) == 0 {
+		if err := os.Remove(q.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal push queue: %w", err)
+	}
+	tmp := q.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, q.path)
+}
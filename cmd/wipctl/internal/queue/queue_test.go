@@ -0,0 +1,189 @@
+package queue
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"sync"
+	"testing"
+)
+
+func TestAddReplacesSameRef(t *testing.T) {
+	q := Open(t.TempDir())
+
+	first := Entry{Repo: "api", Path: "/ws/api", Remote: "origin", Branch: "wip/a", Commit: "111", Mode: ModeLease, Lease: "aaa"}
+	other := Entry{Repo: "web", Path: "/ws/web", Remote: "origin", Branch: "wip/b", Commit: "222", Mode: ModeUpstream}
+	if err := q.Add(first); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if err := q.Add(other); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if err := q.Update(func(entries []Entry) []Entry {
+		entries[0].Attempts = 2
+		return entries
+	}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	newer := first
+	newer.Commit = "333"
+	newer.Lease = "bbb"
+	if err := q.Add(newer); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+
+	entries, err := q.Entries()
+	if err != nil {
+		t.Fatalf("Entries: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("len(entries) = %d, want 2", len(entries))
+	}
+	got := entries[0]
+	if got.Repo != "api" || got.Commit != "333" {
+		t.Errorf("first entry = %s@%s, want api@333 kept in place", got.Repo, got.Commit)
+	}
+	if got.Lease != "aaa" {
+		t.Errorf("lease = %q, want the original lease %q", got.Lease, "aaa")
+	}
+	if got.Attempts != 2 {
+		t.Errorf("attempts = %d, want 2 carried over", got.Attempts)
+	}
+	if q.Added() != 3 {
+		t.Errorf("Added = %d, want 3", q.Added())
+	}
+}
+
+func TestRemoveAndPending(t *testing.T) {
+	q := Open(t.TempDir())
+
+	entries := []Entry{
+		{Repo: "api", Path: "/ws/api", Remote: "origin", Branch: "wip/a"},
+		{Repo: "api", Path: "/ws/api", Remote: "wip", Branch: "wip/a"},
+		{Repo: "web", Path: "/ws/web", Remote: "origin", Branch: "wip/b"},
+	}
+	for _, entry := range entries {
+		if err := q.Add(entry); err != nil {
+			t.Fatalf("Add: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		remove  Entry
+		pending map[string]int
+	}{
+		{name: "other remote kept", remove: entries[0], pending: map[string]int{"/ws/api": 1, "/ws/web": 1}},
+		{name: "missing entry is not an error", remove: entries[0], pending: map[string]int{"/ws/api": 1, "/ws/web": 1}},
+		{name: "last entry of a repo", remove: entries[1], pending: map[string]int{"/ws/web": 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := q.Remove(tt.remove.Path, tt.remove.Remote, tt.remove.Branch); err != nil {
+				t.Fatalf("Remove: %v", err)
+			}
+			pending, err := q.Pending()
+			if err != nil {
+				t.Fatalf("Pending: %v", err)
+			}
+			if len(pending) != len(tt.pending) {
+				t.Fatalf("Pending = %v, want %v", pending, tt.pending)
+			}
+			for path, count := range tt.pending {
+				if pending[path] != count {
+					t.Errorf("Pending[%s] = %d, want %d", path, pending[path], count)
+				}
+			}
+		})
+	}
+}
+
+func TestEmptyQueueRemovesFile(t *testing.T) {
+	q := Open(t.TempDir())
+	if err := q.Add(Entry{Path: "/ws/api", Remote: "origin", Branch: "wip/a"}); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if err := q.Update(func([]Entry) []Entry { return nil }); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if _, err := os.Stat(q.Path()); !os.IsNotExist(err) {
+		t.Errorf("queue file still exists after emptying: %v", err)
+	}
+}
+
+func TestConcurrentHandlesKeepEveryEntry(t *testing.T) {
+	dir := t.TempDir()
+
+	// Separate handles stand in for separate processes: only the file lock
+	// keeps their read-modify-write cycles apart
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			entry := Entry{Path: "/ws/api", Remote: "origin", Branch: string(rune('a' + i))}
+			if err := Open(dir).Add(entry); err != nil {
+				t.Errorf("Add: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	entries, err := Open(dir).Entries()
+	if err != nil {
+		t.Fatalf("Entries: %v", err)
+	}
+	if len(entries) != 20 {
+		t.Errorf("len(entries) = %d, want 20", len(entries))
+	}
+}
+
+// helperDirEnv tells a re-executed test binary to act as a queue writer
+const helperDirEnv = "WIPCTL_QUEUE_HELPER_DIR"
+
+const helperEntries = 30
+
+func TestEnqueueFromTwoProcesses(t *testing.T) {
+	dir := t.TempDir()
+
+	var helpers []*exec.Cmd
+	for _, name := range []string{"api", "web"} {
+		cmd := exec.Command(os.Args[0], "-test.run=^TestEnqueueHelperProcess$")
+		cmd.Env = append(os.Environ(), helperDirEnv+"="+dir, "WIPCTL_QUEUE_HELPER_REPO="+name)
+		if err := cmd.Start(); err != nil {
+			t.Fatalf("start helper: %v", err)
+		}
+		helpers = append(helpers, cmd)
+	}
+	for _, cmd := range helpers {
+		if err := cmd.Wait(); err != nil {
+			t.Fatalf("helper process failed: %v", err)
+		}
+	}
+
+	entries, err := Open(dir).Entries()
+	if err != nil {
+		t.Fatalf("Entries: %v", err)
+	}
+	if len(entries) != 2*helperEntries {
+		t.Errorf("len(entries) = %d, want %d: a writer lost the other's updates", len(entries), 2*helperEntries)
+	}
+}
+
+// TestEnqueueHelperProcess is the writer run by TestEnqueueFromTwoProcesses
+func TestEnqueueHelperProcess(t *testing.T) {
+	dir := os.Getenv(helperDirEnv)
+	if dir == "" {
+		t.Skip("only run as a helper process")
+	}
+	repo := os.Getenv("WIPCTL_QUEUE_HELPER_REPO")
+	q := Open(dir)
+	for i := 0; i < helperEntries; i++ {
+		entry := Entry{Repo: repo, Path: "/ws/" + repo, Remote: "origin", Branch: fmt.Sprintf("wip/%d", i)}
+		if err := q.Add(entry); err != nil {
+			t.Fatalf("Add: %v", err)
+		}
+	}
+}
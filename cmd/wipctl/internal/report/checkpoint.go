@@ -6,11 +6,15 @@ import (
 	"path/filepath"
 	"strings"
 	"time"
+
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/aiusage"
 )
 
 type CheckpointEntry struct {
 	ReportEntry
-	// Enhanced checkpoint-specific fields
+	// Enhanced checkpoint-specific fields. Path is the repository directory;
+	// Repo is only its base name.
+	Path            string   `json:"path"`
 	Branch          string   `json:"branch"`
 	FilesModified   int      `json:"files_modified"`
 	FilesAdded      int      `json:"files_added"`
@@ -23,6 +27,7 @@ type CheckpointEntry struct {
 	CommitMessage   string   `json:"commit_message"`
 	FeatureName     string   `json:"feature_name,omitempty"`
 	CrossRepoGroup  string   `json:"cross_repo_group,omitempty"`
+	PushQueued      bool     `json:"push_queued,omitempty"`
 }
 
 type CheckpointReport struct {
@@ -33,6 +38,7 @@ type CheckpointReport struct {
 	SuccessfulRepos  int                `json:"successful_repos"`
 	FailedRepos      int                `json:"failed_repos"`
 	SkippedRepos     int                `json:"skipped_repos"`
+	QueuedRepos      int                `json:"queued_repos"`
 	TotalFiles       int                `json:"total_files"`
 	TotalLines       int                `json:"total_lines"`
 	WorkspaceChanges string             `json:"workspace_changes"`
@@ -63,9 +69,12 @@ func (r *CheckpointReport) AddCheckpointEntry(entry CheckpointEntry) {
 		r.TotalLines += entry.LinesAdded + entry.LinesRemoved
 	case "failed":
 		r.FailedRepos++
-	case "skipped":
+	case "skipped", "aborted":
 		r.SkippedRepos++
 	}
+	if entry.PushQueued {
+		r.QueuedRepos++
+	}
 }
 
 func (r *CheckpointReport) GenerateWorkspaceSummary() {
@@ -83,10 +92,22 @@ func (r *CheckpointReport) GenerateWorkspaceSummary() {
 	if r.FailedRepos > 0 {
 		summaryParts = append(summaryParts, fmt.Sprintf("%d failures", r.FailedRepos))
 	}
+	if r.QueuedRepos > 0 {
+		summaryParts = append(summaryParts, fmt.Sprintf("%d pushes queued", r.QueuedRepos))
+	}
 
 	r.WorkspaceChanges = strings.Join(summaryParts, ", ")
 }
 
+// AIUsage totals the AI calls of every entry
+func (r *CheckpointReport) AIUsage() aiusage.Usage {
+	var usages []aiusage.Usage
+	for _, entry := range r.Entries {
+		usages = append(usages, entry.Usage...)
+	}
+	return aiusage.Total(usages)
+}
+
 func (r *CheckpointReport) Save() error {
 	if err := os.MkdirAll(r.reportDir, 0755); err != nil {
 		return fmt.Errorf("create report directory: %w", err)
@@ -133,8 +154,14 @@ func (r *CheckpointReport) generateEnhancedMarkdown() string {
 	if r.SkippedRepos > 0 {
 		sb.WriteString(fmt.Sprintf("- ⏭️ **Skipped:** %d repositories\n", r.SkippedRepos))
 	}
+	if r.QueuedRepos > 0 {
+		sb.WriteString(fmt.Sprintf("- 📤 **Push Queued:** %d repositories (run `wipctl flush` once online)\n", r.QueuedRepos))
+	}
 	sb.WriteString(fmt.Sprintf("- 📁 **Total Files:** %d\n", r.TotalFiles))
 	sb.WriteString(fmt.Sprintf("- 📝 **Total Lines:** %d\n", r.TotalLines))
+	if total := r.AIUsage(); total.Calls > 0 {
+		sb.WriteString(fmt.Sprintf("- 🤖 **AI Total:** %s\n", total.Summary()))
+	}
 	sb.WriteString("\n")
 
 	// Cross-repo feature analysis
@@ -183,6 +210,8 @@ func (r *CheckpointReport) formatCheckpointEntry(entry CheckpointEntry) string {
 		statusIcon = "❌"
 	} else if entry.Outcome == "skipped" {
 		statusIcon = "⏭️"
+	} else if entry.Outcome == "aborted" {
+		statusIcon = "🛑"
 	}
 
 	sb.WriteString(fmt.Sprintf("### %s **%s** (%s)\n\n", statusIcon, entry.Repo, entry.Branch))
@@ -201,9 +230,15 @@ func (r *CheckpointReport) formatCheckpointEntry(entry CheckpointEntry) string {
 		if entry.CommitHash != "" {
 			sb.WriteString(fmt.Sprintf("- **Commit:** `%s`\n", entry.CommitHash))
 		}
+		if entry.PushQueued {
+			sb.WriteString("- **Push:** queued (remote unreachable)\n")
+		}
 		if entry.CommitMessage != "" {
 			sb.WriteString(fmt.Sprintf("- **Message:** %s\n", entry.CommitMessage))
 		}
+		if entry.AI != "" {
+			sb.WriteString(fmt.Sprintf("- **AI:** %s\n", entry.AI))
+		}
 
 		// File statistics
 		if entry.FilesModified > 0 || entry.FilesAdded > 0 {
@@ -237,6 +272,11 @@ func (r *CheckpointReport) formatCheckpointEntry(entry CheckpointEntry) string {
 		}
 	}
 
+	// AI calls are paid for whether or not the checkpoint succeeded
+	for _, usage := range aiusage.Merge(entry.Usage) {
+		sb.WriteString(fmt.Sprintf("- **%s** %s\n", aiusage.Label, usage))
+	}
+
 	// Warnings and errors
 	for _, warning := range entry.Warnings {
 		sb.WriteString(fmt.Sprintf("  ⚠️ %s\n", warning))
@@ -244,6 +284,8 @@ func (r *CheckpointReport) formatCheckpointEntry(entry CheckpointEntry) string {
 	for _, err := range entry.Errors {
 		sb.WriteString(fmt.Sprintf("  ❌ %s\n", err))
 	}
+	sb.WriteString(formatHookRuns(entry.Hooks))
+	sb.WriteString(formatGateRuns(entry.Gates))
 
 	return sb.String()
 }
@@ -6,6 +6,8 @@ import (
 	"path/filepath"
 	"strings"
 	"time"
+
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/aiusage"
 )
 
 type ReportEntry struct {
@@ -14,6 +16,26 @@ type ReportEntry struct {
 	Details  string
 	Warnings []string
 	Errors   []string
+	Hooks    []HookRun
+	Gates    []GateRun
+	AI       string          // provider that generated content, with any fallbacks
+	Usage    []aiusage.Usage // AI calls made for the entry, priced
+}
+
+// HookRun records a lifecycle hook execution for a repository
+type HookRun struct {
+	Hook    string
+	Command string
+	Output  string
+	Error   string
+}
+
+// GateRun records a quality gate command run before committing
+type GateRun struct {
+	Command  string
+	Duration time.Duration
+	Output   string
+	Error    string
 }
 
 type Report struct {
@@ -39,6 +61,15 @@ func (r *Report) AddEntry(entry ReportEntry) {
 	r.Entries = append(r.Entries, entry)
 }
 
+// AIUsage totals the AI calls of every entry
+func (r *Report) AIUsage() aiusage.Usage {
+	var usages []aiusage.Usage
+	for _, entry := range r.Entries {
+		usages = append(usages, entry.Usage...)
+	}
+	return aiusage.Total(usages)
+}
+
 func (r *Report) Save() error {
 	if err := os.MkdirAll(r.reportDir, 0755); err != nil {
 		return fmt.Errorf("create report directory: %w", err)
@@ -71,27 +102,104 @@ func (r *Report) generateMarkdown() string {
 	sb.WriteString("## Results\n\n")
 
 	for _, entry := range r.Entries {
-		sb.WriteString(fmt.Sprintf("- **%s**: %s", entry.Repo, entry.Outcome))
+		sb.WriteString(formatEntry(entry))
+	}
 
-		if entry.Details != "" {
-			sb.WriteString(fmt.Sprintf(" - %s", entry.Details))
-		}
+	if total := r.AIUsage(); total.Calls > 0 {
+		sb.WriteString(fmt.Sprintf("\n**🤖 AI Total:** %s\n", total.Summary()))
+	}
 
-		sb.WriteString("\n")
+	sb.WriteString("\n")
+	return sb.String()
+}
 
-		for _, warning := range entry.Warnings {
-			sb.WriteString(fmt.Sprintf("  ⚠ %s\n", warning))
-		}
+func formatEntry(entry ReportEntry) string {
+	var sb strings.Builder
 
-		for _, error := range entry.Errors {
-			sb.WriteString(fmt.Sprintf("  ❌ %s\n", error))
-		}
+	sb.WriteString(fmt.Sprintf("- **%s**: %s", entry.Repo, entry.Outcome))
+
+	if entry.Details != "" {
+		sb.WriteString(fmt.Sprintf(" - %s", entry.Details))
 	}
 
 	sb.WriteString("\n")
+
+	if entry.AI != "" {
+		sb.WriteString(fmt.Sprintf("  🤖 AI: %s\n", entry.AI))
+	}
+	for _, usage := range aiusage.Merge(entry.Usage) {
+		sb.WriteString(fmt.Sprintf("  💰 %s %s\n", aiusage.Label, usage))
+	}
+
+	for _, warning := range entry.Warnings {
+		sb.WriteString(fmt.Sprintf("  ⚠ %s\n", warning))
+	}
+
+	for _, error := range entry.Errors {
+		sb.WriteString(fmt.Sprintf("  ❌ %s\n", error))
+	}
+
+	sb.WriteString(formatHookRuns(entry.Hooks))
+	sb.WriteString(formatGateRuns(entry.Gates))
 	return sb.String()
 }
 
+// ReportTime returns when a report was started, from its file name
+func ReportTime(path string) (time.Time, bool) {
+	name := strings.TrimSuffix(filepath.Base(path), ".md")
+	const layout = "20060102-150405"
+	if len(name) < len(layout) {
+		return time.Time{}, false
+	}
+	started, err := time.ParseInLocation(layout, name[len(name)-len(layout):], time.Local)
+	return started, err == nil
+}
+
+// LatestReport returns the newest saved report for an operation
+func LatestReport(reportDir, operation string) (string, error) {
+	reports, err := ListReports(reportDir)
+	if err != nil {
+		return "", err
+	}
+
+	prefix := fmt.Sprintf("wip-%s-", operation)
+	latest := ""
+	for _, path := range reports {
+		// Timestamped names sort chronologically
+		if strings.HasPrefix(filepath.Base(path), prefix) && path > latest {
+			latest = path
+		}
+	}
+
+	if latest == "" {
+		return "", fmt.Errorf("no %s reports found", operation)
+	}
+	return latest, nil
+}
+
+// AppendSection adds a titled block of entries to an existing report file
+func AppendSection(path, title string, entries []ReportEntry) error {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("## %s\n\n", title))
+	sb.WriteString(fmt.Sprintf("**Timestamp:** %s  \n\n", time.Now().Format(time.RFC3339)))
+	for _, entry := range entries {
+		sb.WriteString(formatEntry(entry))
+	}
+	sb.WriteString("\n")
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("open report file: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.WriteString(sb.String()); err != nil {
+		return fmt.Errorf("append report section: %w", err)
+	}
+	return nil
+}
+
 func ListReports(reportDir string) ([]string, error) {
 	files, err := os.ReadDir(reportDir)
 	if err != nil {
@@ -145,4 +253,54 @@ func (e *ReportEntry) AddWarning(warning string) {
 
 func (e *ReportEntry) AddError(error string) {
 	e.Errors = append(e.Errors, error)
+}
+
+func (e *ReportEntry) AddHookRun(run HookRun) {
+	e.Hooks = append(e.Hooks, run)
+}
+
+// formatGateRuns renders quality gate results, with output for failures
+func formatGateRuns(runs []GateRun) string {
+	var sb strings.Builder
+
+	for _, run := range runs {
+		status := "ok"
+		if run.Error != "" {
+			status = "failed: " + run.Error
+		}
+		sb.WriteString(fmt.Sprintf("  🚦 `%s` (%s, %s)\n", run.Command, status, run.Duration.Round(time.Millisecond)))
+
+		if run.Output != "" {
+			sb.WriteString("  ```\n")
+			for _, line := range strings.Split(run.Output, "\n") {
+				sb.WriteString("  " + line + "\n")
+			}
+			sb.WriteString("  ```\n")
+		}
+	}
+
+	return sb.String()
+}
+
+// formatHookRuns renders hook executions with their captured output
+func formatHookRuns(runs []HookRun) string {
+	var sb strings.Builder
+
+	for _, run := range runs {
+		status := "ok"
+		if run.Error != "" {
+			status = "failed: " + run.Error
+		}
+		sb.WriteString(fmt.Sprintf("  🪝 %s `%s` (%s)\n", run.Hook, run.Command, status))
+
+		if run.Output != "" {
+			sb.WriteString("  ```\n")
+			for _, line := range strings.Split(run.Output, "\n") {
+				sb.WriteString("  " + line + "\n")
+			}
+			sb.WriteString("  ```\n")
+		}
+	}
+
+	return sb.String()
 }
\ No newline at end of file
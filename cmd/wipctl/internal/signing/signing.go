@@ -0,0 +1,84 @@
+package signing
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/config"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/gitexec"
+)
+
+// AllowedSignersFileName is the generated SSH allowed-signers file in the report directory
+const AllowedSignersFileName = "allowed_signers"
+
+// Verdict is the trust decision for a WIP commit
+type Verdict struct {
+	Trusted bool
+	Host    string // trusted host that signed the commit
+	Reason  string // why the commit is not trusted
+}
+
+// WriteAllowedSigners writes the SSH keys of the keyring in git's allowed-signers
+// format, with the host name as principal, and returns the file path
+func WriteAllowedSigners(dir string, trusted []config.TrustedKey) (string, error) {
+	var sb strings.Builder
+	for _, key := range trusted {
+		if !config.IsSSHKey(key.Key) {
+			continue
+		}
+		principal := strings.Join(strings.Fields(key.Host), "-")
+		sb.WriteString(fmt.Sprintf("%s namespaces=\"git\" %s\n", principal, strings.TrimSpace(key.Key)))
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("create report directory: %w", err)
+	}
+	// git reads the file from inside each repository, so the path must be absolute
+	path, err := filepath.Abs(filepath.Join(dir, AllowedSignersFileName))
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(path, []byte(sb.String()), 0644); err != nil {
+		return "", fmt.Errorf("write allowed signers: %w", err)
+	}
+	return path, nil
+}
+
+// Evaluate decides whether a commit signature comes from the trusted keyring
+func Evaluate(sig gitexec.Signature, trusted []config.TrustedKey) Verdict {
+	switch sig.Status {
+	case "N", "":
+		return Verdict{Reason: "unsigned"}
+	case "B":
+		return Verdict{Reason: "bad signature"}
+	case "E":
+		return Verdict{Reason: "signature cannot be checked (key not available)"}
+	case "X", "Y", "R":
+		return Verdict{Reason: "signature made with an expired or revoked key"}
+	}
+
+	// SSH signatures only verify as good when the key is in allowed_signers
+	if strings.HasPrefix(sig.Key, "SHA256:") {
+		if sig.Status == "G" && sig.Signer != "" {
+			return Verdict{Trusted: true, Host: sig.Signer}
+		}
+		return Verdict{Reason: "signed by untrusted SSH key " + sig.Key}
+	}
+
+	// Only full fingerprints are compared: a key id suffix is easy to collide.
+	// GPG usually signs with a subkey, so the primary key's fingerprint, which
+	// is what people publish, is accepted as well.
+	fingerprint := config.TrustedKey{Key: sig.Fingerprint}.Fingerprint()
+	primary := config.TrustedKey{Key: sig.Primary}.Fingerprint()
+	for _, key := range trusted {
+		if config.IsSSHKey(key.Key) {
+			continue
+		}
+		if want := key.Fingerprint(); want != "" && (want == fingerprint || want == primary) {
+			return Verdict{Trusted: true, Host: key.Host}
+		}
+	}
+	return Verdict{Reason: "signed by untrusted GPG key " + sig.Fingerprint}
+}
@@ -0,0 +1,69 @@
+package signing
+
+import (
+	"testing"
+
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/config"
+	"github.com/TheBranchDriftCatalyst/cli-tools/cmd/wipctl/internal/gitexec"
+)
+
+func TestEvaluate(t *testing.T) {
+	const fingerprint = "0123456789ABCDEF0123456789ABCDEF01234567"
+	trusted := []config.TrustedKey{
+		{Host: "laptop", Key: "0123 4567 89ab cdef 0123  4567 89ab cdef 0123 4567"},
+		{Host: "desk", Key: "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAA desk"},
+		{Host: "short", Key: "89ABCDEF01234567"},
+	}
+
+	tests := []struct {
+		name    string
+		sig     gitexec.Signature
+		trusted bool
+		host    string
+	}{
+		{name: "unsigned", sig: gitexec.Signature{Status: "N"}},
+		{name: "bad", sig: gitexec.Signature{Status: "B", Fingerprint: fingerprint}},
+		{name: "expired key", sig: gitexec.Signature{Status: "X", Fingerprint: fingerprint}},
+		{name: "trusted gpg", sig: gitexec.Signature{Status: "G", Fingerprint: fingerprint}, trusted: true, host: "laptop"},
+		{name: "unknown validity gpg", sig: gitexec.Signature{Status: "U", Fingerprint: fingerprint}, trusted: true, host: "laptop"},
+		{name: "subkey of trusted primary", sig: gitexec.Signature{Status: "G", Fingerprint: "FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFF", Primary: fingerprint}, trusted: true, host: "laptop"},
+		{name: "untrusted primary", sig: gitexec.Signature{Status: "G", Fingerprint: "FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFF", Primary: "EEEEEEEEEEEEEEEEEEEEEEEEEEEEEEEEEEEEEEEE"}},
+		{name: "key id suffix is not enough", sig: gitexec.Signature{Status: "G", Fingerprint: "FFFFFFFFFFFFFFFFFFFFFFFF89ABCDEF01234567"}},
+		{name: "trusted ssh", sig: gitexec.Signature{Status: "G", Key: "SHA256:abc", Signer: "desk"}, trusted: true, host: "desk"},
+		{name: "untrusted ssh", sig: gitexec.Signature{Status: "U", Key: "SHA256:abc"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verdict := Evaluate(tt.sig, trusted)
+			if verdict.Trusted != tt.trusted || verdict.Host != tt.host {
+				t.Errorf("Evaluate = %+v, want trusted=%v host=%q", verdict, tt.trusted, tt.host)
+			}
+			if !verdict.Trusted && verdict.Reason == "" {
+				t.Error("untrusted verdict has no reason")
+			}
+		})
+	}
+}
+
+func TestTrustedKeyValidate(t *testing.T) {
+	tests := []struct {
+		key     string
+		wantErr bool
+	}{
+		{key: "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAA"},
+		{key: "ecdsa-sha2-nistp256 AAAA"},
+		{key: "0123456789ABCDEF0123456789ABCDEF01234567"},
+		{key: "0123 4567 89ab cdef 0123 4567 89ab cdef 0123 4567"},
+		{key: "89ABCDEF01234567", wantErr: true},
+		{key: "0x89ABCDEF", wantErr: true},
+		{key: "0123456789ABCDEF0123456789ABCDEF0123456Z", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		err := config.TrustedKey{Host: "h", Key: tt.key}.Validate()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Validate(%q) error = %v, wantErr %v", tt.key, err, tt.wantErr)
+		}
+	}
+}
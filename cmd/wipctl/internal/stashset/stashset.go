@@ -0,0 +1,174 @@
+// Package stashset records named stash sets: one stash entry per dirty
+// repository, saved together under a label. Entries are identified by their
+// stash commit, so they can be found again however the stash stack has moved.
+package stashset
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FileName is the stash set file kept in the report directory
+const FileName = "stash-sets.json"
+
+// messagePrefix labels the stash entries that belong to a set
+const messagePrefix = "wipctl stash set: "
+
+// Entry is one repository's stash within a set
+type Entry struct {
+	Repo   string `json:"repo"`
+	Path   string `json:"path"`
+	Branch string `json:"branch"`
+	Commit string `json:"commit"`
+}
+
+// Set is a labelled group of stash entries created together
+type Set struct {
+	Name    string    `json:"name"`
+	Created time.Time `json:"created"`
+	Host    string    `json:"host,omitempty"`
+	Entries []Entry   `json:"entries"`
+}
+
+// Message returns the stash message used for entries of the named set
+func Message(name string) string {
+	return messagePrefix + name
+}
+
+// ValidateName rejects names that cannot be used as a set label
+func ValidateName(name string) error {
+	if strings.TrimSpace(name) == "" {
+		return errors.New("stash set name must not be empty")
+	}
+	if strings.ContainsAny(name, "\n\r") {
+		return errors.New("stash set name must be a single line")
+	}
+	return nil
+}
+
+// Store is the on-disk list of stash sets. It is safe for concurrent use
+// within one process.
+type Store struct {
+	path string
+	mu   sync.Mutex
+}
+
+// Open returns the store kept in dir; the file is created on first Put
+func Open(dir string) *Store {
+	return &Store{path: filepath.Join(dir, FileName)}
+}
+
+// Path returns the store file path
+func (s *Store) Path() string {
+	return s.path
+}
+
+// List returns all sets, oldest first
+func (s *Store) List() ([]Set, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sets, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(sets, func(i, j int) bool { return sets[i].Created.Before(sets[j].Created) })
+	return sets, nil
+}
+
+// Get returns the named set
+func (s *Store) Get(name string) (Set, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sets, err := s.load()
+	if err != nil {
+		return Set{}, false, err
+	}
+	for _, set := range sets {
+		if set.Name == name {
+			return set, true, nil
+		}
+	}
+	return Set{}, false, nil
+}
+
+// Put adds a set, replacing any existing set with the same name
+func (s *Store) Put(set Set) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sets, err := s.load()
+	if err != nil {
+		return err
+	}
+	for i, existing := range sets {
+		if existing.Name == set.Name {
+			sets[i] = set
+			return s.save(sets)
+		}
+	}
+	return s.save(append(sets, set))
+}
+
+// Delete removes the named set; deleting a missing set is not an error
+func (s *Store) Delete(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sets, err := s.load()
+	if err != nil {
+		return err
+	}
+	kept := sets[:0]
+	for _, set := range sets {
+		if set.Name != name {
+			kept = append(kept, set)
+		}
+	}
+	return s.save(kept)
+}
+
+func (s *Store) load() ([]Set, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var sets []Set
+	if err := json.Unmarshal(data, &sets); err != nil {
+		return nil, fmt.Errorf("parse stash sets %s: %w", s.path, err)
+	}
+	return sets, nil
+}
+
+func (s *Store) save(sets []Set) error {
+	if len(sets) == 0 {
+		if err := os.Remove(s.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+
+	data, err := json.MarshalIndent(sets, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal stash sets: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
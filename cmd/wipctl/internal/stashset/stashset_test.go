@@ -0,0 +1,83 @@
+package stashset
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestValidateName(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr bool
+	}{
+		{name: "plain", input: "before-refactor"},
+		{name: "spaces inside", input: "before the demo"},
+		{name: "empty", input: "", wantErr: true},
+		{name: "blank", input: "   ", wantErr: true},
+		{name: "multi-line", input: "a\nb", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateName(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateName(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMessage(t *testing.T) {
+	if got, want := Message("demo"), "wipctl stash set: demo"; got != want {
+		t.Errorf("Message = %q, want %q", got, want)
+	}
+}
+
+func TestStore(t *testing.T) {
+	store := Open(t.TempDir())
+	now := time.Now()
+
+	newer := Set{Name: "newer", Created: now, Entries: []Entry{{Repo: "api", Path: "/ws/api", Branch: "main", Commit: "abc"}}}
+	older := Set{Name: "older", Created: now.Add(-time.Hour)}
+	for _, set := range []Set{newer, older} {
+		if err := store.Put(set); err != nil {
+			t.Fatalf("Put: %v", err)
+		}
+	}
+
+	sets, err := store.List()
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(sets) != 2 || sets[0].Name != "older" || sets[1].Name != "newer" {
+		t.Fatalf("List = %v, want older then newer", sets)
+	}
+
+	replaced := newer
+	replaced.Entries = append(replaced.Entries, Entry{Repo: "web", Path: "/ws/web", Branch: "dev", Commit: "def"})
+	if err := store.Put(replaced); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	got, ok, err := store.Get("newer")
+	if err != nil || !ok {
+		t.Fatalf("Get = %v, %v", ok, err)
+	}
+	if len(got.Entries) != 2 || got.Entries[1].Commit != "def" {
+		t.Errorf("Get entries = %v, want the replaced set", got.Entries)
+	}
+
+	if _, ok, _ := store.Get("missing"); ok {
+		t.Error("Get(missing) found a set")
+	}
+
+	for _, name := range []string{"newer", "missing", "older"} {
+		if err := store.Delete(name); err != nil {
+			t.Fatalf("Delete(%s): %v", name, err)
+		}
+	}
+	if _, err := os.Stat(store.Path()); !os.IsNotExist(err) {
+		t.Errorf("store file still exists after deleting every set: %v", err)
+	}
+}
@@ -3,7 +3,9 @@ package ui
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"os"
+	"os/exec"
 	"strings"
 
 	"github.com/pterm/pterm"
@@ -125,6 +127,10 @@ func StatusCell(status string) string {
 		return pterm.FgLightYellow.Sprint("⊘ NO-REMOTE")
 	case "in-progress":
 		return pterm.FgLightMagenta.Sprint("⟳ IN-PROGRESS")
+	case "conflicts":
+		return pterm.FgRed.Sprint("✗ CONFLICTS")
+	case "diverged":
+		return pterm.FgLightYellow.Sprint("⑂ DIVERGED")
 	default:
 		return status
 	}
@@ -162,6 +168,8 @@ func SynthwaveNumber(n int, colorType string) string {
 		return pterm.FgGreen.Sprint("[" + numStr + "]")
 	case "behind":
 		return pterm.FgRed.Sprint("[" + numStr + "]")
+	case "pending":
+		return pterm.FgMagenta.Sprint("[" + numStr + "]")
 	default:
 		return pterm.FgWhite.Sprint("[" + numStr + "]")
 	}
@@ -185,14 +193,83 @@ func Error(msg string) {
 	pterm.Error.Println(msg)
 }
 
+// StreamPrinter prints text as it is generated. The header runs once, just
+// before the first chunk, so nothing is shown for a request that fails early.
+type StreamPrinter struct {
+	header  func()
+	started bool
+	newline bool
+}
+
+// NewStreamPrinter creates a printer that calls header before the first chunk
+func NewStreamPrinter(header func()) *StreamPrinter {
+	return &StreamPrinter{header: header}
+}
+
+// Write prints a chunk of streamed text
+func (p *StreamPrinter) Write(chunk string) {
+	if !p.started {
+		p.started = true
+		if p.header != nil {
+			p.header()
+		}
+		chunk = strings.TrimLeft(chunk, "\n")
+	}
+	fmt.Print(chunk)
+	p.newline = strings.HasSuffix(chunk, "\n")
+}
+
+// Started reports whether anything has been printed
+func (p *StreamPrinter) Started() bool {
+	return p.started
+}
+
+// Finish ends the last streamed line
+func (p *StreamPrinter) Finish() {
+	if p.started && !p.newline {
+		fmt.Println()
+	}
+}
+
 // 🔥 INPUT FUNCTIONS 🔥
 
+// stdinReader is shared so buffered input isn't lost between prompts
+var stdinReader = bufio.NewReader(os.Stdin)
+
+// SetInput makes prompts read their answers from r instead of stdin
+func SetInput(r io.Reader) {
+	stdinReader = bufio.NewReader(r)
+}
+
 func Confirm(question string) bool {
 	fmt.Printf("%s [y/N] ", question)
-	reader := bufio.NewReader(os.Stdin)
-	answer, _ := reader.ReadString('\n')
+	answer, _ := stdinReader.ReadString('\n')
 	answer = strings.TrimSpace(answer)
 	return strings.ToLower(answer) == "y" || strings.ToLower(answer) == "yes"
 }
 
+// Prompt asks a free-form question and returns the trimmed answer
+func Prompt(question string) string {
+	fmt.Printf("%s ", question)
+	answer, _ := stdinReader.ReadString('\n')
+	return strings.TrimSpace(answer)
+}
+
+// OpenEditor opens path in $VISUAL/$EDITOR (default vi) attached to the terminal
+func OpenEditor(path string) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+
+	cmd := exec.Command("sh", "-c", editor+` "$1"`, "editor", path)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
 // Legacy functions removed - use InitTable/AddTableRow/RenderTable directly
\ No newline at end of file
@@ -0,0 +1,82 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"testing"
+)
+
+// captureStdout returns what fn prints to stdout
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = old }()
+
+	done := make(chan string)
+	go func() {
+		out, _ := io.ReadAll(r)
+		done <- string(out)
+	}()
+	fn()
+	w.Close()
+	return <-done
+}
+
+func TestStreamPrinter(t *testing.T) {
+	tests := []struct {
+		name    string
+		chunks  []string
+		want    string
+		started bool
+	}{
+		{name: "nothing streamed", want: ""},
+		{name: "header before the first chunk", chunks: []string{"Hello", " world"}, want: "[header]Hello world\n", started: true},
+		{name: "leading newlines trimmed", chunks: []string{"\n\nHello", "\n\nworld"}, want: "[header]Hello\n\nworld\n", started: true},
+		{name: "trailing newline kept", chunks: []string{"Hello\n"}, want: "[header]Hello\n", started: true},
+		{name: "newline only mid-stream", chunks: []string{"Hello\n", "world"}, want: "[header]Hello\nworld\n", started: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			headers := 0
+			var printer *StreamPrinter
+			got := captureStdout(t, func() {
+				printer = NewStreamPrinter(func() {
+					headers++
+					fmt.Print("[header]")
+				})
+				for _, chunk := range tt.chunks {
+					printer.Write(chunk)
+				}
+				printer.Finish()
+			})
+			if got != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+			if printer.Started() != tt.started {
+				t.Errorf("Started() = %v, want %v", printer.Started(), tt.started)
+			}
+			if tt.started && headers != 1 {
+				t.Errorf("header ran %d times, want once", headers)
+			}
+			if !tt.started && headers != 0 {
+				t.Errorf("header ran %d times, want never", headers)
+			}
+		})
+	}
+
+	got := captureStdout(t, func() {
+		printer := NewStreamPrinter(nil)
+		printer.Write("no header")
+		printer.Finish()
+	})
+	if got != "no header\n" {
+		t.Errorf("output without header = %q, want %q", got, "no header\n")
+	}
+}
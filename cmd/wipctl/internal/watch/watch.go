@@ -0,0 +1,245 @@
+package watch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	pidFileName   = "watch.pid"
+	stateFileName = "watch-state.json"
+	LogFileName   = "watch.log"
+)
+
+// RepoState tracks the change/checkpoint timeline of a single repository
+type RepoState struct {
+	Path           string    `json:"path"`
+	Name           string    `json:"name"`
+	Signature      string    `json:"-"`
+	Pending        bool      `json:"pending"`
+	PendingSince   time.Time `json:"pending_since,omitempty"`
+	LastChange     time.Time `json:"last_change,omitempty"`
+	LastCheckpoint time.Time `json:"last_checkpoint,omitempty"`
+	LastOutcome    string    `json:"last_outcome,omitempty"`
+}
+
+// State is the snapshot persisted for `wipctl watch status`
+type State struct {
+	PID         int          `json:"pid"`
+	Workspace   string       `json:"workspace"`
+	Started     time.Time    `json:"started"`
+	LastPoll    time.Time    `json:"last_poll"`
+	Idle        string       `json:"idle"`
+	MaxInterval string       `json:"max_interval"`
+	Repos       []*RepoState `json:"repos"`
+}
+
+// Tracker decides when a repository is due for an automatic checkpoint
+type Tracker struct {
+	idle        time.Duration
+	maxInterval time.Duration
+
+	mu    sync.Mutex
+	repos map[string]*RepoState
+}
+
+// NewTracker creates a tracker that fires after idle time or max interval
+func NewTracker(idle, maxInterval time.Duration) *Tracker {
+	return &Tracker{
+		idle:        idle,
+		maxInterval: maxInterval,
+		repos:       make(map[string]*RepoState),
+	}
+}
+
+// Observe records the latest worktree signature for a repository.
+// hasChanges reports whether the repo has anything worth checkpointing.
+func (t *Tracker) Observe(path, name, signature string, hasChanges bool, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	state, ok := t.repos[path]
+	if !ok {
+		state = &RepoState{Path: path, Name: name, Signature: signature}
+		t.repos[path] = state
+		if hasChanges {
+			state.Pending = true
+			state.PendingSince = now
+			state.LastChange = now
+		}
+		return
+	}
+
+	if !hasChanges {
+		state.Signature = signature
+		state.Pending = false
+		state.PendingSince = time.Time{}
+		return
+	}
+
+	if signature != state.Signature || !state.Pending {
+		state.Signature = signature
+		state.LastChange = now
+		if !state.Pending {
+			state.Pending = true
+			state.PendingSince = now
+		}
+	}
+}
+
+// Due returns the paths of repositories that should be checkpointed now
+func (t *Tracker) Due(now time.Time) []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var due []string
+	for path, state := range t.repos {
+		if !state.Pending {
+			continue
+		}
+		if now.Sub(state.LastChange) >= t.idle || now.Sub(state.PendingSince) >= t.maxInterval {
+			due = append(due, path)
+		}
+	}
+	sort.Strings(due)
+	return due
+}
+
+// MarkCheckpointed resets the pending window after a checkpoint attempt
+func (t *Tracker) MarkCheckpointed(path, outcome string, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	state, ok := t.repos[path]
+	if !ok {
+		return
+	}
+	state.LastCheckpoint = now
+	state.LastOutcome = outcome
+	state.Pending = false
+	state.PendingSince = time.Time{}
+	// Force the next observation to re-read the worktree rather than
+	// treating the post-checkpoint state as a fresh edit.
+	state.Signature = ""
+}
+
+// Forget drops repositories that disappeared from the workspace
+func (t *Tracker) Forget(keep map[string]bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for path := range t.repos {
+		if !keep[path] {
+			delete(t.repos, path)
+		}
+	}
+}
+
+// Snapshot returns a copy of all tracked repository states
+func (t *Tracker) Snapshot() []*RepoState {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	states := make([]*RepoState, 0, len(t.repos))
+	for _, state := range t.repos {
+		copied := *state
+		states = append(states, &copied)
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].Name < states[j].Name })
+	return states
+}
+
+// ErrRunning is returned by ClaimPID while another live daemon holds the PID file
+var ErrRunning = errors.New("watch daemon already running")
+
+// ClaimPID records pid as the daemon's in the report directory. The file is
+// created exclusively, so of two daemons started together only one wins; a
+// file left by a dead process (alive reports false) is replaced, and one that
+// already holds pid is kept.
+func ClaimPID(dir string, pid int, alive func(int) bool) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("create report directory: %w", err)
+	}
+	path := filepath.Join(dir, pidFileName)
+
+	for attempt := 0; ; attempt++ {
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			_, err = file.WriteString(strconv.Itoa(pid) + "\n")
+			if closeErr := file.Close(); err == nil {
+				err = closeErr
+			}
+			return err
+		}
+		if !errors.Is(err, fs.ErrExist) || attempt > 0 {
+			return err
+		}
+
+		current, err := ReadPID(dir)
+		switch {
+		case err == nil && current == pid:
+			return nil
+		case err == nil && alive(current):
+			return fmt.Errorf("%w (pid %d)", ErrRunning, current)
+		}
+		// Stale or unreadable: remove it and try once more
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+}
+
+// ReadPID returns the PID recorded by a running daemon
+func ReadPID(dir string) (int, error) {
+	content, err := os.ReadFile(filepath.Join(dir, pidFileName))
+	if err != nil {
+		return 0, err
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(content)))
+	if err != nil {
+		return 0, fmt.Errorf("invalid pid file: %w", err)
+	}
+	return pid, nil
+}
+
+// RemovePID deletes the PID file if it still belongs to pid
+func RemovePID(dir string, pid int) {
+	if current, err := ReadPID(dir); err == nil && current == pid {
+		os.Remove(filepath.Join(dir, pidFileName))
+	}
+}
+
+// SaveState persists the daemon snapshot for status queries
+func SaveState(dir string, state State) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal watch state: %w", err)
+	}
+	tmp := filepath.Join(dir, stateFileName+".tmp")
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("write watch state: %w", err)
+	}
+	return os.Rename(tmp, filepath.Join(dir, stateFileName))
+}
+
+// LoadState reads the last snapshot written by the daemon
+func LoadState(dir string) (*State, error) {
+	data, err := os.ReadFile(filepath.Join(dir, stateFileName))
+	if err != nil {
+		return nil, err
+	}
+	var state State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("parse watch state: %w", err)
+	}
+	return &state, nil
+}
@@ -0,0 +1,188 @@
+package watch
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTrackerDue(t *testing.T) {
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) time.Time { return start.Add(d) }
+
+	type observation struct {
+		at         time.Duration
+		signature  string
+		hasChanges bool
+	}
+
+	tests := []struct {
+		name         string
+		observations []observation
+		check        time.Duration
+		due          bool
+	}{
+		{
+			name:         "clean repo never due",
+			observations: []observation{{at: 0, signature: "a"}},
+			check:        time.Hour,
+		},
+		{
+			name:         "changed repo due after idle",
+			observations: []observation{{at: 0, signature: "a", hasChanges: true}},
+			check:        5 * time.Minute,
+			due:          true,
+		},
+		{
+			name:         "still editing",
+			observations: []observation{{at: 0, signature: "a", hasChanges: true}, {at: 4 * time.Minute, signature: "b", hasChanges: true}},
+			check:        5 * time.Minute,
+		},
+		{
+			name:         "unchanged signature keeps idle clock",
+			observations: []observation{{at: 0, signature: "a", hasChanges: true}, {at: 4 * time.Minute, signature: "a", hasChanges: true}},
+			check:        5 * time.Minute,
+			due:          true,
+		},
+		{
+			name: "max interval despite constant edits",
+			observations: []observation{
+				{at: 0, signature: "a", hasChanges: true},
+				{at: 20 * time.Minute, signature: "b", hasChanges: true},
+				{at: 29 * time.Minute, signature: "c", hasChanges: true},
+			},
+			check: 30 * time.Minute,
+			due:   true,
+		},
+		{
+			name:         "changes reverted",
+			observations: []observation{{at: 0, signature: "a", hasChanges: true}, {at: time.Minute, signature: "b"}},
+			check:        time.Hour,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := NewTracker(5*time.Minute, 30*time.Minute)
+			for _, obs := range tt.observations {
+				tracker.Observe("/ws/api", "api", obs.signature, obs.hasChanges, at(obs.at))
+			}
+
+			due := tracker.Due(at(tt.check))
+			if got := len(due) == 1; got != tt.due {
+				t.Errorf("Due = %v, want due %v", due, tt.due)
+			}
+		})
+	}
+}
+
+func TestTrackerMarkCheckpointed(t *testing.T) {
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	tracker := NewTracker(time.Minute, time.Hour)
+
+	tracker.Observe("/ws/api", "api", "a", true, start)
+	tracker.MarkCheckpointed("/ws/api", "success", start.Add(2*time.Minute))
+
+	if due := tracker.Due(start.Add(10 * time.Minute)); len(due) != 0 {
+		t.Fatalf("Due after checkpoint = %v, want none", due)
+	}
+
+	// The same worktree seen again after the checkpoint counts as a new change
+	tracker.Observe("/ws/api", "api", "a", true, start.Add(3*time.Minute))
+	if due := tracker.Due(start.Add(4 * time.Minute)); !reflect.DeepEqual(due, []string{"/ws/api"}) {
+		t.Errorf("Due = %v, want [/ws/api]", due)
+	}
+
+	states := tracker.Snapshot()
+	if len(states) != 1 || states[0].LastOutcome != "success" {
+		t.Errorf("Snapshot = %+v, want one repo with outcome success", states)
+	}
+}
+
+func TestTrackerForgetAndSnapshot(t *testing.T) {
+	now := time.Now()
+	tracker := NewTracker(time.Minute, time.Hour)
+	tracker.Observe("/ws/web", "web", "a", true, now)
+	tracker.Observe("/ws/api", "api", "a", true, now)
+	tracker.Observe("/ws/old", "old", "a", true, now)
+
+	tracker.Forget(map[string]bool{"/ws/api": true, "/ws/web": true})
+
+	var names []string
+	for _, state := range tracker.Snapshot() {
+		names = append(names, state.Name)
+	}
+	if want := []string{"api", "web"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("Snapshot names = %v, want %v", names, want)
+	}
+
+	// Snapshots are copies
+	tracker.Snapshot()[0].Pending = false
+	if !tracker.Snapshot()[0].Pending {
+		t.Error("modifying a snapshot changed the tracker")
+	}
+}
+
+func TestClaimPID(t *testing.T) {
+	alive := map[int]bool{100: true}
+	isAlive := func(pid int) bool { return alive[pid] }
+
+	tests := []struct {
+		name     string
+		existing int // PID already in the file; 0 for none
+		pid      int
+		wantErr  error
+		wantPID  int
+	}{
+		{name: "no daemon", pid: 200, wantPID: 200},
+		{name: "live daemon", existing: 100, pid: 200, wantErr: ErrRunning, wantPID: 100},
+		{name: "stale pid file", existing: 300, pid: 200, wantPID: 200},
+		{name: "already ours", existing: 200, pid: 200, wantPID: 200},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if tt.existing != 0 {
+				if err := ClaimPID(dir, tt.existing, isAlive); err != nil {
+					t.Fatalf("ClaimPID(existing): %v", err)
+				}
+			}
+
+			err := ClaimPID(dir, tt.pid, isAlive)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ClaimPID error = %v, want %v", err, tt.wantErr)
+			}
+			if got, _ := ReadPID(dir); got != tt.wantPID {
+				t.Errorf("pid file = %d, want %d", got, tt.wantPID)
+			}
+		})
+	}
+}
+
+func TestClaimPIDConcurrent(t *testing.T) {
+	dir := t.TempDir()
+	alive := func(int) bool { return true }
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	won := 0
+	for pid := 1; pid <= 8; pid++ {
+		wg.Add(1)
+		go func(pid int) {
+			defer wg.Done()
+			if ClaimPID(dir, pid, alive) == nil {
+				mu.Lock()
+				won++
+				mu.Unlock()
+			}
+		}(pid)
+	}
+	wg.Wait()
+
+	if won != 1 {
+		t.Errorf("%d concurrent claims succeeded, want exactly 1", won)
+	}
+}
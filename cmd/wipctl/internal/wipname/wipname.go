@@ -0,0 +1,323 @@
+package wipname
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// DefaultTemplate reproduces the historical wip/<host>[/<feature>]/<timestamp> names
+const DefaultTemplate = "wip/{host}/{feature}/{ts}"
+
+// DefaultRollingTemplate names the single rolling WIP ref kept per host and branch
+const DefaultRollingTemplate = "wip/{host}/{branch}"
+
+// TimestampFormat is how {ts} is rendered
+const TimestampFormat = "20060102-150405"
+
+var (
+	tokenRegex  = regexp.MustCompile(`\{([a-z]+)\}`)
+	knownTokens = map[string]string{
+		"user":    `[A-Za-z0-9._-]+`,
+		"host":    `[A-Za-z0-9._-]+`,
+		"feature": `[A-Za-z0-9._-]+`,
+		"branch":  `[A-Za-z0-9._-]+`,
+		"ts":      `\d{8}-\d{6}`,
+	}
+)
+
+// Fields are the values encoded in a WIP branch name
+type Fields struct {
+	User    string
+	Host    string
+	Feature string
+	Branch  string
+	Time    time.Time
+	Rolling bool // parsed from a rolling ref, which has no timestamp
+}
+
+// Scheme is a compiled naming template
+type Scheme struct {
+	template string
+	prefix   string
+	pattern  *regexp.Regexp
+	rolling  bool
+}
+
+// Compile validates a template and builds its parser. Path segments made of a
+// single token other than {ts} are optional: they are dropped when empty.
+func Compile(template string) (*Scheme, error) {
+	if template == "" {
+		template = DefaultTemplate
+	}
+	if !strings.Contains(template, "{ts}") {
+		return nil, fmt.Errorf("WIP branch template %q must contain {ts}", template)
+	}
+	return compile(template, false)
+}
+
+// CompileRolling builds a scheme for rolling refs: one per host and branch, so
+// it must contain {branch} (which is never dropped) and must not contain {ts}.
+func CompileRolling(template string) (*Scheme, error) {
+	if template == "" {
+		template = DefaultRollingTemplate
+	}
+	if !strings.Contains(template, "{branch}") || strings.Contains(template, "{ts}") {
+		return nil, fmt.Errorf("rolling WIP template %q must contain {branch} and no {ts}", template)
+	}
+	return compile(template, true)
+}
+
+func compile(template string, rolling bool) (*Scheme, error) {
+	seen := make(map[string]bool)
+	for _, match := range tokenRegex.FindAllStringSubmatch(template, -1) {
+		if _, ok := knownTokens[match[1]]; !ok {
+			return nil, fmt.Errorf("WIP branch template %q: unknown token {%s}", template, match[1])
+		}
+		if seen[match[1]] {
+			return nil, fmt.Errorf("WIP branch template %q: token {%s} used twice", template, match[1])
+		}
+		seen[match[1]] = true
+	}
+
+	segments := strings.Split(template, "/")
+	last := len(segments) - 1
+
+	var sb strings.Builder
+	sb.WriteString("^")
+	for i, segment := range segments {
+		if token, ok := optionalToken(segment, rolling); ok {
+			group := fmt.Sprintf("(?P<%s>%s)", token, knownTokens[token])
+			if i < last {
+				sb.WriteString("(?:" + group + "/)?")
+			} else {
+				sb.WriteString("(?:/" + group + ")?")
+			}
+			continue
+		}
+
+		sb.WriteString(segmentPattern(segment))
+		if i < last {
+			if _, nextOptional := optionalToken(segments[i+1], rolling); !nextOptional || i+1 < last {
+				sb.WriteString("/")
+			}
+		}
+	}
+	sb.WriteString("$")
+
+	pattern, err := regexp.Compile(sb.String())
+	if err != nil {
+		return nil, fmt.Errorf("WIP branch template %q: %w", template, err)
+	}
+
+	prefix := template
+	if i := strings.Index(prefix, "{"); i >= 0 {
+		prefix = prefix[:i]
+	}
+	if i := strings.LastIndex(prefix, "/"); i >= 0 {
+		prefix = prefix[:i+1]
+	} else {
+		prefix = ""
+	}
+
+	return &Scheme{template: template, prefix: prefix, pattern: pattern, rolling: rolling}, nil
+}
+
+// Template returns the source template
+func (s *Scheme) Template() string {
+	return s.template
+}
+
+// Prefix returns the literal leading directory of the template (e.g. "wip/"),
+// used to narrow ref listings. It is empty when the template starts with a token.
+func (s *Scheme) Prefix() string {
+	return s.prefix
+}
+
+// Render builds a branch name from fields
+func (s *Scheme) Render(f Fields) string {
+	values := f.values()
+
+	var parts []string
+	for _, segment := range strings.Split(s.template, "/") {
+		if token, ok := optionalToken(segment, s.rolling); ok {
+			if values[token] != "" {
+				parts = append(parts, values[token])
+			}
+			continue
+		}
+		parts = append(parts, tokenRegex.ReplaceAllStringFunc(segment, func(match string) string {
+			return values[match[1:len(match)-1]]
+		}))
+	}
+	return strings.Join(parts, "/")
+}
+
+// Parse extracts fields from a branch name, reporting whether it matches the scheme
+func (s *Scheme) Parse(name string) (Fields, bool) {
+	match := s.pattern.FindStringSubmatch(name)
+	if match == nil {
+		return Fields{}, false
+	}
+
+	f := Fields{Rolling: s.rolling}
+	for i, group := range s.pattern.SubexpNames() {
+		switch group {
+		case "user":
+			f.User = match[i]
+		case "host":
+			f.Host = match[i]
+		case "feature":
+			f.Feature = match[i]
+		case "branch":
+			f.Branch = match[i]
+		case "ts":
+			t, err := time.ParseInLocation(TimestampFormat, match[i], time.Local)
+			if err != nil {
+				return Fields{}, false
+			}
+			f.Time = t
+		}
+	}
+	return f, true
+}
+
+// Naming renders new WIP names with the configured schemes and parses names
+// produced by them or by the default schemes, so older refs stay readable
+// after a template changes.
+type Naming struct {
+	schemes []*Scheme
+	rolling *Scheme
+}
+
+// New builds a Naming for the configured templates (empty means the default)
+func New(template, rollingTemplate string) (*Naming, error) {
+	primary, err := Compile(template)
+	if err != nil {
+		return nil, err
+	}
+	rolling, err := CompileRolling(rollingTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	naming := &Naming{schemes: []*Scheme{primary}, rolling: rolling}
+	if primary.Template() != DefaultTemplate {
+		fallback, _ := Compile(DefaultTemplate)
+		naming.schemes = append(naming.schemes, fallback)
+	}
+
+	// Timestamped schemes are tried first: their {ts} anchor is more specific
+	naming.schemes = append(naming.schemes, rolling)
+	if rolling.Template() != DefaultRollingTemplate {
+		fallback, _ := CompileRolling(DefaultRollingTemplate)
+		naming.schemes = append(naming.schemes, fallback)
+	}
+	return naming, nil
+}
+
+// Default returns a Naming using only the default templates
+func Default() *Naming {
+	naming, _ := New("", "")
+	return naming
+}
+
+// RenderRolling builds the rolling WIP branch name for a host and branch
+func (n *Naming) RenderRolling(f Fields) string {
+	return n.rolling.Render(f)
+}
+
+// Template returns the template new names are rendered with
+func (n *Naming) Template() string {
+	return n.schemes[0].Template()
+}
+
+// Render builds a new WIP branch name
+func (n *Naming) Render(f Fields) string {
+	return n.schemes[0].Render(f)
+}
+
+// Parse reports whether name is a WIP branch under any known scheme
+func (n *Naming) Parse(name string) (Fields, bool) {
+	for _, scheme := range n.schemes {
+		if f, ok := scheme.Parse(name); ok {
+			return f, true
+		}
+	}
+	return Fields{}, false
+}
+
+// Prefixes returns the distinct literal prefixes of all known schemes
+func (n *Naming) Prefixes() []string {
+	var prefixes []string
+	seen := make(map[string]bool)
+	for _, scheme := range n.schemes {
+		if !seen[scheme.Prefix()] {
+			seen[scheme.Prefix()] = true
+			prefixes = append(prefixes, scheme.Prefix())
+		}
+	}
+	return prefixes
+}
+
+func (f Fields) values() map[string]string {
+	ts := ""
+	if !f.Time.IsZero() {
+		ts = f.Time.Format(TimestampFormat)
+	}
+	return map[string]string{
+		"user":    sanitize(f.User),
+		"host":    sanitize(f.Host),
+		"feature": sanitize(f.Feature),
+		"branch":  sanitize(f.Branch),
+		"ts":      ts,
+	}
+}
+
+// optionalToken reports whether a path segment is exactly one droppable token.
+// {ts} is never optional, nor is {branch} in rolling schemes.
+func optionalToken(segment string, rolling bool) (string, bool) {
+	match := tokenRegex.FindStringSubmatch(segment)
+	if match == nil || match[0] != segment || match[1] == "ts" || (rolling && match[1] == "branch") {
+		return "", false
+	}
+	return match[1], true
+}
+
+func segmentPattern(segment string) string {
+	var sb strings.Builder
+	last := 0
+	for _, loc := range tokenRegex.FindAllStringSubmatchIndex(segment, -1) {
+		sb.WriteString(regexp.QuoteMeta(segment[last:loc[0]]))
+		token := segment[loc[2]:loc[3]]
+		sb.WriteString(fmt.Sprintf("(?P<%s>%s)", token, knownTokens[token]))
+		last = loc[1]
+	}
+	sb.WriteString(regexp.QuoteMeta(segment[last:]))
+	return sb.String()
+}
+
+// sanitize turns a value into a single ref path component. The encoding is
+// injective, so distinct branches never share a WIP name: "/" becomes "__",
+// and any other byte outside [A-Za-z0-9.-] becomes "_" plus two hex digits,
+// as do a leading or trailing "-" or "." and a "." following another.
+// Letters, digits, dashes and dots read as they are.
+func sanitize(value string) string {
+	var sb strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		edge := i == 0 || i == len(value)-1
+		switch {
+		case c == '/':
+			sb.WriteString("__")
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+			sb.WriteByte(c)
+		case c == '-' && !edge, c == '.' && !edge && value[i-1] != '.':
+			sb.WriteByte(c)
+		default:
+			fmt.Fprintf(&sb, "_%02x", c)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,211 @@
+package wipname
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCompileRejectsBadTemplates(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		rolling  bool
+	}{
+		{name: "missing ts", template: "wip/{host}/{feature}"},
+		{name: "unknown token", template: "wip/{host}/{nope}/{ts}"},
+		{name: "token twice", template: "wip/{host}/{host}/{ts}"},
+		{name: "rolling without branch", template: "wip/{host}", rolling: true},
+		{name: "rolling with ts", template: "wip/{host}/{branch}/{ts}", rolling: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var err error
+			if tt.rolling {
+				_, err = CompileRolling(tt.template)
+			} else {
+				_, err = Compile(tt.template)
+			}
+			if err == nil {
+				t.Errorf("compile %q: expected an error", tt.template)
+			}
+		})
+	}
+}
+
+func TestSchemeRenderAndParse(t *testing.T) {
+	ts := time.Date(2026, 3, 4, 15, 16, 17, 0, time.Local)
+
+	tests := []struct {
+		name     string
+		template string
+		fields   Fields
+		want     string
+	}{
+		{
+			name:     "default with feature",
+			template: DefaultTemplate,
+			fields:   Fields{Host: "laptop", Feature: "login", Time: ts},
+			want:     "wip/laptop/login/20260304-151617",
+		},
+		{
+			name:     "default drops empty feature",
+			template: DefaultTemplate,
+			fields:   Fields{Host: "laptop", Time: ts},
+			want:     "wip/laptop/20260304-151617",
+		},
+		{
+			name:     "unsafe characters sanitized",
+			template: DefaultTemplate,
+			fields:   Fields{Host: "my laptop!", Feature: "fix/login", Time: ts},
+			want:     "wip/my_20laptop_21/fix__login/20260304-151617",
+		},
+		{
+			name:     "user and branch",
+			template: "wip/{user}/{branch}/{ts}",
+			fields:   Fields{User: "ada", Branch: "main", Time: ts},
+			want:     "wip/ada/main/20260304-151617",
+		},
+		{
+			name:     "tokens sharing a segment",
+			template: "wip/{host}-{ts}",
+			fields:   Fields{Host: "laptop", Time: ts},
+			want:     "wip/laptop-20260304-151617",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme, err := Compile(tt.template)
+			if err != nil {
+				t.Fatalf("Compile(%q): %v", tt.template, err)
+			}
+
+			got := scheme.Render(tt.fields)
+			if got != tt.want {
+				t.Fatalf("Render = %q, want %q", got, tt.want)
+			}
+
+			parsed, ok := scheme.Parse(got)
+			if !ok {
+				t.Fatalf("Parse(%q) did not match", got)
+			}
+			if !parsed.Time.Equal(ts) {
+				t.Errorf("Parse time = %v, want %v", parsed.Time, ts)
+			}
+			if want := sanitize(tt.fields.Host); parsed.Host != want {
+				t.Errorf("Parse host = %q, want %q", parsed.Host, want)
+			}
+			if want := sanitize(tt.fields.Feature); parsed.Feature != want {
+				t.Errorf("Parse feature = %q, want %q", parsed.Feature, want)
+			}
+		})
+	}
+}
+
+func TestSanitizeKeepsBranchesApart(t *testing.T) {
+	groups := [][]string{
+		{"feat/a-b", "feat-a/b", "feat-a-b", "feat__a-b"},
+		{"a-/b", "a/-b", "a--b"},
+		{"a_b", "a b", "a/b"},
+		{"v1.2", "v1..2", ".v12", "v12."},
+	}
+
+	for _, group := range groups {
+		seen := make(map[string]string)
+		for _, value := range group {
+			got := sanitize(value)
+			if other, ok := seen[got]; ok {
+				t.Errorf("sanitize(%q) = sanitize(%q) = %q", value, other, got)
+			}
+			seen[got] = value
+		}
+	}
+}
+
+func TestSanitizeProducesRefComponents(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{value: "main", want: "main"},
+		{value: "feat/a-b", want: "feat__a-b"},
+		{value: "fix_login", want: "fix_5flogin"},
+		{value: "-x.", want: "_2dx_2e"},
+		{value: "v1..2", want: "v1._2e2"},
+	}
+
+	for _, tt := range tests {
+		if got := sanitize(tt.value); got != tt.want {
+			t.Errorf("sanitize(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestSchemePrefix(t *testing.T) {
+	tests := []struct {
+		template string
+		want     string
+	}{
+		{template: DefaultTemplate, want: "wip/"},
+		{template: "scratch/wip/{host}/{ts}", want: "scratch/wip/"},
+		{template: "{host}/{ts}", want: ""},
+	}
+
+	for _, tt := range tests {
+		scheme, err := Compile(tt.template)
+		if err != nil {
+			t.Fatalf("Compile(%q): %v", tt.template, err)
+		}
+		if got := scheme.Prefix(); got != tt.want {
+			t.Errorf("Prefix(%q) = %q, want %q", tt.template, got, tt.want)
+		}
+	}
+}
+
+func TestNamingParse(t *testing.T) {
+	naming, err := New("scratch/{host}/{ts}", "")
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		branch  string
+		ok      bool
+		host    string
+		rolling bool
+	}{
+		{name: "configured template", branch: "scratch/laptop/20260304-151617", ok: true, host: "laptop"},
+		{name: "default template still read", branch: "wip/desk/login/20260304-151617", ok: true, host: "desk"},
+		{name: "rolling ref", branch: "wip/laptop/main", ok: true, host: "laptop", rolling: true},
+		{name: "regular branch", branch: "feature/login", ok: false},
+		{name: "bad timestamp", branch: "scratch/laptop/2026-03-04", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fields, ok := naming.Parse(tt.branch)
+			if ok != tt.ok {
+				t.Fatalf("Parse(%q) ok = %v, want %v", tt.branch, ok, tt.ok)
+			}
+			if fields.Host != tt.host {
+				t.Errorf("host = %q, want %q", fields.Host, tt.host)
+			}
+			if fields.Rolling != tt.rolling {
+				t.Errorf("rolling = %v, want %v", fields.Rolling, tt.rolling)
+			}
+		})
+	}
+
+	if got, want := naming.Prefixes(), []string{"scratch/", "wip/"}; len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("Prefixes = %v, want %v", got, want)
+	}
+}
+
+func TestRenderRolling(t *testing.T) {
+	naming := Default()
+	if got, want := naming.RenderRolling(Fields{Host: "laptop", Branch: "feature/login"}), "wip/laptop/feature__login"; got != want {
+		t.Errorf("RenderRolling = %q, want %q", got, want)
+	}
+}